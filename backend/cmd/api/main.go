@@ -9,10 +9,13 @@ import (
 	"syscall"
 	"time"
 
+	"absolutcinema-backend/internal/database"
+	"absolutcinema-backend/internal/scheduler"
 	"absolutcinema-backend/internal/server"
+	"absolutcinema-backend/internal/services"
 )
 
-func gracefulShutdown(apiServer *http.Server, done chan bool) {
+func gracefulShutdown(apiServer *http.Server, jobs *scheduler.Scheduler, done chan bool) {
 	// Create context that listens for the interrupt signal from the OS.
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -31,20 +34,77 @@ func gracefulShutdown(apiServer *http.Server, done chan bool) {
 		log.Printf("Server forced to shutdown with error: %v", err)
 	}
 
+	// Stop background jobs within the same deadline
+	if err := jobs.Stop(ctx); err != nil {
+		log.Printf("Scheduler forced to stop with error: %v", err)
+	}
+
 	log.Println("Server exiting")
 
 	// Notify the main goroutine that the shutdown is complete
 	done <- true
 }
 
+// newScheduler registers the background jobs that run inside the API process
+func newScheduler() *scheduler.Scheduler {
+	db := database.New().DB()
+
+	// Payment provider is optional here too; without it invoices are simply not expired
+	paymentProvider, err := services.NewPaymentProvider()
+	if err != nil {
+		log.Printf("[Scheduler] Payment provider initialization failed: %v", err)
+		log.Println("[Scheduler] Stale invoices will not be expired at the provider")
+		paymentProvider = nil
+	}
+	bookingService := services.NewBookingService(db, paymentProvider)
+
+	jobs := scheduler.New(db)
+	jobs.Every(
+		scheduler.DurationFromEnv("BOOKING_REAPER_INTERVAL_SECONDS", time.Second, time.Minute),
+		scheduler.NewBookingReaper(
+			bookingService,
+			scheduler.DurationFromEnv("PENDING_BOOKING_TIMEOUT_MINUTES", time.Minute, 30*time.Minute),
+		),
+	)
+
+	jobs.Every(
+		scheduler.DurationFromEnv("RECONCILIATION_INTERVAL_MINUTES", time.Minute, 10*time.Minute),
+		scheduler.NewPaymentReconciler(
+			bookingService,
+			scheduler.DurationFromEnv("RECONCILIATION_LOOKBACK_HOURS", time.Hour, 24*time.Hour),
+		),
+	)
+
+	jobs.Every(
+		30*time.Second,
+		scheduler.NewHoldPurger(services.NewHoldService(db)),
+	)
+
+	jobs.Every(
+		scheduler.DurationFromEnv("EMAIL_DISPATCH_INTERVAL_SECONDS", time.Second, 15*time.Second),
+		scheduler.NewEmailDispatcher(services.NewNotificationService(db, services.NewSMTPMailer(), bookingService)),
+	)
+
+	jobs.Every(
+		scheduler.DurationFromEnv("REMINDER_INTERVAL_SECONDS", time.Second, time.Minute),
+		scheduler.NewReminderSender(services.NewReminderService(db, services.NewReminderChannels(db))),
+	)
+
+	return jobs
+}
+
 func main() {
 	server := server.NewServer()
 
+	// Start background jobs (uses the database connection opened by NewServer)
+	jobs := newScheduler()
+	jobs.Start()
+
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
 
 	// Run graceful shutdown in a separate goroutine
-	go gracefulShutdown(server, done)
+	go gracefulShutdown(server, jobs, done)
 
 	err := server.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
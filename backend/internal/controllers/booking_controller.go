@@ -1,7 +1,10 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -63,8 +66,8 @@ func (bc *BookingController) CreateBooking(c *gin.Context) {
 		return
 	}
 
-	// Validate minimum seat count
-	if len(req.SeatNumbers) == 0 {
+	// Validate minimum seat count (seats may come from a hold instead)
+	if len(req.SeatNumbers) == 0 && req.HoldID == nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "At least one seat must be selected",
 		})
@@ -86,7 +89,18 @@ func (bc *BookingController) CreateBooking(c *gin.Context) {
 
 		// Check for validation errors
 		if err.Error() == "showtime not found" ||
-			err.Error() == "cannot book seats for a showtime that has already started" {
+			err.Error() == "cannot book seats for a showtime that has already started" ||
+			err.Error() == "hold not found" ||
+			err.Error() == "hold does not belong to this showtime" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		// Check for ticket type eligibility, promo code and concession errors
+		if strings.HasPrefix(err.Error(), "ticket type") || strings.HasPrefix(err.Error(), "promo code") ||
+			strings.HasPrefix(err.Error(), "product") {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
 			})
@@ -220,14 +234,25 @@ func (bc *BookingController) GetBookingByID(c *gin.Context) {
 		return
 	}
 
+	// The cancellation policy that applies and what cancelling would refund right now
+	quote, err := bc.bookingService.GetCancellationQuote(booking)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve booking",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Booking retrieved successfully",
-		"data":    booking,
+		"message":      "Booking retrieved successfully",
+		"data":         booking,
+		"cancellation": quote,
 	})
 }
 
 // CancelBooking handles DELETE /api/bookings/:id
-// Cancels a pending booking
+// Cancels a pending booking, or refunds a paid one as its cancellation policy allows
 func (bc *BookingController) CancelBooking(c *gin.Context) {
 	// Get user ID from context
 	userIDValue, exists := c.Get("user_id")
@@ -276,12 +301,22 @@ func (bc *BookingController) CancelBooking(c *gin.Context) {
 			return
 		}
 		if err.Error() == "booking is already cancelled" ||
-			err.Error() == "cannot cancel a paid booking" {
+			err.Error() == "booking has already expired" ||
+			err.Error() == "cannot cancel a paid booking" ||
+			err.Error() == "booking is under payment review" ||
+			isRefundDenied(err) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
 			})
 			return
 		}
+		if isProviderRefundError(err) {
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error":   "Refund was rejected by the payment provider",
+				"details": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to cancel booking",
 			"details": err.Error(),
@@ -364,3 +399,432 @@ func (bc *BookingController) RetryPayment(c *gin.Context) {
 		"payment_url": result.PaymentURL,
 	})
 }
+
+// GetPaymentReviewBookings handles GET /api/admin/bookings/payment-review
+// Lists bookings whose payment failed verification, oldest first
+func (bc *BookingController) GetPaymentReviewBookings(c *gin.Context) {
+	bookings, err := bc.bookingService.GetPaymentReviewBookings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve bookings",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Bookings under payment review retrieved successfully",
+		"data":    bookings,
+	})
+}
+
+// ResolvePaymentReview handles POST /api/admin/bookings/:id/payment-review
+// Body: {"action": "approve"} confirms the booking, {"action": "reject"} cancels it
+func (bc *BookingController) ResolvePaymentReview(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid booking ID",
+		})
+		return
+	}
+
+	var req struct {
+		Action string `json:"action" binding:"required,oneof=approve reject"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := bc.bookingService.ResolvePaymentReview(bookingID, req.Action == "approve"); err != nil {
+		switch err.Error() {
+		case "booking not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case "booking is not under payment review",
+			"cannot approve a booking whose seats were released":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to resolve payment review",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment review resolved successfully",
+	})
+}
+
+// GetReconciliationReports handles GET /api/admin/payment-reconciliations
+// Optional query params: limit, offset
+func (bc *BookingController) GetReconciliationReports(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	reports, total, err := bc.bookingService.GetReconciliationReports(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve reconciliation reports",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reconciliation reports retrieved successfully",
+		"data":    reports,
+		"total":   total,
+	})
+}
+
+// RequestRefund handles POST /api/bookings/:id/refund
+// Refunds the customer's own paid booking within the refund policy and releases the seats
+func (bc *BookingController) RequestRefund(c *gin.Context) {
+	bc.refundBooking(c, false)
+}
+
+// RefundBooking handles POST /api/admin/bookings/:id/refund
+// Body (optional): {"reason": "CANCELLATION", "note": "...", "amount": 25000}; amount defaults to everything refundable
+func (bc *BookingController) RefundBooking(c *gin.Context) {
+	bc.refundBooking(c, true)
+}
+
+// refundBooking runs a refund for a customer or an admin
+func (bc *BookingController) refundBooking(c *gin.Context, isAdmin bool) {
+	userID := optionalUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid booking ID",
+		})
+		return
+	}
+
+	var req services.RefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	refund, err := bc.bookingService.RefundBooking(bookingID, *userID, isAdmin, &req)
+	if err != nil {
+		switch {
+		case err.Error() == "booking not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case isRefundDenied(err):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		case err.Error() == "invalid refund reason",
+			err.Error() == "refund amount exceeds the refundable amount":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case isProviderRefundError(err):
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error":   "Refund was rejected by the payment provider",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to refund booking",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	message := "Booking refunded successfully"
+	if refund == nil {
+		message = "Booking cancelled; the cancellation policy refunds nothing at this time"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    refund,
+	})
+}
+
+// GetBookingRefunds handles GET /api/admin/bookings/:id/refunds
+func (bc *BookingController) GetBookingRefunds(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid booking ID",
+		})
+		return
+	}
+
+	refunds, err := bc.bookingService.GetBookingRefunds(bookingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve refunds",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refunds retrieved successfully",
+		"data":    refunds,
+	})
+}
+
+// GetBookingPayments handles GET /api/admin/bookings/:id/payments
+func (bc *BookingController) GetBookingPayments(c *gin.Context) {
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid booking ID",
+		})
+		return
+	}
+
+	payments, err := bc.bookingService.GetBookingPayments(bookingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve payments",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payments retrieved successfully",
+		"data":    payments,
+	})
+}
+
+// GetTicketQR handles GET /api/bookings/:id/tickets/:ticketId/qr
+// Returns a paid ticket's signed token as a QR code (format=png|svg, size in pixels)
+func (bc *BookingController) GetTicketQR(c *gin.Context) {
+	userID := optionalUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid booking ID",
+		})
+		return
+	}
+	ticketID, err := strconv.ParseUint(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid ticket ID",
+		})
+		return
+	}
+	size := 0
+	if s := c.Query("size"); s != "" {
+		if size, err = strconv.Atoi(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid size",
+			})
+			return
+		}
+	}
+
+	token, err := bc.bookingService.GetTicketToken(bookingID, uint(ticketID), *userID)
+	if err != nil {
+		switch err.Error() {
+		case "booking not found", "ticket not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case "tickets are only issued for paid bookings":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to retrieve ticket",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	image, contentType, err := services.RenderTicketQR(token, c.Query("format"), size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Data(http.StatusOK, contentType, image)
+}
+
+// GetTicketPDF handles GET /api/bookings/:id/ticket.pdf
+// Returns the e-ticket of a paid booking, one page per seat with its QR code
+func (bc *BookingController) GetTicketPDF(c *gin.Context) {
+	bc.serveBookingDocument(c, services.DocumentTicket)
+}
+
+// GetReceiptPDF handles GET /api/bookings/:id/receipt.pdf
+// Returns the receipt of a paid or refunded booking
+func (bc *BookingController) GetReceiptPDF(c *gin.Context) {
+	bc.serveBookingDocument(c, services.DocumentReceipt)
+}
+
+// serveBookingDocument sends a booking PDF, answering 304 when the client's copy is current
+func (bc *BookingController) serveBookingDocument(c *gin.Context, kind string) {
+	userID := optionalUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid booking ID",
+		})
+		return
+	}
+
+	doc, err := bc.bookingService.GetBookingDocument(kind, bookingID, *userID)
+	if err != nil {
+		switch err.Error() {
+		case "booking not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case "tickets are only issued for paid bookings", "receipts are only issued for paid bookings":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to generate document",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.Header("ETag", doc.ETag)
+	c.Header("Last-Modified", doc.LastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, no-cache")
+	if c.GetHeader("If-None-Match") == doc.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, strings.ReplaceAll(doc.Filename, "/", "-")))
+	c.Data(http.StatusOK, "application/pdf", doc.Data)
+}
+
+// RedeemItemsRequest selects the concession items to hand out; empty means all of them
+type RedeemItemsRequest struct {
+	ItemIDs []uint `json:"item_ids"`
+}
+
+// RedeemBookingItems handles POST /api/admin/bookings/:id/items/redeem
+// Marks a paid booking's food and beverage items as picked up at the counter
+func (bc *BookingController) RedeemBookingItems(c *gin.Context) {
+	staffID := optionalUserID(c)
+	if staffID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid booking ID",
+		})
+		return
+	}
+
+	var req RedeemItemsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	items, err := bc.bookingService.RedeemBookingItems(bookingID, req.ItemIDs, *staffID)
+	if err != nil {
+		switch {
+		case err.Error() == "booking not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case err.Error() == "only paid bookings can be redeemed",
+			err.Error() == "no items to redeem",
+			strings.HasSuffix(err.Error(), "was already redeemed"):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to redeem items",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Items redeemed successfully",
+		"data":    items,
+	})
+}
+
+// isRefundDenied reports whether err is a refund or cancellation refused by the booking's state or policy
+func isRefundDenied(err error) bool {
+	switch err.Error() {
+	case "only paid bookings can be refunded",
+		"booking has already been refunded",
+		"the showtime has already started",
+		"this booking can no longer be cancelled",
+		"tickets have already been checked in":
+		return true
+	}
+	return false
+}
+
+// isProviderRefundError reports whether the payment provider refused a refund
+func isProviderRefundError(err error) bool {
+	return strings.HasPrefix(err.Error(), "refund was rejected by the payment provider")
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/services"
+)
+
+type CancellationPolicyController struct {
+	service *services.CancellationPolicyService
+}
+
+func NewCancellationPolicyController(service *services.CancellationPolicyService) *CancellationPolicyController {
+	return &CancellationPolicyController{service: service}
+}
+
+// CreatePolicy handles
+// POST /api/admin/cancellation-policies
+func (pc *CancellationPolicyController) CreatePolicy(c *gin.Context) {
+	var policy models.CancellationPolicy
+
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := pc.service.CreatePolicy(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Cancellation policy created successfully",
+		"data":    policy,
+	})
+}
+
+// GetAllPolicies handles
+// GET /api/admin/cancellation-policies
+func (pc *CancellationPolicyController) GetAllPolicies(c *gin.Context) {
+	policies, err := pc.service.GetAllPolicies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve cancellation policies",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cancellation policies retrieved successfully",
+		"data":    policies,
+	})
+}
+
+// GetPolicyByID handles
+// GET /api/admin/cancellation-policies/:id
+func (pc *CancellationPolicyController) GetPolicyByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid cancellation policy ID",
+		})
+		return
+	}
+
+	policy, err := pc.service.GetPolicyByID(uint(id))
+	if err != nil {
+		if err.Error() == "cancellation policy not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve cancellation policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cancellation policy retrieved successfully",
+		"data":    policy,
+	})
+}
+
+// UpdatePolicy handles
+// PUT /api/admin/cancellation-policies/:id
+func (pc *CancellationPolicyController) UpdatePolicy(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid cancellation policy ID",
+		})
+		return
+	}
+
+	var policy models.CancellationPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := pc.service.UpdatePolicy(uint(id), &policy); err != nil {
+		if err.Error() == "cancellation policy not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cancellation policy updated successfully",
+	})
+}
+
+// DeletePolicy handles
+// DELETE /api/admin/cancellation-policies/:id
+func (pc *CancellationPolicyController) DeletePolicy(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid cancellation policy ID",
+		})
+		return
+	}
+
+	if err := pc.service.DeletePolicy(uint(id)); err != nil {
+		if err.Error() == "cancellation policy not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete cancellation policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cancellation policy deleted successfully",
+	})
+}
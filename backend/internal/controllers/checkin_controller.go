@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// CheckinController handles ticket scans at the door
+type CheckinController struct {
+	service *services.CheckinService
+}
+
+// NewCheckinController creates a new check-in controller
+func NewCheckinController(service *services.CheckinService) *CheckinController {
+	return &CheckinController{service: service}
+}
+
+// CheckIn handles POST /api/staff/checkin
+// Verifies a scanned ticket and admits it at the given gate
+func (cc *CheckinController) CheckIn(c *gin.Context) {
+	staffID := optionalUserID(c)
+	if staffID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req services.CheckinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := cc.service.CheckIn(&req, *staffID)
+	if err != nil {
+		switch {
+		case err.Error() == "invalid ticket token",
+			strings.HasPrefix(err.Error(), "gate"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case err.Error() == "ticket not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case isCheckinRejected(err):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to check in ticket",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ticket checked in successfully",
+		"data":    result,
+	})
+}
+
+// GetOfflineBundle handles GET /api/staff/showtimes/:id/checkin-bundle
+// Exports the signed list of admissible tickets so a scanner can work offline
+func (cc *CheckinController) GetOfflineBundle(c *gin.Context) {
+	showtimeID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid showtime ID",
+		})
+		return
+	}
+
+	bundle, err := cc.service.GetOfflineBundle(uint(showtimeID))
+	if err != nil {
+		if err.Error() == "showtime not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to export check-in bundle",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Check-in bundle exported successfully",
+		"data":    bundle,
+	})
+}
+
+// SyncAdmissions handles POST /api/staff/checkin/sync
+// Uploads a scanner's offline admission log and resolves duplicate admissions
+func (cc *CheckinController) SyncAdmissions(c *gin.Context) {
+	staffID := optionalUserID(c)
+	if staffID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req services.AdmissionSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := cc.service.SyncAdmissions(&req, *staffID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "device_id") || strings.HasPrefix(err.Error(), "at most") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to sync admissions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Admissions synced successfully",
+		"data":    result,
+	})
+}
+
+// isCheckinRejected reports whether err is a valid ticket that can't be admitted now
+func isCheckinRejected(err error) bool {
+	switch err.Error() {
+	case "ticket is for a different showtime",
+		"ticket has already been checked in",
+		"check-in has not opened for this showtime",
+		"showtime has already ended":
+		return true
+	}
+	return strings.HasPrefix(err.Error(), "booking is not paid")
+}
@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/services"
+)
+
+type ConcessionController struct {
+	service *services.ConcessionService
+}
+
+// SetStockRequest sets a product's stock at one cinema
+type SetStockRequest struct {
+	Cinema   string `json:"cinema"`
+	Quantity *int   `json:"quantity" binding:"required"`
+}
+
+func NewConcessionController(service *services.ConcessionService) *ConcessionController {
+	return &ConcessionController{service: service}
+}
+
+// CreateProduct handles
+// POST /api/admin/concessions/products
+func (cc *ConcessionController) CreateProduct(c *gin.Context) {
+	// New products are on sale unless the body says otherwise
+	product := models.ConcessionProduct{Active: true}
+
+	if err := c.ShouldBindJSON(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := cc.service.CreateProduct(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Product created successfully",
+		"data":    product,
+	})
+}
+
+// GetAllProducts handles
+// GET /api/admin/concessions/products
+func (cc *ConcessionController) GetAllProducts(c *gin.Context) {
+	products, err := cc.service.GetAllProducts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve products",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Products retrieved successfully",
+		"data":    products,
+	})
+}
+
+// GetProductByID handles
+// GET /api/admin/concessions/products/:id
+func (cc *ConcessionController) GetProductByID(c *gin.Context) {
+	id, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	product, err := cc.service.GetProductByID(id)
+	if err != nil {
+		if err.Error() == "product not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve product",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	stock, err := cc.service.GetStock(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve stock",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product retrieved successfully",
+		"data": gin.H{
+			"product": product,
+			"stock":   stock,
+		},
+	})
+}
+
+// UpdateProduct handles
+// PUT /api/admin/concessions/products/:id
+func (cc *ConcessionController) UpdateProduct(c *gin.Context) {
+	id, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	var product models.ConcessionProduct
+	if err := c.ShouldBindJSON(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := cc.service.UpdateProduct(id, &product); err != nil {
+		if err.Error() == "product not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product updated successfully",
+	})
+}
+
+// DeleteProduct handles
+// DELETE /api/admin/concessions/products/:id
+func (cc *ConcessionController) DeleteProduct(c *gin.Context) {
+	id, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	if err := cc.service.DeleteProduct(id); err != nil {
+		switch err.Error() {
+		case "product not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case "product is part of a combo":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to delete product",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product deleted successfully",
+	})
+}
+
+// SetStock handles
+// PUT /api/admin/concessions/products/:id/stock
+func (cc *ConcessionController) SetStock(c *gin.Context) {
+	id, ok := parseProductID(c)
+	if !ok {
+		return
+	}
+
+	var req SetStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	stock, err := cc.service.SetStock(id, req.Cinema, *req.Quantity)
+	if err != nil {
+		if err.Error() == "product not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stock updated successfully",
+		"data":    stock,
+	})
+}
+
+// GetShowtimeConcessions handles
+// GET /api/showtimes/:id/concessions
+// Lists the food and beverage that can be ordered with a booking for the showtime
+func (cc *ConcessionController) GetShowtimeConcessions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid showtime ID",
+		})
+		return
+	}
+
+	products, err := cc.service.GetShowtimeConcessions(uint(id))
+	if err != nil {
+		if err.Error() == "showtime not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve concessions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Concessions retrieved successfully",
+		"data":    products,
+	})
+}
+
+// parseProductID reads the :id param, answering 400 when it is not a valid ID
+func parseProductID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid product ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
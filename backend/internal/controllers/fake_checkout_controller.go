@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// FakeCheckoutController serves the simulated checkout pages of the fake payment provider
+type FakeCheckoutController struct {
+	provider *services.FakePaymentProvider
+}
+
+// NewFakeCheckoutController creates a new fake checkout controller
+func NewFakeCheckoutController(provider *services.FakePaymentProvider) *FakeCheckoutController {
+	return &FakeCheckoutController{provider: provider}
+}
+
+var fakeCheckoutPage = template.Must(template.New("checkout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Fake checkout - {{.Invoice.InvoiceID}}</title>
+<style>
+body { font-family: sans-serif; max-width: 520px; margin: 40px auto; color: #222; }
+table { width: 100%; border-collapse: collapse; margin: 16px 0; }
+td { padding: 6px 0; border-bottom: 1px solid #eee; }
+td.amount { text-align: right; }
+.banner { background: #fff3cd; padding: 8px 12px; border-radius: 4px; }
+.error { background: #f8d7da; padding: 8px 12px; border-radius: 4px; }
+button { padding: 10px 18px; margin-right: 8px; cursor: pointer; }
+</style>
+</head>
+<body>
+<p class="banner">Simulated payment &mdash; no money is moved.</p>
+<h2>{{.Invoice.Description}}</h2>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<table>
+{{range .Invoice.Items}}<tr><td>{{.Name}}</td><td class="amount">{{.Price}}</td></tr>
+{{end}}<tr><td><strong>Total</strong></td><td class="amount"><strong>{{.Invoice.Amount}}</strong></td></tr>
+</table>
+<p>Status: <strong>{{.Invoice.Status}}</strong></p>
+{{if eq .Invoice.Status "PENDING"}}
+<form method="post" action="{{.Invoice.InvoiceID}}/pay" style="display:inline"><button type="submit">Pay</button></form>
+<form method="post" action="{{.Invoice.InvoiceID}}/expire" style="display:inline"><button type="submit">Let it expire</button></form>
+{{end}}
+</body>
+</html>
+`))
+
+// ShowCheckout handles GET /fake-checkout/:invoiceId
+func (fc *FakeCheckoutController) ShowCheckout(c *gin.Context) {
+	fc.render(c, http.StatusOK, "")
+}
+
+// Pay handles POST /fake-checkout/:invoiceId/pay
+// Marks the invoice PAID, sends the PAID callback and redirects like Xendit does
+func (fc *FakeCheckoutController) Pay(c *gin.Context) {
+	if err := fc.provider.Pay(c.Param("invoiceId")); err != nil {
+		fc.render(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	inv, _ := fc.provider.Checkout(c.Param("invoiceId"))
+	c.Redirect(http.StatusSeeOther, inv.SuccessRedirectURL)
+}
+
+// Expire handles POST /fake-checkout/:invoiceId/expire
+// Marks the invoice EXPIRED and sends the EXPIRED callback
+func (fc *FakeCheckoutController) Expire(c *gin.Context) {
+	if err := fc.provider.Expire(c.Param("invoiceId")); err != nil {
+		fc.render(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	inv, _ := fc.provider.Checkout(c.Param("invoiceId"))
+	c.Redirect(http.StatusSeeOther, inv.FailureRedirectURL)
+}
+
+// render writes the checkout page for the invoice in the URL
+func (fc *FakeCheckoutController) render(c *gin.Context, status int, errMsg string) {
+	inv, err := fc.provider.Checkout(c.Param("invoiceId"))
+	if err != nil {
+		c.String(http.StatusNotFound, "invoice not found")
+		return
+	}
+
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	_ = fakeCheckoutPage.Execute(c.Writer, gin.H{
+		"Invoice": inv,
+		"Error":   errMsg,
+	})
+}
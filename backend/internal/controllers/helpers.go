@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// optionalUserID returns the authenticated user's ID, or nil for anonymous requests.
+// Used on routes behind OptionalAuthMiddleware.
+func optionalUserID(c *gin.Context) *uuid.UUID {
+	userIDValue, exists := c.Get("user_id")
+	if !exists {
+		return nil
+	}
+
+	switch v := userIDValue.(type) {
+	case uuid.UUID:
+		return &v
+	case string:
+		if parsed, err := uuid.Parse(v); err == nil {
+			return &parsed
+		}
+	}
+	return nil
+}
+
+// parseDateRange reads the optional from and to query params as [from, to).
+// Both accept RFC 3339 or YYYY-MM-DD; a plain to date includes that whole day.
+func parseDateRange(c *gin.Context) (from, to *time.Time, err error) {
+	if s := c.Query("from"); s != "" {
+		t, err := parseDateParam(s)
+		if err != nil {
+			return nil, nil, errors.New("invalid from date")
+		}
+		from = &t
+	}
+	if s := c.Query("to"); s != "" {
+		t, err := parseDateParam(s)
+		if err != nil {
+			return nil, nil, errors.New("invalid to date")
+		}
+		if len(s) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errors.New("from must be before to")
+	}
+	return from, to, nil
+}
+
+// parseDateParam parses an RFC 3339 timestamp or a YYYY-MM-DD date (in local time)
+func parseDateParam(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+// isSeatValidationError reports whether err came from seat number validation
+func isSeatValidationError(err error) bool {
+	msg := err.Error()
+	return strings.HasPrefix(msg, "seat") || strings.HasPrefix(msg, "invalid")
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// HoldController handles temporary seat hold requests
+type HoldController struct {
+	holdService *services.HoldService
+}
+
+// NewHoldController creates a new hold controller
+func NewHoldController(holdService *services.HoldService) *HoldController {
+	return &HoldController{
+		holdService: holdService,
+	}
+}
+
+// CreateHold handles POST /api/showtimes/:id/holds
+// Locks seats for a limited time; works with or without authentication
+func (hc *HoldController) CreateHold(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid showtime ID",
+		})
+		return
+	}
+
+	var req services.CreateHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := hc.holdService.CreateHold(uint(id), optionalUserID(c), req.SeatNumbers)
+	if err != nil {
+		if services.IsConflictError(err) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Seat conflict",
+				"details": err.Error(),
+				"code":    "SEAT_ALREADY_TAKEN",
+			})
+			return
+		}
+		if err.Error() == "showtime not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if err.Error() == "cannot hold seats for a showtime that has already started" ||
+			isSeatValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to hold seats",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Seats held successfully",
+		"data":    result,
+	})
+}
+
+// GetHold handles GET /api/showtimes/:id/holds/:holdId
+// Returns an active hold so the client can resume a countdown
+func (hc *HoldController) GetHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("holdId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid hold ID",
+		})
+		return
+	}
+
+	result, err := hc.holdService.GetHold(holdID)
+	if err != nil {
+		if err.Error() == "hold not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve hold",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Hold retrieved successfully",
+		"data":    result,
+	})
+}
+
+// ReleaseHold handles DELETE /api/showtimes/:id/holds/:holdId
+// Releases held seats before the hold expires
+func (hc *HoldController) ReleaseHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("holdId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid hold ID",
+		})
+		return
+	}
+
+	if err := hc.holdService.ReleaseHold(holdID, optionalUserID(c)); err != nil {
+		if err.Error() == "hold not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to release hold",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Hold released successfully",
+	})
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// LedgerController serves the accounting ledger reports
+type LedgerController struct {
+	service *services.LedgerService
+}
+
+// NewLedgerController creates a new ledger controller
+func NewLedgerController(service *services.LedgerService) *LedgerController {
+	return &LedgerController{service: service}
+}
+
+// GetBalances handles GET /api/admin/ledger/balances
+// Optional query params: from, to (RFC 3339 or YYYY-MM-DD)
+func (lc *LedgerController) GetBalances(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	balances, err := lc.service.GetBalances(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve balances",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Balances retrieved successfully",
+		"data":    balances,
+	})
+}
+
+// GetTrialBalance handles GET /api/admin/ledger/trial-balance
+// Optional query params: from, to (RFC 3339 or YYYY-MM-DD)
+func (lc *LedgerController) GetTrialBalance(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	trialBalance, err := lc.service.GetTrialBalance(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve trial balance",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Trial balance retrieved successfully",
+		"data":    trialBalance,
+	})
+}
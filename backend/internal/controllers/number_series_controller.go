@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/services"
+)
+
+type NumberSeriesController struct {
+	service *services.NumberingService
+}
+
+func NewNumberSeriesController(service *services.NumberingService) *NumberSeriesController {
+	return &NumberSeriesController{service: service}
+}
+
+// CreateSeries handles
+// POST /api/admin/number-series
+func (nc *NumberSeriesController) CreateSeries(c *gin.Context) {
+	var series models.NumberSeries
+
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := nc.service.CreateSeries(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Number series created successfully",
+		"data":    series,
+	})
+}
+
+// GetAllSeries handles
+// GET /api/admin/number-series
+func (nc *NumberSeriesController) GetAllSeries(c *gin.Context) {
+	series, err := nc.service.GetAllSeries()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve number series",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Number series retrieved successfully",
+		"data":    series,
+	})
+}
+
+// UpdateSeries handles
+// PUT /api/admin/number-series/:id
+func (nc *NumberSeriesController) UpdateSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid number series ID",
+		})
+		return
+	}
+
+	var series models.NumberSeries
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := nc.service.UpdateSeries(uint(id), &series); err != nil {
+		if err.Error() == "number series not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Number series updated successfully",
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"absolutcinema-backend/internal/models"
 	"absolutcinema-backend/internal/services"
 )
 
@@ -15,6 +16,7 @@ type PublicController struct {
 	showtimeService *services.ShowtimeService
 	studioService   *services.StudioService
 	bookingService  *services.BookingService
+	categoryService *services.SeatCategoryService
 }
 
 // NewPublicController creates a new public controller
@@ -23,12 +25,14 @@ func NewPublicController(
 	showtimeService *services.ShowtimeService,
 	studioService *services.StudioService,
 	bookingService *services.BookingService,
+	categoryService *services.SeatCategoryService,
 ) *PublicController {
 	return &PublicController{
 		movieService:    movieService,
 		showtimeService: showtimeService,
 		studioService:   studioService,
 		bookingService:  bookingService,
+		categoryService: categoryService,
 	}
 }
 
@@ -108,7 +112,7 @@ func (pc *PublicController) GetMovieDetails(c *gin.Context) {
 }
 
 // GetStudioLayout handles GET /api/studios/:id
-// Returns studio seat layout/dimensions (public, no auth required)
+// Returns the studio's stored seat map (public, no auth required)
 func (pc *PublicController) GetStudioLayout(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -118,7 +122,7 @@ func (pc *PublicController) GetStudioLayout(c *gin.Context) {
 		return
 	}
 
-	studio, err := pc.studioService.GetStudioByID(uint(id))
+	studio, err := pc.studioService.GetStudioWithSeats(uint(id))
 	if err != nil {
 		if err.Error() == "studio not found" {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -133,9 +137,6 @@ func (pc *PublicController) GetStudioLayout(c *gin.Context) {
 		return
 	}
 
-	// Generate seat layout for frontend
-	seatLayout := generateSeatLayout(studio.TotalRows, studio.TotalCols)
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Studio layout retrieved successfully",
 		"data": gin.H{
@@ -143,7 +144,8 @@ func (pc *PublicController) GetStudioLayout(c *gin.Context) {
 			"name":       studio.Name,
 			"total_rows": studio.TotalRows,
 			"total_cols": studio.TotalCols,
-			"seats":      seatLayout,
+			"seats":      buildSeatGrid(studio),
+			"layout":     studio.Seats,
 		},
 	})
 }
@@ -185,6 +187,16 @@ func (pc *PublicController) GetOccupiedSeats(c *gin.Context) {
 		return
 	}
 
+	// Get the price of every seat (seat category pricing)
+	seatPrices, err := pc.categoryService.GetSeatPriceMap(showtime)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve seat prices",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Occupied seats retrieved successfully",
 		"data": gin.H{
@@ -195,20 +207,26 @@ func (pc *PublicController) GetOccupiedSeats(c *gin.Context) {
 			"price":          showtime.Price,
 			"occupied_seats": occupiedSeats,
 			"total_occupied": len(occupiedSeats),
+			"seat_prices":    seatPrices,
 		},
 	})
 }
 
-// generateSeatLayout creates a 2D representation of seats
-// Rows are labeled A-Z, columns are numbered 1-N
-func generateSeatLayout(rows, cols int) [][]string {
-	layout := make([][]string, rows)
-	for i := 0; i < rows; i++ {
-		rowLabel := string(rune('A' + i))
-		layout[i] = make([]string, cols)
-		for j := 0; j < cols; j++ {
-			layout[i][j] = rowLabel + strconv.Itoa(j+1)
+// buildSeatGrid places the studio's seats on a total_rows x total_cols grid.
+// Cells without a seat (aisles, gaps, removed seats) are empty strings.
+func buildSeatGrid(studio *models.Studio) [][]string {
+	grid := make([][]string, studio.TotalRows)
+	for i := range grid {
+		grid[i] = make([]string, studio.TotalCols)
+	}
+
+	for _, seat := range studio.Seats {
+		if seat.State == models.SeatStateRemoved {
+			continue
+		}
+		if seat.GridRow < studio.TotalRows && seat.GridCol < studio.TotalCols {
+			grid[seat.GridRow][seat.GridCol] = seat.Label
 		}
 	}
-	return layout
+	return grid
 }
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/services"
+)
+
+// ReminderController handles the user's showtime reminder preferences
+type ReminderController struct {
+	service *services.ReminderService
+}
+
+// NewReminderController creates a new reminder controller
+func NewReminderController(service *services.ReminderService) *ReminderController {
+	return &ReminderController{service: service}
+}
+
+// GetPreferences handles GET /api/reminders/preferences
+// Returns how the user is reminded of their showtimes and which channels exist
+func (rc *ReminderController) GetPreferences(c *gin.Context) {
+	userID := optionalUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	prefs, err := rc.service.GetPreferences(*userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve reminder preferences",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reminder preferences retrieved successfully",
+		"data": gin.H{
+			"preferences":        prefs,
+			"available_channels": rc.service.AvailableChannels(),
+		},
+	})
+}
+
+// UpdatePreferences handles PUT /api/reminders/preferences
+// Chooses the channels (email, sms, push) showtime reminders are sent through
+func (rc *ReminderController) UpdatePreferences(c *gin.Context) {
+	userID := optionalUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var prefs models.NotificationPreference
+	if err := c.ShouldBindJSON(&prefs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updated, err := rc.service.UpdatePreferences(*userID, &prefs)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "failed") {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update reminder preferences",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reminder preferences updated successfully",
+		"data": gin.H{
+			"preferences":        updated,
+			"available_channels": rc.service.AvailableChannels(),
+		},
+	})
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// ReportController serves the admin sales reports
+type ReportController struct {
+	service *services.ReportService
+}
+
+// NewReportController creates a new report controller
+func NewReportController(service *services.ReportService) *ReportController {
+	return &ReportController{service: service}
+}
+
+// GetSalesReport handles GET /api/admin/reports/sales
+// Query params: group_by (day, week, movie, studio, slot or showtime; default day),
+// from, to (RFC 3339 or YYYY-MM-DD, on the showtime start) and format=csv for a CSV download
+func (rc *ReportController) GetSalesReport(c *gin.Context) {
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	groupBy := c.DefaultQuery("group_by", services.ReportGroupDay)
+	rows, err := rc.service.GetSalesReport(groupBy, from, to)
+	if err != nil {
+		if err.Error() == "group_by must be day, week, movie, studio, slot or showtime" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to compute sales report",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		writeSalesReportCSV(c, groupBy, rows)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Sales report retrieved successfully",
+		"group_by": groupBy,
+		"data":     rows,
+	})
+}
+
+// writeSalesReportCSV sends the report as a CSV attachment; amounts are in major units
+func writeSalesReportCSV(c *gin.Context, groupBy string, rows []services.SalesReportRow) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="sales-report-by-%s.csv"`, groupBy))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{
+		groupBy, "label", "showtimes", "tickets_sold", "capacity", "occupancy_percent",
+		"revenue", "discounts", "net_revenue", "average_ticket_price",
+	})
+	for _, r := range rows {
+		_ = w.Write([]string{
+			r.Key,
+			r.Label,
+			strconv.FormatInt(r.Showtimes, 10),
+			strconv.FormatInt(r.TicketsSold, 10),
+			strconv.FormatInt(r.Capacity, 10),
+			strconv.FormatFloat(r.OccupancyPercent, 'f', 2, 64),
+			r.Revenue.Decimal(),
+			r.Discounts.Decimal(),
+			r.NetRevenue.Decimal(),
+			r.AverageTicketPrice.Decimal(),
+		})
+	}
+	w.Flush()
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/services"
+)
+
+type SeatCategoryController struct {
+	service *services.SeatCategoryService
+}
+
+func NewSeatCategoryController(service *services.SeatCategoryService) *SeatCategoryController {
+	return &SeatCategoryController{service: service}
+}
+
+// CreateCategory handles
+// POST /api/admin/seat-categories
+func (sc *SeatCategoryController) CreateCategory(c *gin.Context) {
+	var category models.SeatCategory
+
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := sc.service.CreateCategory(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Seat category created successfully",
+		"data":    category,
+	})
+}
+
+// GetAllCategories handles
+// GET /api/admin/seat-categories
+func (sc *SeatCategoryController) GetAllCategories(c *gin.Context) {
+	categories, err := sc.service.GetAllCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve seat categories",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Seat categories retrieved successfully",
+		"data":    categories,
+	})
+}
+
+// UpdateCategory handles
+// PUT /api/admin/seat-categories/:id
+func (sc *SeatCategoryController) UpdateCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid seat category ID",
+		})
+		return
+	}
+
+	var category models.SeatCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := sc.service.UpdateCategory(uint(id), &category); err != nil {
+		if err.Error() == "seat category not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Seat category updated successfully",
+	})
+}
+
+// DeleteCategory handles
+// DELETE /api/admin/seat-categories/:id
+func (sc *SeatCategoryController) DeleteCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid seat category ID",
+		})
+		return
+	}
+
+	if err := sc.service.DeleteCategory(uint(id)); err != nil {
+		if err.Error() == "seat category not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete seat category",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Seat category deleted successfully",
+	})
+}
+
+// GetShowtimePrices handles
+// GET /api/admin/showtimes/:id/prices
+func (sc *SeatCategoryController) GetShowtimePrices(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid showtime ID",
+		})
+		return
+	}
+
+	prices, err := sc.service.GetShowtimePrices(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve showtime prices",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Showtime prices retrieved successfully",
+		"data":    prices,
+	})
+}
+
+// SetShowtimePrices handles
+// PUT /api/admin/showtimes/:id/prices
+func (sc *SeatCategoryController) SetShowtimePrices(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid showtime ID",
+		})
+		return
+	}
+
+	var req services.SetShowtimePricesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := sc.service.SetShowtimePrices(uint(id), req.Prices); err != nil {
+		if err.Error() == "showtime not found" || err.Error() == "seat category not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Showtime prices updated successfully",
+	})
+}
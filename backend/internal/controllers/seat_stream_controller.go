@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// seatStreamHeartbeat keeps idle SSE connections alive through proxies
+const seatStreamHeartbeat = 15 * time.Second
+
+// SeatStreamController streams live seat-map changes to clients
+type SeatStreamController struct {
+	seatEventHub   *services.SeatEventHub
+	bookingService *services.BookingService
+}
+
+// NewSeatStreamController creates a new seat stream controller
+func NewSeatStreamController(seatEventHub *services.SeatEventHub, bookingService *services.BookingService) *SeatStreamController {
+	return &SeatStreamController{
+		seatEventHub:   seatEventHub,
+		bookingService: bookingService,
+	}
+}
+
+// StreamSeats handles GET /api/showtimes/:id/seats/stream
+// Server-Sent Events stream: one "snapshot" event with the currently occupied seats,
+// followed by "seat" events whenever seats are taken or released (public, no auth required)
+func (sc *SeatStreamController) StreamSeats(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid showtime ID",
+		})
+		return
+	}
+	showtimeID := uint(id)
+
+	// Subscribe before reading the snapshot so no change falls in between
+	events, unsubscribe := sc.seatEventHub.Subscribe(showtimeID)
+	defer unsubscribe()
+
+	occupiedSeats, err := sc.bookingService.GetOccupiedSeats(showtimeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve occupied seats",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// The server's WriteTimeout would otherwise cut the stream off
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("snapshot", gin.H{
+		"showtime_id":    showtimeID,
+		"occupied_seats": occupiedSeats,
+	})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(seatStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case event, ok := <-events:
+			if !ok {
+				// Hub stopped or we fell behind; the client's EventSource will reconnect
+				return
+			}
+			c.SSEvent("seat", event)
+			c.Writer.Flush()
+
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
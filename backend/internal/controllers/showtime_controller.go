@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
 	"absolutcinema-backend/internal/services"
 )
 
@@ -21,18 +22,18 @@ func NewShowtimeController(service *services.ShowtimeService) *ShowtimeControlle
 
 // CreateShowtimeRequest represents the request body for creating a showtime
 type CreateShowtimeRequest struct {
-	MovieID   uint      `json:"movie_id" binding:"required"`
-	StudioID  uint      `json:"studio_id" binding:"required"`
-	StartTime time.Time `json:"start_time" binding:"required"`
-	Price     float64   `json:"price" binding:"required,gt=0"`
+	MovieID   uint        `json:"movie_id" binding:"required"`
+	StudioID  uint        `json:"studio_id" binding:"required"`
+	StartTime time.Time   `json:"start_time" binding:"required"`
+	Price     money.Money `json:"price"` // Must be positive; checked by the service
 }
 
 // UpdateShowtimeRequest represents the request body for updating a showtime
 type UpdateShowtimeRequest struct {
-	MovieID   uint      `json:"movie_id" binding:"required"`
-	StudioID  uint      `json:"studio_id" binding:"required"`
-	StartTime time.Time `json:"start_time" binding:"required"`
-	Price     float64   `json:"price" binding:"required,gt=0"`
+	MovieID   uint        `json:"movie_id" binding:"required"`
+	StudioID  uint        `json:"studio_id" binding:"required"`
+	StartTime time.Time   `json:"start_time" binding:"required"`
+	Price     money.Money `json:"price"` // Must be positive; checked by the service
 }
 
 // CreateShowtime handles
@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -95,7 +96,7 @@ func (sc *StudioController) GetStudioByID(c *gin.Context) {
 }
 
 // UpdateStudio handles
-// PUT /api/admin/studios/:id
+// PUT /api/admin/studios/:id[?reset_layout=true]
 func (sc *StudioController) UpdateStudio(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -114,13 +115,22 @@ func (sc *StudioController) UpdateStudio(c *gin.Context) {
 		return
 	}
 	
-	if err := sc.service.UpdateStudio(uint(id), &studio); err != nil {
+	// ?reset_layout=true replaces a custom seat map with a full grid of the new size
+	resetLayout := c.Query("reset_layout") == "true"
+
+	if err := sc.service.UpdateStudio(uint(id), &studio, resetLayout); err != nil {
 		if err.Error() == "studio not found" {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": err.Error(),
 			})
 			return
 		}
+		if strings.HasPrefix(err.Error(), "studio has a custom seat layout") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 		})
@@ -161,3 +171,128 @@ func (sc *StudioController) DeleteStudio(c *gin.Context) {
 		"message": "Studio deleted successfully",
 	})
 }
+
+// GetLayout handles
+// GET /api/admin/studios/:id/layout
+func (sc *StudioController) GetLayout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid studio ID",
+		})
+		return
+	}
+
+	studio, err := sc.service.GetStudioWithSeats(uint(id))
+	if err != nil {
+		if err.Error() == "studio not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve studio layout",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Studio layout retrieved successfully",
+		"data": gin.H{
+			"studio_id":  studio.ID,
+			"total_rows": studio.TotalRows,
+			"total_cols": studio.TotalCols,
+			"seats":      studio.Seats,
+		},
+	})
+}
+
+// UpdateLayout handles
+// PUT /api/admin/studios/:id/layout
+func (sc *StudioController) UpdateLayout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid studio ID",
+		})
+		return
+	}
+
+	var req services.UpdateLayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := sc.service.ReplaceLayout(uint(id), req.Seats); err != nil {
+		if err.Error() == "studio not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if strings.HasSuffix(err.Error(), "has tickets for upcoming showtimes") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Studio layout updated successfully",
+	})
+}
+
+// UpdateSeatState handles
+// PATCH /api/admin/studios/:id/layout/seats/:label
+func (sc *StudioController) UpdateSeatState(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid studio ID",
+		})
+		return
+	}
+
+	var req services.UpdateSeatStateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := sc.service.UpdateSeatState(uint(id), c.Param("label"), req.State); err != nil {
+		if err.Error() == "seat not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if strings.HasSuffix(err.Error(), "has tickets for upcoming showtimes") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Seat state updated successfully",
+	})
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/services"
+)
+
+type TicketTypeController struct {
+	service *services.TicketTypeService
+}
+
+func NewTicketTypeController(service *services.TicketTypeService) *TicketTypeController {
+	return &TicketTypeController{service: service}
+}
+
+// CreateTicketType handles
+// POST /api/admin/ticket-types
+func (tc *TicketTypeController) CreateTicketType(c *gin.Context) {
+	var ticketType models.TicketType
+
+	if err := c.ShouldBindJSON(&ticketType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := tc.service.CreateTicketType(&ticketType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Ticket type created successfully",
+		"data":    ticketType,
+	})
+}
+
+// GetAllTicketTypes handles
+// GET /api/ticket-types (Public) and GET /api/admin/ticket-types
+func (tc *TicketTypeController) GetAllTicketTypes(c *gin.Context) {
+	ticketTypes, err := tc.service.GetAllTicketTypes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve ticket types",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ticket types retrieved successfully",
+		"data":    ticketTypes,
+	})
+}
+
+// UpdateTicketType handles
+// PUT /api/admin/ticket-types/:id
+func (tc *TicketTypeController) UpdateTicketType(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid ticket type ID",
+		})
+		return
+	}
+
+	var ticketType models.TicketType
+	if err := c.ShouldBindJSON(&ticketType); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := tc.service.UpdateTicketType(uint(id), &ticketType); err != nil {
+		if err.Error() == "ticket type not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ticket type updated successfully",
+	})
+}
+
+// DeleteTicketType handles
+// DELETE /api/admin/ticket-types/:id
+func (tc *TicketTypeController) DeleteTicketType(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid ticket type ID",
+		})
+		return
+	}
+
+	if err := tc.service.DeleteTicketType(uint(id)); err != nil {
+		if err.Error() == "ticket type not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete ticket type",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ticket type deleted successfully",
+	})
+}
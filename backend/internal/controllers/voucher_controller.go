@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/services"
+)
+
+type VoucherController struct {
+	service *services.VoucherService
+}
+
+func NewVoucherController(service *services.VoucherService) *VoucherController {
+	return &VoucherController{service: service}
+}
+
+// CreateVoucher handles
+// POST /api/admin/vouchers
+func (vc *VoucherController) CreateVoucher(c *gin.Context) {
+	// New vouchers are active unless the body says otherwise
+	voucher := models.Voucher{IsActive: true}
+
+	if err := c.ShouldBindJSON(&voucher); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := vc.service.CreateVoucher(&voucher); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Voucher created successfully",
+		"data":    voucher,
+	})
+}
+
+// GetAllVouchers handles
+// GET /api/admin/vouchers
+func (vc *VoucherController) GetAllVouchers(c *gin.Context) {
+	vouchers, err := vc.service.GetAllVouchers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve vouchers",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vouchers retrieved successfully",
+		"data":    vouchers,
+	})
+}
+
+// GetVoucherByID handles
+// GET /api/admin/vouchers/:id
+func (vc *VoucherController) GetVoucherByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid voucher ID",
+		})
+		return
+	}
+
+	voucher, err := vc.service.GetVoucherByID(uint(id))
+	if err != nil {
+		if err.Error() == "voucher not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve voucher",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Voucher retrieved successfully",
+		"data":    voucher,
+	})
+}
+
+// UpdateVoucher handles
+// PUT /api/admin/vouchers/:id
+func (vc *VoucherController) UpdateVoucher(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid voucher ID",
+		})
+		return
+	}
+
+	// Bind onto the stored voucher so fields left out of the body keep their values
+	voucher, err := vc.service.GetVoucherByID(uint(id))
+	if err != nil {
+		if err.Error() == "voucher not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve voucher",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err := c.ShouldBindJSON(voucher); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := vc.service.UpdateVoucher(uint(id), voucher); err != nil {
+		if err.Error() == "voucher not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Voucher updated successfully",
+	})
+}
+
+// DeleteVoucher handles
+// DELETE /api/admin/vouchers/:id
+func (vc *VoucherController) DeleteVoucher(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid voucher ID",
+		})
+		return
+	}
+
+	if err := vc.service.DeleteVoucher(uint(id)); err != nil {
+		if err.Error() == "voucher not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete voucher",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Voucher deleted successfully",
+	})
+}
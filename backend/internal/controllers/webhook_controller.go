@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -13,28 +14,25 @@ import (
 
 // WebhookController handles external webhook callbacks
 type WebhookController struct {
-	bookingService *services.BookingService
+	webhookEventService *services.WebhookEventService
+	bookingService      *services.BookingService
 }
 
 // NewWebhookController creates a new webhook controller
-func NewWebhookController(bookingService *services.BookingService) *WebhookController {
+func NewWebhookController(webhookEventService *services.WebhookEventService, bookingService *services.BookingService) *WebhookController {
 	return &WebhookController{
-		bookingService: bookingService,
+		webhookEventService: webhookEventService,
+		bookingService:      bookingService,
 	}
 }
 
 // HandleXenditCallback handles Xendit invoice webhook callbacks
 // @Router /api/webhooks/xendit [post]
 func (wc *WebhookController) HandleXenditCallback(c *gin.Context) {
-	// 1. Extract callback token from header
-	// Xendit sends this token in the x-callback-token header
-	callbackToken := c.GetHeader("x-callback-token")
-
-	// 2. Bind JSON payload
-	var payload models.XenditInvoiceCallback
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		log.Printf("[Webhook] Failed to parse payload: %v", err)
-		// Still return 200 to prevent Xendit from retrying malformed requests
+	// 1. Read the raw payload; it is stored as received, together with the headers
+	rawBody, err := c.GetRawData()
+	if err != nil {
+		log.Printf("[Webhook] Failed to read payload: %v", err)
 		c.JSON(http.StatusOK, gin.H{
 			"status":  "error",
 			"message": "invalid payload format",
@@ -42,12 +40,14 @@ func (wc *WebhookController) HandleXenditCallback(c *gin.Context) {
 		return
 	}
 
-	// Log incoming webhook for debugging (exclude sensitive data in production)
-	log.Printf("[Webhook] Received Xendit callback - ID: %s, ExternalID: %s, Status: %s",
-		payload.ID, payload.ExternalID, payload.Status)
+	// 2. Store, verify the x-callback-token header and process via the event log
+	receipt, err := wc.webhookEventService.ReceiveInvoiceCallback(rawBody, c.Request.Header)
+	if receipt != nil {
+		// Log incoming webhook for debugging (exclude sensitive data in production)
+		log.Printf("[Webhook] Received Xendit callback - Event: %d, ID: %s, ExternalID: %s, Status: %s",
+			receipt.Event.ID, receipt.Event.InvoiceID, receipt.Event.ExternalID, receipt.Event.Status)
+	}
 
-	// 3. Process the webhook via service
-	err := wc.bookingService.HandleInvoiceCallback(&payload, callbackToken)
 	if err != nil {
 		// Check error type for appropriate response
 		var webhookErr *services.WebhookError
@@ -56,15 +56,24 @@ func (wc *WebhookController) HandleXenditCallback(c *gin.Context) {
 			case services.ErrCodeUnauthorized:
 				// IMPORTANT: For security, we still return 200 to not reveal that the token is invalid
 				// But we log it for monitoring
-				log.Printf("[Webhook] SECURITY WARNING - Invalid callback token attempt for invoice %s", payload.ID)
+				log.Printf("[Webhook] SECURITY WARNING - Invalid callback token attempt (event %d)", receipt.Event.ID)
 				c.JSON(http.StatusOK, gin.H{
 					"status":  "error",
 					"message": "unauthorized",
 				})
 				return
 
+			case services.ErrCodeInvalidPayload:
+				log.Printf("[Webhook] Failed to parse payload (event %d)", receipt.Event.ID)
+				// Still return 200 to prevent Xendit from retrying malformed requests
+				c.JSON(http.StatusOK, gin.H{
+					"status":  "error",
+					"message": "invalid payload format",
+				})
+				return
+
 			case services.ErrCodeBookingNotFound:
-				log.Printf("[Webhook] Booking not found for external_id: %s", payload.ExternalID)
+				log.Printf("[Webhook] Booking not found for external_id: %s", receipt.Event.ExternalID)
 				// Return 200 to prevent retries for non-existent bookings
 				c.JSON(http.StatusOK, gin.H{
 					"status":  "error",
@@ -81,7 +90,7 @@ func (wc *WebhookController) HandleXenditCallback(c *gin.Context) {
 		}
 
 		// For unexpected errors, we still return 200 but log for investigation
-		// This prevents infinite retry loops from Xendit
+		// This prevents infinite retry loops from Xendit; the stored event can be replayed
 		c.JSON(http.StatusOK, gin.H{
 			"status":  "error",
 			"message": "processing error",
@@ -89,12 +98,168 @@ func (wc *WebhookController) HandleXenditCallback(c *gin.Context) {
 		return
 	}
 
-	// 4. Success response
+	// 3. Success response
+	if receipt.Duplicate {
+		log.Printf("[Webhook] Ignoring duplicate callback %s", *receipt.Event.DedupeKey)
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "webhook already processed",
+		})
+		return
+	}
+
 	log.Printf("[Webhook] Successfully processed callback for booking %s, status: %s",
-		payload.ExternalID, payload.Status)
+		receipt.Event.ExternalID, receipt.Event.Status)
 
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "success",
 		"message": "webhook processed",
 	})
 }
+
+// HandleXenditRefundCallback handles Xendit refund webhook callbacks
+// @Router /api/webhooks/xendit/refund [post]
+func (wc *WebhookController) HandleXenditRefundCallback(c *gin.Context) {
+	var payload models.XenditRefundCallback
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		log.Printf("[Webhook] Failed to parse refund payload: %v", err)
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "error",
+			"message": "invalid payload format",
+		})
+		return
+	}
+
+	log.Printf("[Webhook] Received Xendit refund callback - Event: %s, ID: %s, Status: %s",
+		payload.Event, payload.Data.ID, payload.Data.Status)
+
+	if err := wc.bookingService.HandleRefundCallback(&payload, c.GetHeader("x-callback-token")); err != nil {
+		message := "processing error"
+		var webhookErr *services.WebhookError
+		if errors.As(err, &webhookErr) {
+			switch webhookErr.Code {
+			case services.ErrCodeUnauthorized:
+				log.Printf("[Webhook] SECURITY WARNING - Invalid callback token on refund callback")
+				message = "unauthorized"
+			case services.ErrCodeInvalidPayload:
+				message = "invalid payload format"
+			case services.ErrCodeRefundNotFound:
+				log.Printf("[Webhook] Refund not found: %s", payload.Data.ID)
+				message = "refund not found"
+			default:
+				log.Printf("[Webhook] Error processing refund callback: %v", err)
+			}
+		} else {
+			log.Printf("[Webhook] Unexpected error: %v", err)
+		}
+
+		// Always 200 so Xendit doesn't retry; failures are logged for investigation
+		c.JSON(http.StatusOK, gin.H{
+			"status":  "error",
+			"message": message,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "success",
+		"message": "refund callback processed",
+	})
+}
+
+// ListWebhookEvents handles GET /api/admin/webhook-events
+// Optional query params: status (e.g. FAILED), limit, offset
+func (wc *WebhookController) ListWebhookEvents(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	events, total, err := wc.webhookEventService.ListEvents(c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve webhook events",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook events retrieved successfully",
+		"data":    events,
+		"total":   total,
+	})
+}
+
+// GetWebhookEvent handles GET /api/admin/webhook-events/:id
+func (wc *WebhookController) GetWebhookEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid webhook event ID",
+		})
+		return
+	}
+
+	event, err := wc.webhookEventService.GetEvent(uint(id))
+	if err != nil {
+		if err.Error() == "webhook event not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve webhook event",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook event retrieved successfully",
+		"data":    event,
+	})
+}
+
+// ReplayWebhookEvent handles POST /api/admin/webhook-events/:id/replay
+// Processes a failed event again; the outcome is recorded on the event
+func (wc *WebhookController) ReplayWebhookEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid webhook event ID",
+		})
+		return
+	}
+
+	event, err := wc.webhookEventService.ReplayEvent(uint(id))
+	if err != nil {
+		switch err.Error() {
+		case "webhook event not found":
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case "cannot replay an unverified webhook event",
+			"webhook event was already processed",
+			"cannot replay a webhook event with an invalid payload":
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to replay webhook event",
+				"details": err.Error(),
+			})
+		}
+		return
+	}
+
+	message := "Webhook event replayed successfully"
+	if event.ProcessingStatus != models.WebhookEventProcessed {
+		message = "Webhook event replayed but processing failed again"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    event,
+	})
+}
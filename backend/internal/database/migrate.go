@@ -2,30 +2,44 @@ package database
 
 import (
 	"absolutcinema-backend/internal/models"
+	"fmt"
 	"log"
+
+	"gorm.io/gorm"
 )
 
 // Migrate runs database migrations for all models
 func (s *service) Migrate() error {
 	log.Println("Running database migrations...")
 	
+	// Amounts used to be decimal(10,2) rupiah; they are now bigint minor units (whole rupiah).
+	// Convert before AutoMigrate so existing values are rounded explicitly, not cast.
+	if err := s.convertMoneyColumns(); err != nil {
+		log.Printf("Failed to convert money columns: %v", err)
+		return err
+	}
+
+	// Codes used to be unique across deleted rows too, so a deleted code could never be reused.
+	// Drop the old index; AutoMigrate creates the partial one that only covers live rows.
+	if err := s.gormDB.Exec(`DROP INDEX IF EXISTS idx_seat_categories_code, idx_ticket_types_code`).Error; err != nil {
+		log.Printf("Failed to drop old code index: %v", err)
+		return err
+	}
+
 	// AutoMigrate will create tables, missing columns, and missing indexes
 	// It will NOT change existing column types or delete unused columns
-	err := s.gormDB.AutoMigrate(
-		&models.User{},
-		&models.RefreshToken{},
-		&models.Movie{},
-		&models.Studio{},
-		&models.Showtime{},
-		&models.Booking{},
-		&models.Ticket{},
-	)
+	err := s.gormDB.AutoMigrate(Models()...)
 	
 	if err != nil {
 		log.Printf("Migration failed: %v", err)
 		return err
 	}
 	
+	if err := s.convertVoucherValues(); err != nil {
+		log.Printf("Failed to convert voucher values: %v", err)
+		return err
+	}
+
 	// Create unique composite index for tickets (showtime_id, seat_number)
 	// This enforces that a seat can only be booked once per showtime
 	err = s.gormDB.Exec(`
@@ -38,6 +52,230 @@ func (s *service) Migrate() error {
 		return err
 	}
 	
+	// Backfill seat maps for studios created before seat maps existed
+	// Each gets the full rows x cols rectangle it was implicitly using (rows A-Z, columns 1-N)
+	err = s.gormDB.Exec(`
+		INSERT INTO studio_seats (studio_id, label, row_label, number, grid_row, grid_col, state)
+		SELECT s.id, chr(65 + r) || (c + 1), chr(65 + r), c + 1, r, c, 'ACTIVE'
+		FROM studios s
+		CROSS JOIN LATERAL generate_series(0, s.total_rows - 1) AS r
+		CROSS JOIN LATERAL generate_series(0, s.total_cols - 1) AS c
+		WHERE NOT EXISTS (SELECT 1 FROM studio_seats ss WHERE ss.studio_id = s.id)
+	`).Error
+
+	if err != nil {
+		log.Printf("Failed to backfill studio seat maps: %v", err)
+		return err
+	}
+
+	// Seed the standard seat categories (admins can rename or add more).
+	// Deleted ones count as seeded, so a category an admin removed stays removed.
+	err = s.gormDB.Exec(`
+		INSERT INTO seat_categories (code, name)
+		SELECT v.code, v.name FROM (VALUES
+			('REGULAR', 'Regular'),
+			('PREMIUM', 'Premium'),
+			('VIP_RECLINER', 'VIP Recliner'),
+			('COUPLE_SOFA', 'Couple Sofa')
+		) AS v(code, name)
+		WHERE NOT EXISTS (SELECT 1 FROM seat_categories sc WHERE sc.code = v.code)
+		ON CONFLICT (code) WHERE deleted_at IS NULL DO NOTHING
+	`).Error
+
+	if err != nil {
+		log.Printf("Failed to seed seat categories: %v", err)
+		return err
+	}
+
+	// Seed the standard ticket types (admins can adjust modifiers and rules).
+	// Deleted ones count as seeded, so a type an admin removed stays removed.
+	err = s.gormDB.Exec(`
+		INSERT INTO ticket_types (code, name, price_modifier, excluded_ratings)
+		SELECT v.code, v.name, v.price_modifier, v.excluded_ratings::jsonb FROM (VALUES
+			('ADULT', 'Adult', 1.0, '[]'),
+			('CHILD', 'Child', 0.75, '["R", "NC-17", "17+", "21+"]'),
+			('STUDENT', 'Student', 0.85, '[]'),
+			('SENIOR', 'Senior', 0.8, '[]')
+		) AS v(code, name, price_modifier, excluded_ratings)
+		WHERE NOT EXISTS (SELECT 1 FROM ticket_types tt WHERE tt.code = v.code)
+		ON CONFLICT (code) WHERE deleted_at IS NULL DO NOTHING
+	`).Error
+
+	if err != nil {
+		log.Printf("Failed to seed ticket types: %v", err)
+		return err
+	}
+
+	// Backfill payment attempts for bookings created before payments were tracked separately.
+	// Only the latest invoice of each booking was kept, so that's the one recorded.
+	err = s.gormDB.Exec(`
+		INSERT INTO payments (booking_id, provider, provider_invoice_id, invoice_url, amount, currency, status, created_at, updated_at)
+		SELECT b.id,
+			CASE WHEN b.payment_id LIKE 'fake_%' THEN 'fake' ELSE 'xendit' END,
+			b.payment_id, b.payment_url, b.total_amount, 'IDR',
+			CASE
+				WHEN b.status IN ('PAID', 'REFUNDED', 'PARTIALLY_REFUNDED', 'PAYMENT_REVIEW') THEN 'PAID'
+				WHEN b.status = 'PENDING' THEN 'PENDING'
+				ELSE 'EXPIRED'
+			END,
+			b.created_at, b.created_at
+		FROM bookings b
+		WHERE b.payment_id IS NOT NULL AND b.payment_id <> ''
+		ON CONFLICT (provider_invoice_id) DO NOTHING
+	`).Error
+
+	if err != nil {
+		log.Printf("Failed to backfill payment attempts: %v", err)
+		return err
+	}
+
+	// Seed the default invoice and credit note series (admins can change the formats)
+	err = s.gormDB.Exec(`
+		INSERT INTO number_series (entity, document_type, format, reset_period) VALUES
+			('DEFAULT', 'INVOICE', 'INV/{YYYY}/{SEQ:6}', 'YEARLY'),
+			('DEFAULT', 'CREDIT_NOTE', 'CN/{YYYY}/{SEQ:6}', 'YEARLY')
+		ON CONFLICT (entity, document_type) DO NOTHING
+	`).Error
+
+	if err != nil {
+		log.Printf("Failed to seed number series: %v", err)
+		return err
+	}
+
+	// Record the stock taken by concession items booked before it was stored per item,
+	// from the combo recipes as they are now (the closest there is to what was taken)
+	err = s.gormDB.Exec(`
+		INSERT INTO booking_item_components (booking_item_id, product_id, quantity)
+		SELECT bi.id, COALESCE(ci.product_id, bi.product_id), COALESCE(ci.quantity, 1) * bi.quantity
+		FROM booking_items bi
+		LEFT JOIN concession_combo_items ci ON bi.category = 'COMBO' AND ci.combo_id = bi.product_id
+		WHERE bi.status = 'ACTIVE'
+			AND NOT EXISTS (SELECT 1 FROM booking_item_components c WHERE c.booking_item_id = bi.id)
+	`).Error
+
+	if err != nil {
+		log.Printf("Failed to backfill booking item components: %v", err)
+		return err
+	}
+
+	// Create unique composite index for holds (showtime_id, seat_number)
+	// Only one hold row may exist per seat; expired rows are cleared before re-holding
+	err = s.gormDB.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_holds_showtime_seat
+		ON holds(showtime_id, seat_number)
+	`).Error
+
+	if err != nil {
+		log.Printf("Failed to create unique index on holds: %v", err)
+		return err
+	}
+
 	log.Println("Database migrations completed successfully!")
 	return nil
 }
+
+// Models lists every model AutoMigrate manages, in creation order
+func Models() []interface{} {
+	return []interface{}{
+		&models.User{},
+		&models.RefreshToken{},
+		&models.Movie{},
+		&models.Studio{},
+		&models.SeatCategory{},
+		&models.StudioSeat{},
+		&models.Showtime{},
+		&models.Booking{},
+		&models.Ticket{},
+		&models.SeatHold{},
+		&models.ShowtimeSeatPrice{},
+		&models.TicketType{},
+		&models.Voucher{},
+		&models.VoucherRedemption{},
+		&models.WebhookEvent{},
+		&models.PaymentReconciliation{},
+		&models.Refund{},
+		&models.CancellationPolicy{},
+		&models.Payment{},
+		&models.NumberSeries{},
+		&models.NumberSequence{},
+		&models.LedgerTransaction{},
+		&models.LedgerEntry{},
+		&models.ConcessionProduct{},
+		&models.ConcessionComboItem{},
+		&models.ConcessionStock{},
+		&models.BookingItem{},
+		&models.BookingItemComponent{},
+		&models.TicketAdmission{},
+		&models.EmailOutbox{},
+		&models.ShowtimeReminder{},
+		&models.NotificationPreference{},
+	}
+}
+
+// moneyColumns lists the amount columns stored as decimal(10,2) before amounts became integer minor units
+var moneyColumns = []struct{ table, column string }{
+	{"showtimes", "price"},
+	{"bookings", "total_amount"},
+	{"bookings", "discount_amount"},
+	{"tickets", "price"},
+	{"showtime_seat_prices", "price"},
+	{"vouchers", "max_discount"},
+	{"voucher_redemptions", "discount_amount"},
+}
+
+// convertVoucherValues moves the old decimal vouchers.value column into amount (FIXED,
+// whole rupiah) and percent_basis_points (PERCENTAGE), then drops it. Runs after
+// AutoMigrate has added the new columns; does nothing once value is gone.
+func (s *service) convertVoucherValues() error {
+	var count int64
+	err := s.gormDB.Raw(`
+		SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'vouchers' AND column_name = 'value'
+	`).Scan(&count).Error
+	if err != nil || count == 0 {
+		return err
+	}
+
+	return s.gormDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			UPDATE vouchers SET
+				amount = CASE WHEN discount_type = 'FIXED' THEN ROUND(value)::bigint ELSE 0 END,
+				percent_basis_points = CASE WHEN discount_type = 'PERCENTAGE' THEN ROUND(value * 100)::integer ELSE 0 END
+		`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`ALTER TABLE vouchers DROP COLUMN value`).Error; err != nil {
+			return err
+		}
+		log.Println("Converted vouchers.value to amount and percent_basis_points")
+		return nil
+	})
+}
+
+// convertMoneyColumns changes any amount column that is still numeric to bigint,
+// rounding to whole rupiah. Columns already converted, or not created yet, are skipped.
+func (s *service) convertMoneyColumns() error {
+	for _, mc := range moneyColumns {
+		var count int64
+		err := s.gormDB.Raw(`
+			SELECT COUNT(*) FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ? AND column_name = ? AND data_type = 'numeric'
+		`, mc.table, mc.column).Scan(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+
+		err = s.gormDB.Exec(fmt.Sprintf(
+			`ALTER TABLE %[1]s ALTER COLUMN %[2]s TYPE bigint USING ROUND(%[2]s)::bigint`,
+			mc.table, mc.column,
+		)).Error
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", mc.table, mc.column, err)
+		}
+		log.Printf("Converted %s.%s to integer minor units", mc.table, mc.column)
+	}
+	return nil
+}
@@ -55,3 +55,8 @@ func RequireCustomer() gin.HandlerFunc {
 func RequireAdminOrCustomer() gin.HandlerFunc {
 	return RequireRole("admin", "customer")
 }
+
+// RequireStaff allows cinema staff (ushers at the door) and admins
+func RequireStaff() gin.HandlerFunc {
+	return RequireRole("admin", "staff")
+}
@@ -5,25 +5,48 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/money"
 )
 
 type Booking struct {
 	ID            uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
 	UserID        uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
 	
-	InvoiceNumber string    `gorm:"type:varchar(100);uniqueIndex" json:"invoice_number"`
-	TotalAmount   float64   `gorm:"type:decimal(10,2);not null" json:"total_amount"`
-	Status        string    `gorm:"type:varchar(50);default:'PENDING'" json:"status"`
+	InvoiceNumber string      `gorm:"type:varchar(100);uniqueIndex" json:"invoice_number"`
+	BillingEntity string      `gorm:"type:varchar(50)" json:"billing_entity,omitempty"` // Entity whose series numbered the invoice
+	TotalAmount   money.Money `gorm:"not null" json:"total_amount"`
+	Status        string      `gorm:"type:varchar(50);default:'PENDING'" json:"status"`
+
+	// Promo code applied to this booking; TotalAmount is already net of the discount
+	PromoCode      string      `gorm:"type:varchar(50)" json:"promo_code,omitempty"`
+	DiscountAmount money.Money `gorm:"default:0" json:"discount_amount"`
 	
-	// Payment gateway fields (Xendit)
+	// Payment gateway fields (Xendit) of the latest payment attempt; see Payments for all of them
 	PaymentURL string `gorm:"type:varchar(500);column:payment_url" json:"payment_url,omitempty"`
 	PaymentID  string `gorm:"type:varchar(100);column:payment_id" json:"payment_id,omitempty"`
+
+	// PaymentExpiresAt is when an unpaid booking expires and its seats are released.
+	// Every payment attempt's invoice expires at this time.
+	PaymentExpiresAt *time.Time `gorm:"index" json:"payment_expires_at,omitempty"`
+
+	// PaymentReviewReason explains why a payment callback put the booking in PAYMENT_REVIEW
+	PaymentReviewReason string `gorm:"type:text" json:"payment_review_reason,omitempty"`
+
+	// LastReconciledAt is when the reconciler last compared the booking with its invoice
+	LastReconciledAt *time.Time `gorm:"index" json:"-"`
+
+	// LastExpiryAttemptAt is when the reaper last tried to expire the booking
+	LastExpiryAttemptAt *time.Time `gorm:"index" json:"-"`
 	
 	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	
 	// Relationships (User hidden from JSON to reduce payload size)
-	User    User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
-	Tickets []Ticket `gorm:"foreignKey:BookingID" json:"tickets,omitempty"`
+	User     User          `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Tickets  []Ticket      `gorm:"foreignKey:BookingID" json:"tickets,omitempty"`
+	Items    []BookingItem `gorm:"foreignKey:BookingID" json:"items,omitempty"`
+	Refunds  []Refund      `gorm:"foreignKey:BookingID" json:"refunds,omitempty"`
+	Payments []Payment     `gorm:"foreignKey:BookingID" json:"payments,omitempty"`
 }
 
 // BeforeCreate hook to generate UUID if not set
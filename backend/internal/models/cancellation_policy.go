@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Cancellation policy scopes, from least to most specific
+const (
+	PolicyScopeGlobal   = "GLOBAL"
+	PolicyScopeStudio   = "STUDIO"
+	PolicyScopeMovie    = "MOVIE"
+	PolicyScopeShowtime = "SHOWTIME"
+)
+
+// CancellationPolicy decides whether a paid booking can be cancelled and how much is refunded.
+// The most specific policy attached to a booking's showtime applies: showtime, then movie,
+// then studio, then global.
+type CancellationPolicy struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string `gorm:"type:varchar(100);not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+
+	// What the policy is attached to; ScopeID is the studio, movie or showtime ID (empty for GLOBAL)
+	Scope   string `gorm:"type:varchar(20);not null;index:idx_cancellation_policy_scope" json:"scope"`
+	ScopeID *uint  `gorm:"index:idx_cancellation_policy_scope" json:"scope_id,omitempty"`
+
+	// Refund tiers; a booking that matches no tier can't be cancelled (e.g. no tiers = no refunds)
+	Rules []CancellationRule `gorm:"type:jsonb;serializer:json" json:"rules"`
+
+	CreatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// CancellationRule is one refund tier. It applies while the showtime starts at least
+// MinutesBefore minutes from now; among matching tiers the one with the largest MinutesBefore wins.
+// For example {120, 100} is "free cancellation until 2 hours before start".
+type CancellationRule struct {
+	MinutesBefore int   `json:"minutes_before"`
+	RefundPercent int64 `json:"refund_percent"` // 0-100
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/money"
+)
+
+// Concession product categories
+const (
+	ConcessionCategorySnack = "SNACK"
+	ConcessionCategoryDrink = "DRINK"
+	ConcessionCategoryCombo = "COMBO"
+)
+
+// Booking item statuses
+const (
+	BookingItemStatusActive   = "ACTIVE"   // Paid for (or awaiting payment), not picked up yet
+	BookingItemStatusRedeemed = "REDEEMED" // Handed out at the concession counter
+	BookingItemStatusReleased = "RELEASED" // Booking cancelled, expired or refunded; stock returned
+)
+
+// ConcessionProduct is a food or drink sold with a booking. A COMBO is made of other
+// products and has no stock of its own: selling one takes its components' stock.
+type ConcessionProduct struct {
+	ID          uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code        string      `gorm:"type:varchar(30);uniqueIndex;not null" json:"code"`
+	Name        string      `gorm:"type:varchar(100);not null" json:"name"`
+	Description string      `gorm:"type:text" json:"description,omitempty"`
+	Category    string      `gorm:"type:varchar(20);not null" json:"category"`
+	Price       money.Money `gorm:"not null" json:"price"`
+	Active      bool        `gorm:"not null" json:"active"` // No column default, so an explicit false is stored
+
+	ComboItems []ConcessionComboItem `gorm:"foreignKey:ComboID" json:"combo_items,omitempty"`
+
+	CreatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// ConcessionComboItem is one component of a combo
+type ConcessionComboItem struct {
+	ID        uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	ComboID   uint `gorm:"not null;index" json:"combo_id"`
+	ProductID uint `gorm:"not null" json:"product_id"`
+	Quantity  int  `gorm:"not null;default:1" json:"quantity"`
+
+	Product *ConcessionProduct `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+// ConcessionStock is how many of a product a cinema has left. The cinema is the
+// billing entity of the studio a showtime is in (DEFAULT when the studio has none).
+// A product without a stock row is not sold at that cinema.
+type ConcessionStock struct {
+	ProductID uint      `gorm:"primaryKey" json:"product_id"`
+	Cinema    string    `gorm:"type:varchar(50);primaryKey" json:"cinema"`
+	Quantity  int       `gorm:"not null;default:0" json:"quantity"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Product ConcessionProduct `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BookingItem is a concession line of a booking. Name and price are copied from
+// the product when booked, so later catalog changes don't alter the booking.
+type BookingItem struct {
+	ID          uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	BookingID   uuid.UUID   `gorm:"type:uuid;not null;index" json:"booking_id"`
+	ProductID   uint        `gorm:"not null" json:"product_id"`
+	ProductName string      `gorm:"type:varchar(100);not null" json:"product_name"`
+	Category    string      `gorm:"type:varchar(20)" json:"category"`
+	Cinema      string      `gorm:"type:varchar(50);not null" json:"cinema"`
+	Quantity    int         `gorm:"not null" json:"quantity"`
+	UnitPrice   money.Money `gorm:"not null" json:"unit_price"`
+	TotalPrice  money.Money `gorm:"not null" json:"total_price"`
+	Status      string      `gorm:"type:varchar(20);not null;default:'ACTIVE'" json:"status"`
+
+	RedeemedAt *time.Time `json:"redeemed_at,omitempty"`
+	RedeemedBy *uuid.UUID `gorm:"type:uuid" json:"redeemed_by,omitempty"`
+	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Components []BookingItemComponent `gorm:"foreignKey:BookingItemID;constraint:OnDelete:CASCADE" json:"-"`
+
+	Booking Booking `gorm:"foreignKey:BookingID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BookingItemComponent is the stock a booking item took when it was booked: the item's
+// own product, or each component of a combo. Releasing the item returns exactly this,
+// even if the combo's recipe has changed since.
+type BookingItemComponent struct {
+	ID            uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	BookingItemID uint `gorm:"not null;index" json:"booking_item_id"`
+	ProductID     uint `gorm:"not null" json:"product_id"`
+	Quantity      int  `gorm:"not null" json:"quantity"` // Units taken for the whole line, not per item
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Email kinds, also the names of their templates
+const (
+	EmailBookingCreated   = "BOOKING_CREATED"
+	EmailPaymentConfirmed = "PAYMENT_CONFIRMED"
+	EmailBookingExpired   = "BOOKING_EXPIRED"
+	EmailBookingCancelled = "BOOKING_CANCELLED"
+	EmailShowtimeChanged  = "SHOWTIME_CHANGED"
+	EmailShowtimeReminder = "SHOWTIME_REMINDER"
+)
+
+// Delivery status of an outbox email
+const (
+	EmailStatusPending = "PENDING"
+	EmailStatusSent    = "SENT"
+	EmailStatusFailed  = "FAILED" // Gave up after the maximum number of attempts
+)
+
+// EmailOutbox is an email waiting to be sent. It is rendered and written in the same
+// transaction as the booking change it announces, so a rolled back change sends nothing
+// and a committed one is delivered even if the SMTP server is down at the time.
+type EmailOutbox struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Kind          string     `gorm:"type:varchar(40);not null" json:"kind"`
+	BookingID     *uuid.UUID `gorm:"type:uuid;index" json:"booking_id,omitempty"`
+	Recipient     string     `gorm:"type:varchar(255);not null" json:"recipient"`
+	Subject       string     `gorm:"type:varchar(255);not null" json:"subject"`
+	TextBody      string     `gorm:"type:text;not null" json:"text_body"`
+	HTMLBody      string     `gorm:"type:text;not null" json:"html_body"`
+	Attachment    string     `gorm:"type:varchar(20)" json:"attachment,omitempty"` // Booking document rendered at delivery, e.g. "ticket"
+	Status        string     `gorm:"type:varchar(20);not null;default:'PENDING';index:idx_email_outbox_due" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_email_outbox_due" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName keeps the outbox under a singular name
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
@@ -92,6 +92,7 @@ EXAMPLE USAGE:
 CRITICAL LOGIC:
 - The Ticket model has a BeforeCreate hook that prevents duplicate seat bookings
 - A unique composite index on (showtime_id, seat_number) enforces seat uniqueness at DB level
+- Seats under an unexpired SeatHold (holds table) are treated as taken by the Ticket hook
 - All relationships use UUID for users/bookings and auto-increment integers for others
 - Soft deletes are enabled on users, movies, studios, and showtimes
 */
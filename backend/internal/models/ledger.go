@@ -0,0 +1,84 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/money"
+)
+
+// Ledger accounts. Revenue is kept per studio as AccountRevenuePrefix + studio ID.
+const (
+	AccountReceivables       = "receivables"         // Asset: owed by customers for unpaid bookings
+	AccountGatewayCash       = "gateway_cash"        // Asset: collected by the payment gateway
+	AccountRevenuePrefix     = "revenue:studio:"     // Revenue: ticket sales at list price
+	AccountConcessionRevenue = "revenue:concessions" // Revenue: food and beverage sold with bookings
+	AccountDiscounts         = "discounts"           // Contra-revenue: promo code discounts
+	AccountGatewayFees       = "gateway_fees"        // Expense: fees charged by the payment gateway
+	AccountRefundsPayable    = "refunds_payable"     // Liability: refunds granted but not yet paid out
+)
+
+// Ledger events; together with Reference each one is posted at most once
+const (
+	LedgerEventBookingCreated   = "BOOKING_CREATED"
+	LedgerEventBookingExpired   = "BOOKING_EXPIRED"
+	LedgerEventBookingCancelled = "BOOKING_CANCELLED"
+	LedgerEventPaymentReceived  = "PAYMENT_RECEIVED"
+	LedgerEventRefundIssued     = "REFUND_ISSUED"
+	LedgerEventRefundPaid       = "REFUND_PAID"
+)
+
+// errLedgerAppendOnly is returned when something tries to change a posted ledger row
+var errLedgerAppendOnly = errors.New("ledger is append-only")
+
+// LedgerTransaction is one balanced journal entry. The ledger is append-only:
+// mistakes are corrected by posting another transaction, never by editing.
+type LedgerTransaction struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	BookingID *uuid.UUID `gorm:"type:uuid;index" json:"booking_id,omitempty"`
+
+	// Event and Reference (booking, invoice or refund ID) identify what was posted
+	Event       string `gorm:"type:varchar(30);not null;uniqueIndex:idx_ledger_event_reference" json:"event"`
+	Reference   string `gorm:"type:varchar(100);not null;uniqueIndex:idx_ledger_event_reference" json:"reference"`
+	Description string `gorm:"type:text" json:"description,omitempty"`
+
+	OccurredAt time.Time `gorm:"not null;index" json:"occurred_at"`
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Entries []LedgerEntry `gorm:"foreignKey:TransactionID" json:"entries,omitempty"`
+}
+
+// LedgerEntry is one debit or credit line of a ledger transaction
+type LedgerEntry struct {
+	ID            uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID uint        `gorm:"not null;index" json:"transaction_id"`
+	Account       string      `gorm:"type:varchar(100);not null;index:idx_ledger_entry_account_time" json:"account"`
+	Debit         money.Money `gorm:"not null;default:0" json:"debit"`
+	Credit        money.Money `gorm:"not null;default:0" json:"credit"`
+
+	// Copied from the transaction so balances over a date range need no join
+	OccurredAt time.Time `gorm:"not null;index:idx_ledger_entry_account_time" json:"occurred_at"`
+}
+
+// BeforeUpdate keeps posted transactions unchanged
+func (t *LedgerTransaction) BeforeUpdate(tx *gorm.DB) error {
+	return errLedgerAppendOnly
+}
+
+// BeforeDelete keeps posted transactions in place
+func (t *LedgerTransaction) BeforeDelete(tx *gorm.DB) error {
+	return errLedgerAppendOnly
+}
+
+// BeforeUpdate keeps posted entries unchanged
+func (e *LedgerEntry) BeforeUpdate(tx *gorm.DB) error {
+	return errLedgerAppendOnly
+}
+
+// BeforeDelete keeps posted entries in place
+func (e *LedgerEntry) BeforeDelete(tx *gorm.DB) error {
+	return errLedgerAppendOnly
+}
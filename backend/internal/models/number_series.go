@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// Document types that get their own number series
+const (
+	DocumentTypeInvoice    = "INVOICE"
+	DocumentTypeCreditNote = "CREDIT_NOTE"
+)
+
+// How often a number series starts again from 1
+const (
+	NumberResetNever   = "NEVER"
+	NumberResetYearly  = "YEARLY"
+	NumberResetMonthly = "MONTHLY"
+	NumberResetDaily   = "DAILY"
+)
+
+// DefaultBillingEntity is the entity used by studios that don't name one
+const DefaultBillingEntity = "DEFAULT"
+
+// NumberSeries configures how one billing entity numbers one type of document.
+// Format is a template such as "INV/{ENTITY}/{YYYY}/{SEQ:6}" with the tokens
+// {ENTITY}, {YYYY}, {YY}, {MM}, {DD} and {SEQ} or {SEQ:n} (zero-padded to n digits).
+type NumberSeries struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Entity       string `gorm:"type:varchar(50);not null;uniqueIndex:idx_number_series_entity_type" json:"entity"`
+	DocumentType string `gorm:"type:varchar(20);not null;uniqueIndex:idx_number_series_entity_type" json:"document_type"`
+	Format       string `gorm:"type:varchar(100);not null" json:"format"`
+	ResetPeriod  string `gorm:"type:varchar(10);not null;default:'YEARLY'" json:"reset_period"`
+
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NumberSequence is the last number issued by a series in one period
+// (e.g. "2026" for a yearly series, "" for one that never resets).
+// Numbers are taken by incrementing this row inside the transaction that
+// uses them, so a rolled back transaction gives its number back.
+type NumberSequence struct {
+	SeriesID  uint      `gorm:"primaryKey" json:"series_id"`
+	Period    string    `gorm:"type:varchar(10);primaryKey" json:"period"`
+	LastValue int64     `gorm:"not null" json:"last_value"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Series NumberSeries `gorm:"foreignKey:SeriesID;constraint:OnDelete:RESTRICT" json:"-"`
+}
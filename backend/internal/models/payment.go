@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"absolutcinema-backend/internal/money"
+)
+
+// Payment attempt statuses
+const (
+	PaymentStatusPending = "PENDING"
+	PaymentStatusPaid    = "PAID"
+	PaymentStatusExpired = "EXPIRED"
+)
+
+// Payment is one attempt to pay for a booking: an invoice at the payment provider.
+// A booking gets a new attempt each time payment is retried; Booking.PaymentID and
+// Booking.PaymentURL point at the latest one.
+type Payment struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	BookingID uuid.UUID `gorm:"type:uuid;not null;index" json:"booking_id"`
+
+	Provider          string `gorm:"type:varchar(30);not null" json:"provider"`
+	ProviderInvoiceID string `gorm:"type:varchar(100);not null;uniqueIndex" json:"provider_invoice_id"`
+	InvoiceURL        string `gorm:"type:varchar(500)" json:"invoice_url"`
+
+	Amount    money.Money `gorm:"not null" json:"amount"`
+	Currency  string      `gorm:"type:varchar(3);not null" json:"currency"`
+	Status    string      `gorm:"type:varchar(20);not null;default:'PENDING';index" json:"status"`
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+
+	// Filled in from the PAID callback
+	PaidAmount             money.Money `gorm:"default:0" json:"paid_amount"`
+	FeesPaidAmount         money.Money `gorm:"default:0" json:"fees_paid_amount"`
+	AdjustedReceivedAmount money.Money `gorm:"default:0" json:"adjusted_received_amount"`
+	PaymentMethod          string      `gorm:"type:varchar(50)" json:"payment_method,omitempty"`
+	PaymentChannel         string      `gorm:"type:varchar(50)" json:"payment_channel,omitempty"`
+	PaidAt                 *time.Time  `json:"paid_at,omitempty"`
+	ExpiredAt              *time.Time  `json:"expired_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Booking Booking `gorm:"foreignKey:BookingID;constraint:OnDelete:CASCADE" json:"-"`
+}
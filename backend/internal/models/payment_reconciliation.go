@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentReconciliation records one correction made because a booking's status
+// had drifted from its invoice at the payment gateway (e.g. a lost webhook)
+type PaymentReconciliation struct {
+	ID     uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RunID  uuid.UUID `gorm:"type:uuid;not null;index" json:"run_id"` // Corrections made in the same pass share a run ID
+	Source string    `gorm:"type:varchar(30);not null" json:"source"`
+
+	BookingID      uuid.UUID `gorm:"type:uuid;not null;index" json:"booking_id"`
+	InvoiceID      string    `gorm:"type:varchar(100)" json:"invoice_id"`
+	ProviderStatus string    `gorm:"type:varchar(30);not null" json:"provider_status"`
+	PreviousStatus string    `gorm:"type:varchar(50);not null" json:"previous_status"`
+	NewStatus      string    `gorm:"type:varchar(50);not null" json:"new_status"`
+	Note           string    `gorm:"type:text" json:"note,omitempty"`
+
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP;index" json:"created_at"`
+}
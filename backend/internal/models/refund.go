@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"absolutcinema-backend/internal/money"
+)
+
+// Refund is one refund of a booking's payment. A booking can have several
+// (e.g. a failed attempt followed by a successful one).
+type Refund struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	BookingID uuid.UUID `gorm:"type:uuid;not null;index" json:"booking_id"`
+
+	// Provider side of the refund; ProviderRefundID is empty until the provider accepted it
+	Provider         string `gorm:"type:varchar(30);not null" json:"provider"`
+	ProviderRefundID string `gorm:"type:varchar(100);index" json:"provider_refund_id,omitempty"`
+	InvoiceID        string `gorm:"type:varchar(100)" json:"invoice_id"`
+
+	// CreditNoteNumber comes from the booking's credit note series once the provider accepted the refund
+	CreditNoteNumber *string `gorm:"type:varchar(100);uniqueIndex" json:"credit_note_number,omitempty"`
+
+	Amount money.Money `gorm:"not null" json:"amount"`
+	Reason string      `gorm:"type:varchar(50);not null" json:"reason"`
+	Note   string      `gorm:"type:text" json:"note,omitempty"`
+	Status string      `gorm:"type:varchar(20);not null;index" json:"status"`
+	Error  string      `gorm:"type:text" json:"error,omitempty"`
+
+	// Who asked for the refund; RequestedByAdmin refunds bypass the customer policy
+	RequestedBy      uuid.UUID `gorm:"type:uuid;not null" json:"requested_by"`
+	RequestedByAdmin bool      `gorm:"default:false" json:"requested_by_admin"`
+
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	SettledAt *time.Time `json:"settled_at,omitempty"` // When the provider confirmed or rejected it
+
+	Booking Booking `gorm:"foreignKey:BookingID;constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reminder status
+const (
+	ReminderStatusPending   = "PENDING"
+	ReminderStatusSent      = "SENT"
+	ReminderStatusCancelled = "CANCELLED" // Booking cancelled, or replaced after the showtime moved
+	ReminderStatusSkipped   = "SKIPPED"   // Overtaken by a later reminder or the showtime itself
+	ReminderStatusFailed    = "FAILED"
+)
+
+// Reminder channels
+const (
+	ReminderChannelEmail = "email"
+	ReminderChannelSMS   = "sms"
+	ReminderChannelPush  = "push"
+)
+
+// ShowtimeReminder is a reminder planned for a paid booking, LeadMinutes before its showtime.
+// A booking has at most one pending reminder per lead time; when the showtime moves the
+// pending ones are cancelled and planned again from the new start time.
+type ShowtimeReminder struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	BookingID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_reminder_pending,where:status = 'PENDING';index" json:"booking_id"`
+	ShowtimeID  uint       `gorm:"not null;index" json:"showtime_id"`
+	LeadMinutes int        `gorm:"not null;uniqueIndex:idx_reminder_pending,where:status = 'PENDING'" json:"lead_minutes"`
+	DueAt       time.Time  `gorm:"not null;index:idx_reminder_due" json:"due_at"`
+	Status      string     `gorm:"type:varchar(20);not null;default:'PENDING';index:idx_reminder_due" json:"status"`
+	Channels    string     `gorm:"type:varchar(50)" json:"channels,omitempty"` // Channels it was sent through, comma separated
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// NotificationPreference is how a user wants to be reminded of their showtimes.
+// Users without a row get email reminders only.
+type NotificationPreference struct {
+	UserID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Email        bool      `gorm:"not null" json:"email"`
+	SMS          bool      `gorm:"not null" json:"sms"`
+	Push         bool      `gorm:"not null" json:"push"`
+	Phone        string    `gorm:"type:varchar(30)" json:"phone,omitempty"`
+	PushEndpoint string    `gorm:"type:text" json:"push_endpoint,omitempty"` // Web push subscription endpoint
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/money"
+)
+
+// SeatCategory groups seats that are sold at the same price tier
+// (regular, premium, VIP recliner, couple sofa, ...)
+type SeatCategory struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code        string `gorm:"type:varchar(30);uniqueIndex:idx_seat_categories_code_active,where:deleted_at IS NULL;not null" json:"code"`
+	Name        string `gorm:"type:varchar(100);not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// ShowtimeSeatPrice is the price of one seat category for one showtime.
+// Seats without a category, or whose category has no entry here, cost Showtime.Price.
+type ShowtimeSeatPrice struct {
+	ID             uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	ShowtimeID     uint        `gorm:"not null;uniqueIndex:idx_showtime_seat_price" json:"showtime_id"`
+	SeatCategoryID uint        `gorm:"not null;uniqueIndex:idx_showtime_seat_price" json:"seat_category_id"`
+	Price          money.Money `gorm:"not null" json:"price"`
+
+	Showtime     Showtime     `gorm:"foreignKey:ShowtimeID;constraint:OnDelete:CASCADE" json:"-"`
+	SeatCategory SeatCategory `gorm:"foreignKey:SeatCategoryID;constraint:OnDelete:CASCADE" json:"seat_category"`
+}
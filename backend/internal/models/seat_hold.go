@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SeatHold is a temporary lock on a single seat for a showtime.
+// Seats locked together share the same HoldID. A hold only blocks the seat
+// while ExpiresAt is in the future; expired rows are ignored by every
+// availability check and purged lazily.
+type SeatHold struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	HoldID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"hold_id"`
+	UserID     *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"` // Nil for holds placed before login
+	ShowtimeID uint       `gorm:"not null;index" json:"showtime_id"`
+	SeatNumber string     `gorm:"type:varchar(10);not null" json:"seat_number"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Showtime Showtime `gorm:"foreignKey:ShowtimeID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for SeatHold
+func (SeatHold) TableName() string {
+	return "holds"
+}
+
+// IsExpired checks if the hold has expired
+func (h *SeatHold) IsExpired() bool {
+	return time.Now().After(h.ExpiresAt)
+}
@@ -7,10 +7,28 @@ import (
 type Studio struct {
 	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
 	Name      string         `gorm:"type:varchar(100);not null" json:"name"`
-	TotalRows int            `gorm:"not null" json:"total_rows"`       
-	TotalCols int            `gorm:"not null" json:"total_cols"`
+	TotalRows int            `gorm:"not null" json:"total_rows"` // Grid height of the seat map
+	TotalCols int            `gorm:"not null" json:"total_cols"` // Grid width of the seat map
+
+	// BillingEntity picks the number series for this studio's invoices (empty = DEFAULT)
+	BillingEntity string `gorm:"type:varchar(50)" json:"billing_entity,omitempty"`
 	
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 	
-	Showtimes []Showtime `gorm:"foreignKey:StudioID" json:"-"`
+	Showtimes []Showtime   `gorm:"foreignKey:StudioID" json:"-"`
+	Seats     []StudioSeat `gorm:"foreignKey:StudioID" json:"-"`
+}
+
+// AfterCreate hook - give every new studio a default rectangular seat map
+// The layout can then be reshaped through the admin layout editor
+func (s *Studio) AfterCreate(tx *gorm.DB) error {
+	if len(s.Seats) > 0 {
+		return nil
+	}
+
+	seats := GenerateGridSeats(s.ID, s.TotalRows, s.TotalCols)
+	if len(seats) == 0 {
+		return nil
+	}
+	return tx.Create(&seats).Error
 }
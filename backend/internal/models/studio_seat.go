@@ -0,0 +1,68 @@
+package models
+
+import (
+	"strconv"
+)
+
+// Studio seat states
+const (
+	SeatStateActive  = "ACTIVE"  // Sellable seat
+	SeatStateBlocked = "BLOCKED" // Physically present but not for sale (broken, camera position, etc.)
+	SeatStateRemoved = "REMOVED" // Grid cell kept for rendering, but there is no seat
+)
+
+// StudioSeat is a single seat in a studio's seat map.
+// Label is what customers select and what tickets store as seat_number.
+// GridRow/GridCol place the seat on the rendered map, so aisles, gaps,
+// stepped rows and curved sections are just cells without a seat.
+type StudioSeat struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	StudioID uint   `gorm:"not null;uniqueIndex:idx_studio_seats_label;uniqueIndex:idx_studio_seats_grid" json:"studio_id"`
+	Label    string `gorm:"type:varchar(10);not null;uniqueIndex:idx_studio_seats_label" json:"label"`
+	RowLabel string `gorm:"type:varchar(5);not null" json:"row_label"`
+	Number   int    `gorm:"not null" json:"number"`
+	GridRow  int    `gorm:"not null;uniqueIndex:idx_studio_seats_grid" json:"grid_row"`
+	GridCol  int    `gorm:"not null;uniqueIndex:idx_studio_seats_grid" json:"grid_col"`
+	State    string `gorm:"type:varchar(20);not null;default:'ACTIVE'" json:"state"`
+
+	// CategoryID sets the seat's price tier; nil means a regular seat at the showtime's base price
+	CategoryID *uint `gorm:"index" json:"category_id,omitempty"`
+
+	Studio   Studio        `gorm:"foreignKey:StudioID;constraint:OnDelete:CASCADE" json:"-"`
+	Category *SeatCategory `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL" json:"category,omitempty"`
+}
+
+// IsBookable reports whether the seat can be sold
+func (s *StudioSeat) IsBookable() bool {
+	return s.State == SeatStateActive
+}
+
+// GridRowLabel names the row at a zero-based grid index: A-Z, then AA, AB, ... like spreadsheet columns
+func GridRowLabel(index int) string {
+	label := ""
+	for n := index + 1; n > 0; n = (n - 1) / 26 {
+		label = string(rune('A'+(n-1)%26)) + label
+	}
+	return label
+}
+
+// GenerateGridSeats builds a full rectangular layout: rows A, B, ... (see GridRowLabel), columns 1-N.
+// Used as the default map for new studios and for studios created before seat maps existed.
+func GenerateGridSeats(studioID uint, rows, cols int) []StudioSeat {
+	seats := make([]StudioSeat, 0, rows*cols)
+	for r := 0; r < rows; r++ {
+		rowLabel := GridRowLabel(r)
+		for c := 0; c < cols; c++ {
+			seats = append(seats, StudioSeat{
+				StudioID: studioID,
+				Label:    rowLabel + strconv.Itoa(c+1),
+				RowLabel: rowLabel,
+				Number:   c + 1,
+				GridRow:  r,
+				GridCol:  c,
+				State:    SeatStateActive,
+			})
+		}
+	}
+	return seats
+}
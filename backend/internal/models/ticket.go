@@ -1,8 +1,12 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/money"
 )
 
 type Ticket struct {
@@ -10,6 +14,20 @@ type Ticket struct {
 	BookingID  uuid.UUID `gorm:"type:uuid;not null;index" json:"booking_id"`
 	ShowtimeID uint      `gorm:"not null;index" json:"showtime_id"`
 	SeatNumber string    `gorm:"type:varchar(10);not null" json:"seat_number"`
+
+	// Unit price snapshot at booking time (seat category and ticket type applied)
+	Price          money.Money `gorm:"not null;default:0" json:"price"`
+	SeatCategory   string      `gorm:"type:varchar(100)" json:"seat_category,omitempty"`
+	TicketType     string      `gorm:"type:varchar(30)" json:"ticket_type,omitempty"`
+	TicketTypeName string      `gorm:"type:varchar(100)" json:"ticket_type_name,omitempty"`
+
+	// Signed QR payload, issued once the booking is paid (see services.signTicket)
+	QRToken string `gorm:"type:varchar(200)" json:"qr_token,omitempty"`
+
+	// Door check-in; a ticket is admitted once
+	CheckedInAt *time.Time `gorm:"index" json:"checked_in_at,omitempty"`
+	CheckInGate string     `gorm:"type:varchar(50)" json:"check_in_gate,omitempty"`
+	CheckedInBy *uuid.UUID `gorm:"type:uuid" json:"checked_in_by,omitempty"`
 	
 	Booking  Booking  `gorm:"foreignKey:BookingID;constraint:OnDelete:CASCADE" json:"-"`
 	Showtime Showtime `gorm:"foreignKey:ShowtimeID;constraint:OnDelete:CASCADE" json:"-"`
@@ -33,6 +51,21 @@ func (t *Ticket) BeforeCreate(tx *gorm.DB) error {
 		return err
 	}
 	
+	if count > 0 {
+		return gorm.ErrDuplicatedKey
+	}
+
+	// Seats under an active hold are also unavailable. The holder's own
+	// holds are consumed (deleted) in the same transaction before tickets
+	// are created, so anything left here belongs to someone else.
+	err = tx.Model(&SeatHold{}).
+		Where("showtime_id = ? AND seat_number = ? AND expires_at > ?", t.ShowtimeID, t.SeatNumber, time.Now()).
+		Count(&count).Error
+
+	if err != nil {
+		return err
+	}
+
 	if count > 0 {
 		return gorm.ErrDuplicatedKey
 	}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a scan was made
+const (
+	AdmissionSourceOnline  = "ONLINE"  // POST /staff/checkin
+	AdmissionSourceOffline = "OFFLINE" // Uploaded later from a scanner's offline log
+)
+
+// Outcome of a scan
+const (
+	AdmissionResultAdmitted  = "ADMITTED"
+	AdmissionResultDuplicate = "DUPLICATE" // The ticket had been admitted by an earlier scan
+	AdmissionResultRejected  = "REJECTED"
+)
+
+// TicketAdmission is one scan of a ticket at a gate. The ticket's CheckedInAt is its
+// earliest admitted scan; later scans of the same ticket are recorded as duplicates.
+// A scanner re-uploading its log is idempotent thanks to the (device, ticket, time) key.
+type TicketAdmission struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TicketID   uint       `gorm:"not null;uniqueIndex:idx_admission_scan;index" json:"ticket_id"` // 0 when the token was invalid
+	BookingID  *uuid.UUID `gorm:"type:uuid;index" json:"booking_id,omitempty"`
+	ShowtimeID uint       `gorm:"not null;index" json:"showtime_id"`
+	Gate       string     `gorm:"type:varchar(50)" json:"gate"`
+	DeviceID   string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_admission_scan" json:"device_id"`
+	Source     string     `gorm:"type:varchar(20);not null" json:"source"`
+	ScannedAt  time.Time  `gorm:"not null;uniqueIndex:idx_admission_scan" json:"scanned_at"`
+	Result     string     `gorm:"type:varchar(20);not null" json:"result"`
+	Reason     string     `gorm:"type:text" json:"reason,omitempty"`
+	StaffID    uuid.UUID  `gorm:"type:uuid;not null" json:"staff_id"`
+	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
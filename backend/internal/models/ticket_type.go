@@ -0,0 +1,33 @@
+package models
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TicketType is an admin-defined ticket kind (adult, child, student, senior, ...).
+// The seat price is multiplied by PriceModifier, so 0.75 means 25% off.
+type TicketType struct {
+	ID            uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code          string  `gorm:"type:varchar(30);uniqueIndex:idx_ticket_types_code_active,where:deleted_at IS NULL;not null" json:"code"`
+	Name          string  `gorm:"type:varchar(100);not null" json:"name"`
+	PriceModifier float64 `gorm:"type:decimal(5,4);not null;default:1" json:"price_modifier"`
+
+	// ExcludedRatings is an optional eligibility rule: the type cannot be used
+	// for movies whose Movie.Rating is in this list (e.g. child tickets for "R" or "21+")
+	ExcludedRatings []string `gorm:"type:jsonb;serializer:json" json:"excluded_ratings"`
+
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// AllowsRating reports whether the ticket type may be used for a movie with the given rating
+func (tt *TicketType) AllowsRating(rating string) bool {
+	rating = strings.TrimSpace(rating)
+	for _, excluded := range tt.ExcludedRatings {
+		if strings.EqualFold(strings.TrimSpace(excluded), rating) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/money"
+)
+
+// Voucher discount types
+const (
+	VoucherTypePercentage = "PERCENTAGE"
+	VoucherTypeFixed      = "FIXED"
+)
+
+// Voucher redemption statuses
+const (
+	RedemptionStatusActive   = "ACTIVE"   // Counts against the usage caps
+	RedemptionStatusReleased = "RELEASED" // Booking was cancelled or expired; the use was given back
+)
+
+// Voucher is a promo code that discounts a booking.
+// Zero values on the limits mean "no limit"; empty restriction lists mean "any".
+type Voucher struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code         string `gorm:"type:varchar(50);uniqueIndex;not null" json:"code"`
+	Description  string `gorm:"type:text" json:"description"`
+	DiscountType string `gorm:"type:varchar(20);not null" json:"discount_type"`
+
+	// Amount is the discount of a FIXED voucher
+	Amount money.Money `gorm:"not null;default:0" json:"amount"`
+
+	// PercentBasisPoints is the discount of a PERCENTAGE voucher in hundredths of a percent (1250 = 12.5%)
+	PercentBasisPoints int `gorm:"not null;default:0" json:"percent_basis_points"`
+
+	// MaxDiscount caps percentage discounts (0 = uncapped)
+	MaxDiscount money.Money `gorm:"default:0" json:"max_discount"`
+
+	// Validity window (either end may be open)
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+
+	// Usage caps
+	MaxUses        int `gorm:"default:0" json:"max_uses"`
+	MaxUsesPerUser int `gorm:"default:0" json:"max_uses_per_user"`
+	MinSeats       int `gorm:"default:0" json:"min_seats"`
+
+	// Restrictions; Weekdays use time.Weekday numbering (0 = Sunday) of the showtime start
+	MovieIDs  []uint `gorm:"type:jsonb;serializer:json" json:"movie_ids"`
+	StudioIDs []uint `gorm:"type:jsonb;serializer:json" json:"studio_ids"`
+	Weekdays  []int  `gorm:"type:jsonb;serializer:json" json:"weekdays"`
+
+	IsActive  bool           `gorm:"not null" json:"is_active"` // No column default, so an explicit false is stored
+	CreatedAt time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// VoucherRedemption records one use of a voucher by a booking
+type VoucherRedemption struct {
+	ID             uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	VoucherID      uint        `gorm:"not null;index" json:"voucher_id"`
+	UserID         uuid.UUID   `gorm:"type:uuid;not null;index" json:"user_id"`
+	BookingID      uuid.UUID   `gorm:"type:uuid;not null;uniqueIndex" json:"booking_id"`
+	DiscountAmount money.Money `gorm:"not null" json:"discount_amount"`
+	Status         string      `gorm:"type:varchar(20);not null;default:'ACTIVE';index" json:"status"`
+	CreatedAt      time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	ReleasedAt     *time.Time  `json:"released_at,omitempty"`
+
+	Voucher Voucher `gorm:"foreignKey:VoucherID;constraint:OnDelete:CASCADE" json:"-"`
+	Booking Booking `gorm:"foreignKey:BookingID;constraint:OnDelete:CASCADE" json:"-"`
+}
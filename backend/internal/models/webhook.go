@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"absolutcinema-backend/internal/money"
+)
 
 // XenditInvoiceCallback represents the webhook payload from Xendit for invoice callbacks
 // Documentation: https://developers.xendit.co/api-reference/#invoice-callback
@@ -21,10 +26,10 @@ type XenditInvoiceCallback struct {
 	MerchantName string `json:"merchant_name"`
 
 	// Amount is the invoice amount
-	Amount float64 `json:"amount"`
+	Amount money.Money `json:"amount"`
 
 	// PaidAmount is the amount that was actually paid
-	PaidAmount float64 `json:"paid_amount,omitempty"`
+	PaidAmount money.Money `json:"paid_amount,omitzero"`
 
 	// BankCode is the bank used for payment (if applicable)
 	BankCode string `json:"bank_code,omitempty"`
@@ -39,10 +44,10 @@ type XenditInvoiceCallback struct {
 	Description string `json:"description,omitempty"`
 
 	// AdjustedReceivedAmount after fees
-	AdjustedReceivedAmount float64 `json:"adjusted_received_amount,omitempty"`
+	AdjustedReceivedAmount money.Money `json:"adjusted_received_amount,omitzero"`
 
 	// FeesPaidAmount is the fees deducted
-	FeesPaidAmount float64 `json:"fees_paid_amount,omitempty"`
+	FeesPaidAmount money.Money `json:"fees_paid_amount,omitzero"`
 
 	// Updated timestamp
 	Updated time.Time `json:"updated,omitempty"`
@@ -63,9 +68,77 @@ type XenditInvoiceCallback struct {
 	PaymentDestination string `json:"payment_destination,omitempty"`
 }
 
+// gatewayAmount decodes an amount reported by the gateway, rounding fractions
+// (e.g. a fee of 1998.5) instead of rejecting the whole callback
+type gatewayAmount money.Money
+
+func (a *gatewayAmount) UnmarshalJSON(data []byte) error {
+	m, err := money.DecodeLenientJSON(data)
+	if err != nil {
+		return err
+	}
+	*a = gatewayAmount(m)
+	return nil
+}
+
+// UnmarshalJSON decodes the callback with its amounts rounded to whole minor units
+func (c *XenditInvoiceCallback) UnmarshalJSON(data []byte) error {
+	type plain XenditInvoiceCallback
+	aux := struct {
+		*plain
+		Amount                 gatewayAmount `json:"amount"`
+		PaidAmount             gatewayAmount `json:"paid_amount"`
+		AdjustedReceivedAmount gatewayAmount `json:"adjusted_received_amount"`
+		FeesPaidAmount         gatewayAmount `json:"fees_paid_amount"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.Amount = money.Money(aux.Amount)
+	c.PaidAmount = money.Money(aux.PaidAmount)
+	c.AdjustedReceivedAmount = money.Money(aux.AdjustedReceivedAmount)
+	c.FeesPaidAmount = money.Money(aux.FeesPaidAmount)
+	return nil
+}
+
 // Xendit Invoice Status Constants
 const (
 	XenditStatusPaid    = "PAID"
 	XenditStatusSettled = "SETTLED"
 	XenditStatusExpired = "EXPIRED"
 )
+
+// XenditRefundCallback is the webhook payload Xendit sends when a refund settles
+// Documentation: https://developers.xendit.co/api-reference/#refund-callback
+type XenditRefundCallback struct {
+	// Event is refund.succeeded or refund.failed
+	Event      string             `json:"event"`
+	BusinessID string             `json:"business_id"`
+	Created    time.Time          `json:"created"`
+	Data       XenditRefundDetail `json:"data"`
+}
+
+// XenditRefundDetail is the refund carried by a refund callback
+type XenditRefundDetail struct {
+	ID          string      `json:"id"`
+	InvoiceID   string      `json:"invoice_id"`
+	Amount      money.Money `json:"amount"`
+	Currency    string      `json:"currency"`
+	Status      string      `json:"status"` // SUCCEEDED or FAILED
+	FailureCode string      `json:"failure_code,omitempty"`
+	Reason      string      `json:"reason"`
+}
+
+// UnmarshalJSON decodes the refund with its amount rounded to whole minor units
+func (d *XenditRefundDetail) UnmarshalJSON(data []byte) error {
+	type plain XenditRefundDetail
+	aux := struct {
+		*plain
+		Amount gatewayAmount `json:"amount"`
+	}{plain: (*plain)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.Amount = money.Money(aux.Amount)
+	return nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Webhook event processing statuses
+const (
+	WebhookEventReceived  = "RECEIVED"  // Stored, processing not finished
+	WebhookEventProcessed = "PROCESSED" // Handled successfully
+	WebhookEventFailed    = "FAILED"    // Processing returned an error; can be replayed
+	WebhookEventRejected  = "REJECTED"  // Callback token verification failed; never processed
+)
+
+// WebhookEvent is the raw record of one incoming payment callback.
+// Verified events are deduplicated by DedupeKey (invoice ID + status), so a
+// callback Xendit retries is only processed until it succeeds once.
+type WebhookEvent struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Provider string `gorm:"type:varchar(20);not null" json:"provider"`
+
+	// DedupeKey is "<invoice id>:<status>"; left NULL for unverified or unparseable
+	// callbacks so they can never shadow a genuine one
+	DedupeKey *string `gorm:"type:varchar(150);uniqueIndex" json:"dedupe_key,omitempty"`
+
+	InvoiceID  string `gorm:"type:varchar(100);index" json:"invoice_id"`
+	ExternalID string `gorm:"type:varchar(100);index" json:"external_id"`
+	Status     string `gorm:"type:varchar(30)" json:"status"` // Invoice status in the payload
+
+	RawBody string            `gorm:"type:text;not null" json:"raw_body"`
+	Headers map[string]string `gorm:"type:jsonb;serializer:json" json:"headers"`
+
+	Verified          bool   `gorm:"not null;default:false" json:"verified"`
+	VerificationError string `gorm:"type:text" json:"verification_error,omitempty"`
+
+	ProcessingStatus string     `gorm:"type:varchar(20);not null;index" json:"processing_status"`
+	Error            string     `gorm:"type:text" json:"error,omitempty"`
+	Attempts         int        `gorm:"not null;default:0" json:"attempts"`       // Times processing ran (deliveries + replays)
+	DeliveryCount    int        `gorm:"not null;default:1" json:"delivery_count"` // Times the provider sent this event
+	ReceivedAt       time.Time  `gorm:"not null;index" json:"received_at"`
+	ProcessedAt      *time.Time `json:"processed_at,omitempty"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"absolutcinema-backend/internal/money"
+)
+
+func TestXenditInvoiceCallbackRoundsFractionalAmounts(t *testing.T) {
+	body := `{
+		"id": "inv-1",
+		"external_id": "booking-1",
+		"status": "PAID",
+		"amount": 50000,
+		"paid_amount": 50000,
+		"fees_paid_amount": 1998.5,
+		"adjusted_received_amount": 48001.5,
+		"currency": "IDR"
+	}`
+
+	var callback XenditInvoiceCallback
+	if err := json.Unmarshal([]byte(body), &callback); err != nil {
+		t.Fatalf("callback with fractional fees was rejected: %v", err)
+	}
+	if callback.ID != "inv-1" || callback.Status != XenditStatusPaid || callback.Currency != "IDR" {
+		t.Errorf("other fields lost: %+v", callback)
+	}
+
+	want := map[string][2]money.Money{
+		"amount":                   {callback.Amount, money.FromMajor(50000)},
+		"paid_amount":              {callback.PaidAmount, money.FromMajor(50000)},
+		"fees_paid_amount":         {callback.FeesPaidAmount, money.FromMajor(1999)},
+		"adjusted_received_amount": {callback.AdjustedReceivedAmount, money.FromMajor(48002)},
+	}
+	for field, got := range want {
+		if !got[0].Equal(got[1]) {
+			t.Errorf("%s = %s, want %s", field, got[0], got[1])
+		}
+	}
+}
+
+func TestMoneyStillRejectsFractionalInput(t *testing.T) {
+	var m money.Money
+	if err := json.Unmarshal([]byte(`1998.5`), &m); err == nil {
+		t.Errorf("strict decoding accepted a fractional rupiah amount: %s", m)
+	}
+}
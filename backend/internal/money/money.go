@@ -0,0 +1,415 @@
+// Package money implements exact monetary amounts stored as integer minor units.
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+const (
+	IDR Currency = "IDR"
+
+	// DefaultCurrency is the currency of every amount that does not state one
+	DefaultCurrency = IDR
+)
+
+// exponents holds the number of minor-unit digits per currency.
+// Rupiah is handled in whole units, as Xendit does.
+var exponents = map[Currency]int{
+	IDR: 0,
+}
+
+// Exponent returns the number of decimal digits of the currency's minor unit
+func (c Currency) Exponent() int {
+	return exponents[c]
+}
+
+// Money is an exact amount of a currency, held as an integer number of minor units.
+// The zero value is zero of DefaultCurrency.
+type Money struct {
+	amount   int64
+	currency Currency
+}
+
+// New returns an amount of minor units of the given currency
+func New(minor int64, currency Currency) Money {
+	return Money{amount: minor, currency: currency}
+}
+
+// FromMinor returns an amount of minor units of DefaultCurrency
+func FromMinor(minor int64) Money {
+	return New(minor, DefaultCurrency)
+}
+
+// FromMajor returns a whole amount of major units of DefaultCurrency (e.g. Rp 50000)
+func FromMajor(major int64) Money {
+	return New(major*pow10(DefaultCurrency.Exponent()), DefaultCurrency)
+}
+
+// FromFloat converts a float amount in major units, as returned by payment SDKs,
+// rounding to the nearest minor unit
+func FromFloat(major float64, currency Currency) Money {
+	return New(int64(math.Round(major*float64(pow10(currency.Exponent())))), currency)
+}
+
+// Parse parses a decimal amount in major units (e.g. "50000" or "12.50").
+// More fraction digits than the currency has are rejected unless they are zero.
+func Parse(s string, currency Currency) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, errors.New("empty amount")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+
+	exp := currency.Exponent()
+	if len(frac) > exp {
+		if strings.Trim(frac[exp:], "0") != "" {
+			return Money{}, fmt.Errorf("amount %q has more precision than %s allows", s, currency)
+		}
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	digits := strings.TrimLeft(whole+frac, "0")
+	if digits == "" {
+		return New(0, currency), nil
+	}
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("amount %q is out of range", s)
+	}
+	if negative {
+		minor = -minor
+	}
+	return New(minor, currency), nil
+}
+
+// Minor returns the amount in minor units
+func (m Money) Minor() int64 {
+	return m.amount
+}
+
+// Currency returns the amount's currency
+func (m Money) Currency() Currency {
+	if m.currency == "" {
+		return DefaultCurrency
+	}
+	return m.currency
+}
+
+// Float64 returns the amount in major units; only for APIs that insist on floats
+func (m Money) Float64() float64 {
+	return float64(m.amount) / float64(pow10(m.Currency().Exponent()))
+}
+
+// Float32 returns the amount in major units; only for APIs that insist on floats
+func (m Money) Float32() float32 {
+	return float32(m.Float64())
+}
+
+// Add returns m + o, or an error when they are in different currencies
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.match(o); err != nil {
+		return Money{}, err
+	}
+	return New(m.amount+o.amount, m.Currency()), nil
+}
+
+// Sub returns m - o, or an error when they are in different currencies
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.match(o); err != nil {
+		return Money{}, err
+	}
+	return New(m.amount-o.amount, m.Currency()), nil
+}
+
+// Sum adds up amounts; the sum of none is zero of DefaultCurrency
+func Sum(amounts ...Money) (Money, error) {
+	if len(amounts) == 0 {
+		return Money{}, nil
+	}
+	total := amounts[0]
+	for _, a := range amounts[1:] {
+		var err error
+		if total, err = total.Add(a); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return New(-m.amount, m.Currency())
+}
+
+// MulInt returns m multiplied by a whole quantity
+func (m Money) MulInt(n int64) Money {
+	return New(m.amount*n, m.Currency())
+}
+
+// MulRatio returns m * num / den, rounded half away from zero to the minor unit
+func (m Money) MulRatio(num, den int64) Money {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+	product := m.amount * num
+	quotient, remainder := product/den, product%den
+	if remainder != 0 && 2*abs(remainder) >= abs(den) {
+		if (product < 0) != (den < 0) {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+	return New(quotient, m.Currency())
+}
+
+// Allocate splits m into n parts that differ by at most one minor unit and sum exactly to m.
+// The leftover units go to the first parts.
+func (m Money) Allocate(n int) []Money {
+	if n <= 0 {
+		return nil
+	}
+	parts := make([]Money, n)
+	share, leftover := m.amount/int64(n), m.amount%int64(n)
+	for i := range parts {
+		part := share
+		if int64(i) < abs(leftover) {
+			if leftover > 0 {
+				part++
+			} else {
+				part--
+			}
+		}
+		parts[i] = New(part, m.Currency())
+	}
+	return parts
+}
+
+// Cmp compares m and o, returning -1, 0 or +1, or an error when they are in different currencies
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.match(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.amount < o.amount:
+		return -1, nil
+	case m.amount > o.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Equal reports whether m and o are the same amount of the same currency
+func (m Money) Equal(o Money) bool {
+	return m.Currency() == o.Currency() && m.amount == o.amount
+}
+
+// LessThan reports whether m < o
+func (m Money) LessThan(o Money) (bool, error) {
+	c, err := m.Cmp(o)
+	return c < 0, err
+}
+
+// GreaterThan reports whether m > o
+func (m Money) GreaterThan(o Money) (bool, error) {
+	c, err := m.Cmp(o)
+	return c > 0, err
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.amount > 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// Min returns the smaller of m and o
+func Min(m, o Money) (Money, error) {
+	less, err := o.LessThan(m)
+	if err != nil {
+		return Money{}, err
+	}
+	if less {
+		return o, nil
+	}
+	return m, nil
+}
+
+// String formats the amount in major units with its currency, e.g. "IDR 50000"
+func (m Money) String() string {
+	return string(m.Currency()) + " " + m.Decimal()
+}
+
+// Decimal formats the amount in major units without a currency, e.g. "50000"
+func (m Money) Decimal() string {
+	exp := m.Currency().Exponent()
+	s := strconv.FormatInt(abs(m.amount), 10)
+	if exp > 0 {
+		if len(s) <= exp {
+			s = strings.Repeat("0", exp-len(s)+1) + s
+		}
+		s = s[:len(s)-exp] + "." + s[len(s)-exp:]
+	}
+	if m.amount < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// MarshalJSON encodes the amount as an exact JSON number in major units
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or numeric string in major units of DefaultCurrency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	} else if strings.ContainsAny(s, "eE") {
+		// Exponent notation, e.g. 5e4, still has to be exact
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil {
+			return fmt.Errorf("invalid amount %s", s)
+		}
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	parsed, err := Parse(s, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// DecodeLenientJSON decodes a JSON number or numeric string in major units of DefaultCurrency,
+// rounding any precision beyond the minor unit. Only for amounts reported by payment
+// gateways, which must not be rejected; amounts people enter go through UnmarshalJSON.
+func DecodeLenientJSON(data []byte) (Money, error) {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return Money{}, nil
+	}
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = strings.TrimSpace(unquoted)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return Money{}, fmt.Errorf("invalid amount %s", s)
+	}
+	return FromFloat(f, DefaultCurrency), nil
+}
+
+// Value stores the amount as a bigint of minor units. Columns hold no currency,
+// so only DefaultCurrency amounts can be stored.
+func (m Money) Value() (driver.Value, error) {
+	if m.Currency() != DefaultCurrency {
+		return nil, fmt.Errorf("money: cannot store %s amount, columns hold %s", m.Currency(), DefaultCurrency)
+	}
+	return m.amount, nil
+}
+
+// Scan reads a bigint of minor units of DefaultCurrency, the only currency Value stores
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = Money{}
+	case int64:
+		*m = FromMinor(v)
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("cannot scan %T into money", value)
+	}
+	return nil
+}
+
+func (m *Money) scanString(s string) error {
+	minor, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into money: %w", s, err)
+	}
+	*m = FromMinor(minor)
+	return nil
+}
+
+// GormDataType stores money columns as bigint
+func (Money) GormDataType() string {
+	return "bigint"
+}
+
+// ErrCurrencyMismatch is returned when amounts in different currencies are combined
+var ErrCurrencyMismatch = errors.New("money: currency mismatch")
+
+// match returns an error when two amounts are in different currencies
+func (m Money) match(o Money) error {
+	if m.Currency() != o.Currency() {
+		return fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, m.Currency(), o.Currency())
+	}
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,75 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestAllocateSumsExactly(t *testing.T) {
+	total := FromMajor(100000)
+	parts := total.Allocate(3)
+
+	sum, err := Sum(parts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sum.Equal(total) {
+		t.Fatalf("Allocate parts sum to %s, want %s", sum, total)
+	}
+	if parts[0].Minor() != 33334 || parts[2].Minor() != 33333 {
+		t.Errorf("Allocate gave %v", parts)
+	}
+}
+
+func TestMulRatioRoundsHalfUp(t *testing.T) {
+	// 45000 * 0.8333 = 37498.5
+	if got := FromMajor(45000).MulRatio(8333, 10000); got.Minor() != 37499 {
+		t.Errorf("MulRatio = %s, want IDR 37499", got)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var m Money
+	if err := json.Unmarshal([]byte(`50000.00`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Equal(FromMajor(50000)) {
+		t.Errorf("Unmarshal = %s", m)
+	}
+
+	out, _ := json.Marshal(m)
+	if string(out) != "50000" {
+		t.Errorf("Marshal = %s, want 50000", out)
+	}
+
+	if err := json.Unmarshal([]byte(`50000.5`), &m); err == nil {
+		t.Error("Unmarshal accepted a fraction of a rupiah")
+	}
+}
+
+func TestCurrencyMismatchIsAnError(t *testing.T) {
+	idr, usd := FromMajor(100), New(100, "USD")
+
+	if _, err := idr.Add(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add error = %v, want a currency mismatch", err)
+	}
+	if _, err := idr.Sub(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub error = %v, want a currency mismatch", err)
+	}
+	if _, err := idr.GreaterThan(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("GreaterThan error = %v, want a currency mismatch", err)
+	}
+	if _, err := Sum(idr, idr, usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sum error = %v, want a currency mismatch", err)
+	}
+}
+
+func TestValueStoresOnlyDefaultCurrency(t *testing.T) {
+	if v, err := FromMajor(50000).Value(); err != nil || v != int64(50000) {
+		t.Errorf("Value = %v, %v; want 50000", v, err)
+	}
+	if _, err := New(5000, "USD").Value(); err == nil {
+		t.Error("Value stored a USD amount in a column read back as IDR")
+	}
+}
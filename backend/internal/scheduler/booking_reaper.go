@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// BookingReaper expires PENDING bookings whose payment never arrived.
+// It is the safety net for lost Xendit EXPIRED callbacks.
+type BookingReaper struct {
+	bookingService *services.BookingService
+	maxAge         time.Duration
+}
+
+// NewBookingReaper creates a reaper that expires PENDING bookings past their payment deadline.
+// Bookings without a deadline expire once they are older than maxAge.
+func NewBookingReaper(bookingService *services.BookingService, maxAge time.Duration) *BookingReaper {
+	return &BookingReaper{
+		bookingService: bookingService,
+		maxAge:         maxAge,
+	}
+}
+
+// Name implements Job
+func (r *BookingReaper) Name() string {
+	return "booking-reaper"
+}
+
+// Run implements Job
+func (r *BookingReaper) Run(ctx context.Context) error {
+	expired, err := r.bookingService.ExpireStalePendingBookings(ctx, r.maxAge)
+	if expired > 0 {
+		log.Printf("[Scheduler] Booking reaper expired %d abandoned booking(s)", expired)
+	}
+	return err
+}
@@ -0,0 +1,17 @@
+package scheduler
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// DurationFromEnv reads an integer environment variable expressed in unit,
+// falling back to def when it is unset or invalid
+func DurationFromEnv(key string, unit time.Duration, def time.Duration) time.Duration {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return time.Duration(value) * unit
+}
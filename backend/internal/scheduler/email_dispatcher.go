@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// EmailDispatcher delivers the emails queued in the outbox
+type EmailDispatcher struct {
+	notificationService *services.NotificationService
+}
+
+// NewEmailDispatcher creates a new email dispatcher
+func NewEmailDispatcher(notificationService *services.NotificationService) *EmailDispatcher {
+	return &EmailDispatcher{notificationService: notificationService}
+}
+
+// Name implements Job
+func (d *EmailDispatcher) Name() string {
+	return "email-dispatcher"
+}
+
+// Run implements Job
+func (d *EmailDispatcher) Run(ctx context.Context) error {
+	sent, err := d.notificationService.DeliverPendingEmails(ctx)
+	if sent > 0 {
+		log.Printf("[Scheduler] Email dispatcher sent %d email(s)", sent)
+	}
+	return err
+}
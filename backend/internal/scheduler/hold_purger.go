@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// HoldPurger deletes expired seat holds so live seat maps see the seats freed
+type HoldPurger struct {
+	holdService *services.HoldService
+}
+
+// NewHoldPurger creates a new hold purger
+func NewHoldPurger(holdService *services.HoldService) *HoldPurger {
+	return &HoldPurger{holdService: holdService}
+}
+
+// Name implements Job
+func (p *HoldPurger) Name() string {
+	return "hold-purger"
+}
+
+// Run implements Job
+func (p *HoldPurger) Run(ctx context.Context) error {
+	purged, err := p.holdService.PurgeExpiredHolds()
+	if purged > 0 {
+		log.Printf("[Scheduler] Hold purger released %d expired seat hold(s)", purged)
+	}
+	return err
+}
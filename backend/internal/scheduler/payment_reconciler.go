@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// PaymentReconciler compares bookings with their invoices at the payment gateway
+// and fixes drift left by webhooks that never arrived
+type PaymentReconciler struct {
+	bookingService *services.BookingService
+	lookback       time.Duration
+}
+
+// NewPaymentReconciler creates a reconciler that also rechecks CANCELLED/EXPIRED
+// bookings created within lookback
+func NewPaymentReconciler(bookingService *services.BookingService, lookback time.Duration) *PaymentReconciler {
+	return &PaymentReconciler{
+		bookingService: bookingService,
+		lookback:       lookback,
+	}
+}
+
+// Name implements Job
+func (r *PaymentReconciler) Name() string {
+	return "payment-reconciler"
+}
+
+// Run implements Job
+func (r *PaymentReconciler) Run(ctx context.Context) error {
+	summary, err := r.bookingService.ReconcilePayments(ctx, r.lookback)
+	if summary != nil && (summary.Corrected > 0 || summary.Failed > 0) {
+		log.Printf("[Scheduler] Payment reconciler run %s: checked %d, corrected %d, failed %d",
+			summary.RunID, summary.Checked, summary.Corrected, summary.Failed)
+	}
+	return err
+}
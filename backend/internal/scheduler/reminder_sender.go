@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+
+	"absolutcinema-backend/internal/services"
+)
+
+// ReminderSender sends showtime reminders once they are due
+type ReminderSender struct {
+	reminderService *services.ReminderService
+}
+
+// NewReminderSender creates a new reminder sender
+func NewReminderSender(reminderService *services.ReminderService) *ReminderSender {
+	return &ReminderSender{reminderService: reminderService}
+}
+
+// Name implements Job
+func (s *ReminderSender) Name() string {
+	return "reminder-sender"
+}
+
+// Run implements Job
+func (s *ReminderSender) Run(ctx context.Context) error {
+	sent, err := s.reminderService.SendDueReminders(ctx)
+	if sent > 0 {
+		log.Printf("[Scheduler] Reminder sender sent %d showtime reminder(s)", sent)
+	}
+	return err
+}
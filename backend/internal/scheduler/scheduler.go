@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Job is a unit of background work run periodically by the Scheduler
+type Job interface {
+	// Name identifies the job in logs and derives its advisory lock key.
+	Name() string
+
+	// Run performs one pass of the job. It should return promptly once ctx is cancelled.
+	Run(ctx context.Context) error
+}
+
+type entry struct {
+	job      Job
+	interval time.Duration
+}
+
+// Scheduler runs registered jobs on fixed intervals inside the API process.
+// Each run is guarded by a Postgres advisory lock, so when several replicas
+// are deployed only one of them executes a given job at a time.
+type Scheduler struct {
+	db      *gorm.DB
+	entries []entry
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler that uses db for advisory locking
+func New(db *gorm.DB) *Scheduler {
+	return &Scheduler{db: db}
+}
+
+// Every registers job to run once per interval. Must be called before Start.
+func (s *Scheduler) Every(interval time.Duration, job Job) {
+	s.entries = append(s.entries, entry{job: job, interval: interval})
+}
+
+// Start launches one goroutine per registered job
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(ctx, e)
+	}
+
+	log.Printf("[Scheduler] Started %d job(s)", len(s.entries))
+}
+
+// Stop signals all jobs to finish and waits for running passes to return,
+// or until ctx expires
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		log.Println("[Scheduler] All jobs stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduler did not stop in time: %w", ctx.Err())
+	}
+}
+
+// loop runs a job immediately and then on every tick until ctx is cancelled
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, e.job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce executes a single pass of job while holding its advisory lock.
+// The lock is session-scoped on a dedicated connection, so the job itself runs
+// its own short transactions instead of one held open for the whole pass. If
+// this replica crashes mid-run, the connection closes and Postgres drops the lock.
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	if err := s.withLock(ctx, job); err != nil && ctx.Err() == nil {
+		log.Printf("[Scheduler] Job %s failed: %v", job.Name(), err)
+	}
+}
+
+func (s *Scheduler) withLock(ctx context.Context, job Job) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database handle: %w", err)
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reserve a connection: %w", err)
+	}
+	defer conn.Close()
+
+	key := lockKey(job.Name())
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		return fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+	if !acquired {
+		// Another replica is running this job right now
+		return nil
+	}
+	defer func() {
+		// Unlock even when ctx is already cancelled, or the pooled connection keeps the lock
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			log.Printf("[Scheduler] Failed to release lock for %s: %v", job.Name(), err)
+			// Don't return a connection that may still hold the lock to the pool
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+// lockKey derives a stable advisory lock key from a job name
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("absolutcinema:job:" + name))
+	return int64(h.Sum64())
+}
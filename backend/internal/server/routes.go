@@ -58,27 +58,69 @@ func (s *Server) RegisterRoutes() http.Handler {
 	studioService := services.NewStudioService(s.db.DB())
 	movieService := services.NewMovieService(s.db.DB())
 	showtimeService := services.NewShowtimeService(s.db.DB())
-
-	// Initialize payment service (optional - may fail if XENDIT_SECRET_KEY not set)
-	var paymentService *services.PaymentService
-	var err error
-	paymentService, err = services.NewPaymentService()
+	holdService := services.NewHoldService(s.db.DB())
+	seatCategoryService := services.NewSeatCategoryService(s.db.DB())
+	ticketTypeService := services.NewTicketTypeService(s.db.DB())
+	voucherService := services.NewVoucherService(s.db.DB())
+	cancellationPolicyService := services.NewCancellationPolicyService(s.db.DB())
+	numberingService := services.NewNumberingService(s.db.DB())
+	ledgerService := services.NewLedgerService(s.db.DB())
+	reportService := services.NewReportService(s.db.DB())
+	concessionService := services.NewConcessionService(s.db.DB())
+	checkinService := services.NewCheckinService(s.db.DB())
+	reminderService := services.NewReminderService(s.db.DB(), services.NewReminderChannels(s.db.DB()))
+
+	// Initialize payment provider (optional - may fail if XENDIT_SECRET_KEY not set)
+	paymentProvider, err := services.NewPaymentProvider()
 	if err != nil {
-		log.Printf("Warning: Payment service initialization failed: %v", err)
+		log.Printf("Warning: Payment provider initialization failed: %v", err)
 		log.Println("Bookings will be created without payment links")
-		paymentService = nil
+		paymentProvider = nil
+	} else {
+		log.Printf("Using %s payment provider", paymentProvider.Name())
 	}
 
 	// Initialize booking service
-	bookingService := services.NewBookingService(s.db.DB(), paymentService)
+	bookingService := services.NewBookingService(s.db.DB(), paymentProvider)
+	webhookEventService := services.NewWebhookEventService(s.db.DB(), bookingService)
+
+	// Initialize live seat events (Postgres LISTEN/NOTIFY fan-out)
+	s.seatEventHub = services.NewSeatEventHub(s.db.DB())
+	s.seatEventHub.Start()
 
 	// Initialize controllers
 	studioController := controllers.NewStudioController(studioService)
 	movieController := controllers.NewMovieController(movieService)
 	showtimeController := controllers.NewShowtimeController(showtimeService)
 	bookingController := controllers.NewBookingController(bookingService)
-	publicController := controllers.NewPublicController(movieService, showtimeService, studioService, bookingService)
-	webhookController := controllers.NewWebhookController(bookingService)
+	holdController := controllers.NewHoldController(holdService)
+	seatCategoryController := controllers.NewSeatCategoryController(seatCategoryService)
+	ticketTypeController := controllers.NewTicketTypeController(ticketTypeService)
+	voucherController := controllers.NewVoucherController(voucherService)
+	cancellationPolicyController := controllers.NewCancellationPolicyController(cancellationPolicyService)
+	numberSeriesController := controllers.NewNumberSeriesController(numberingService)
+	ledgerController := controllers.NewLedgerController(ledgerService)
+	reportController := controllers.NewReportController(reportService)
+	concessionController := controllers.NewConcessionController(concessionService)
+	checkinController := controllers.NewCheckinController(checkinService)
+	reminderController := controllers.NewReminderController(reminderService)
+	seatStreamController := controllers.NewSeatStreamController(s.seatEventHub, bookingService)
+	publicController := controllers.NewPublicController(movieService, showtimeService, studioService, bookingService, seatCategoryService)
+	webhookController := controllers.NewWebhookController(webhookEventService, bookingService)
+
+	// Simulated checkout for the fake payment provider (development only).
+	// Its callbacks go through this router, exactly like Xendit's webhook calls.
+	if fakeProvider, ok := paymentProvider.(*services.FakePaymentProvider); ok {
+		fakeProvider.SetCallbackHandler(r)
+
+		fakeCheckoutController := controllers.NewFakeCheckoutController(fakeProvider)
+		fakeCheckoutRoutes := r.Group(services.FakeCheckoutPath)
+		{
+			fakeCheckoutRoutes.GET("/:invoiceId", fakeCheckoutController.ShowCheckout)
+			fakeCheckoutRoutes.POST("/:invoiceId/pay", fakeCheckoutController.Pay)
+			fakeCheckoutRoutes.POST("/:invoiceId/expire", fakeCheckoutController.Expire)
+		}
+	}
 
 	// Note: DigitalOcean routes /api/* to this backend, so we don't need /api prefix here
 	// Routes are defined from root since DO strips the /api prefix
@@ -98,9 +140,16 @@ func (s *Server) RegisterRoutes() http.Handler {
 	// Public showtime routes (read-only)
 	showtimeRoutes := r.Group("/showtimes")
 	{
-		showtimeRoutes.GET("", showtimeController.GetAllShowtimes)          // List with filters
-		showtimeRoutes.GET("/:id", showtimeController.GetShowtimeByID)      // Get single showtime
-		showtimeRoutes.GET("/:id/seats", publicController.GetOccupiedSeats) // Get occupied seats
+		showtimeRoutes.GET("", showtimeController.GetAllShowtimes)                          // List with filters
+		showtimeRoutes.GET("/:id", showtimeController.GetShowtimeByID)                      // Get single showtime
+		showtimeRoutes.GET("/:id/seats", publicController.GetOccupiedSeats)                 // Get occupied seats
+		showtimeRoutes.GET("/:id/seats/stream", seatStreamController.StreamSeats)           // Live seat changes (SSE)
+		showtimeRoutes.GET("/:id/concessions", concessionController.GetShowtimeConcessions) // Food & beverage on sale
+
+		// Seat holds (login optional - anonymous holds are claimed via hold_id at booking time)
+		showtimeRoutes.POST("/:id/holds", middleware.OptionalAuthMiddleware(), holdController.CreateHold)
+		showtimeRoutes.GET("/:id/holds/:holdId", holdController.GetHold)
+		showtimeRoutes.DELETE("/:id/holds/:holdId", middleware.OptionalAuthMiddleware(), holdController.ReleaseHold)
 	}
 
 	// Public movie routes (read-only)
@@ -116,12 +165,16 @@ func (s *Server) RegisterRoutes() http.Handler {
 		studioRoutes.GET("/:id", publicController.GetStudioLayout) // Get studio seat layout
 	}
 
+	// Public ticket type routes (read-only, for the booking form)
+	r.GET("/ticket-types", ticketTypeController.GetAllTicketTypes)
+
 	// Webhook routes (public but secured by callback token)
 	// IMPORTANT: These routes must NOT have JWT middleware
 	// Security is handled by validating the x-callback-token header
 	webhookRoutes := r.Group("/webhooks")
 	{
 		webhookRoutes.POST("/xendit", webhookController.HandleXenditCallback)
+		webhookRoutes.POST("/xendit/refund", webhookController.HandleXenditRefundCallback)
 	}
 
 	// Protected routes - require authentication
@@ -131,15 +184,32 @@ func (s *Server) RegisterRoutes() http.Handler {
 		// Example: User routes (authenticated users only)
 		protected.GET("/profile", s.getProfileHandler)
 
+		// Showtime reminder preferences (any signed-in user)
+		protected.GET("/reminders/preferences", reminderController.GetPreferences)
+		protected.PUT("/reminders/preferences", reminderController.UpdatePreferences)
+
 		// Booking routes (Customer/Admin)
 		bookingRoutes := protected.Group("/bookings")
 		bookingRoutes.Use(middleware.RequireAdminOrCustomer())
 		{
-			bookingRoutes.GET("", bookingController.GetBookings)                     // List own bookings
-			bookingRoutes.POST("", bookingController.CreateBooking)                  // Create booking
-			bookingRoutes.GET("/:id", bookingController.GetBookingByID)              // Get booking by ID
-			bookingRoutes.DELETE("/:id", bookingController.CancelBooking)            // Cancel booking
-			bookingRoutes.POST("/:id/retry-payment", bookingController.RetryPayment) // Retry payment
+			bookingRoutes.GET("", bookingController.GetBookings)                          // List own bookings
+			bookingRoutes.POST("", bookingController.CreateBooking)                       // Create booking
+			bookingRoutes.GET("/:id", bookingController.GetBookingByID)                   // Get booking by ID
+			bookingRoutes.DELETE("/:id", bookingController.CancelBooking)                 // Cancel booking
+			bookingRoutes.POST("/:id/retry-payment", bookingController.RetryPayment)      // Retry payment
+			bookingRoutes.POST("/:id/refund", bookingController.RequestRefund)            // Refund within policy
+			bookingRoutes.GET("/:id/tickets/:ticketId/qr", bookingController.GetTicketQR) // Ticket QR code (png/svg)
+			bookingRoutes.GET("/:id/ticket.pdf", bookingController.GetTicketPDF)          // Printable e-ticket
+			bookingRoutes.GET("/:id/receipt.pdf", bookingController.GetReceiptPDF)        // Receipt
+		}
+
+		// Staff routes (ushers at the door)
+		staffRoutes := protected.Group("/staff")
+		staffRoutes.Use(middleware.RequireStaff())
+		{
+			staffRoutes.POST("/checkin", checkinController.CheckIn)                              // Scan a ticket's QR code
+			staffRoutes.POST("/checkin/sync", checkinController.SyncAdmissions)                  // Upload offline admissions
+			staffRoutes.GET("/showtimes/:id/checkin-bundle", checkinController.GetOfflineBundle) // Offline verification bundle
 		}
 
 		// Admin-only routes for Master Data Management
@@ -153,6 +223,11 @@ func (s *Server) RegisterRoutes() http.Handler {
 			adminRoutes.PUT("/studios/:id", studioController.UpdateStudio)
 			adminRoutes.DELETE("/studios/:id", studioController.DeleteStudio)
 
+			// Studio seat map editor
+			adminRoutes.GET("/studios/:id/layout", studioController.GetLayout)
+			adminRoutes.PUT("/studios/:id/layout", studioController.UpdateLayout)
+			adminRoutes.PATCH("/studios/:id/layout/seats/:label", studioController.UpdateSeatState)
+
 			// Movie CRUD endpoints
 			adminRoutes.POST("/movies", movieController.CreateMovie)
 			adminRoutes.GET("/movies", movieController.GetAllMovies)
@@ -166,6 +241,76 @@ func (s *Server) RegisterRoutes() http.Handler {
 			adminRoutes.PUT("/showtimes/:id", showtimeController.UpdateShowtime)
 			adminRoutes.DELETE("/showtimes/:id", showtimeController.DeleteShowtime)
 
+			// Seat category pricing per showtime
+			adminRoutes.GET("/showtimes/:id/prices", seatCategoryController.GetShowtimePrices)
+			adminRoutes.PUT("/showtimes/:id/prices", seatCategoryController.SetShowtimePrices)
+
+			// Seat category CRUD endpoints
+			adminRoutes.POST("/seat-categories", seatCategoryController.CreateCategory)
+			adminRoutes.GET("/seat-categories", seatCategoryController.GetAllCategories)
+			adminRoutes.PUT("/seat-categories/:id", seatCategoryController.UpdateCategory)
+			adminRoutes.DELETE("/seat-categories/:id", seatCategoryController.DeleteCategory)
+
+			// Ticket type CRUD endpoints
+			adminRoutes.POST("/ticket-types", ticketTypeController.CreateTicketType)
+			adminRoutes.GET("/ticket-types", ticketTypeController.GetAllTicketTypes)
+			adminRoutes.PUT("/ticket-types/:id", ticketTypeController.UpdateTicketType)
+			adminRoutes.DELETE("/ticket-types/:id", ticketTypeController.DeleteTicketType)
+
+			// Voucher (promo code) CRUD endpoints
+			adminRoutes.POST("/vouchers", voucherController.CreateVoucher)
+			adminRoutes.GET("/vouchers", voucherController.GetAllVouchers)
+			adminRoutes.GET("/vouchers/:id", voucherController.GetVoucherByID)
+			adminRoutes.PUT("/vouchers/:id", voucherController.UpdateVoucher)
+			adminRoutes.DELETE("/vouchers/:id", voucherController.DeleteVoucher)
+
+			// Cancellation policy CRUD endpoints (attached globally or to a studio, movie or showtime)
+			adminRoutes.POST("/cancellation-policies", cancellationPolicyController.CreatePolicy)
+			adminRoutes.GET("/cancellation-policies", cancellationPolicyController.GetAllPolicies)
+			adminRoutes.GET("/cancellation-policies/:id", cancellationPolicyController.GetPolicyByID)
+			adminRoutes.PUT("/cancellation-policies/:id", cancellationPolicyController.UpdatePolicy)
+			adminRoutes.DELETE("/cancellation-policies/:id", cancellationPolicyController.DeletePolicy)
+
+			// Invoice and credit note number series per billing entity (no delete: issued numbers must stay traceable)
+			adminRoutes.GET("/number-series", numberSeriesController.GetAllSeries)
+			adminRoutes.POST("/number-series", numberSeriesController.CreateSeries)
+			adminRoutes.PUT("/number-series/:id", numberSeriesController.UpdateSeries)
+
+			// Accounting ledger (optional from/to query params)
+			adminRoutes.GET("/ledger/balances", ledgerController.GetBalances)
+			adminRoutes.GET("/ledger/trial-balance", ledgerController.GetTrialBalance)
+
+			// Revenue and occupancy reports (group_by, from, to, format=csv)
+			adminRoutes.GET("/reports/sales", reportController.GetSalesReport)
+
+			// Concessions (food & beverage catalog and stock per cinema)
+			adminRoutes.POST("/concessions/products", concessionController.CreateProduct)
+			adminRoutes.GET("/concessions/products", concessionController.GetAllProducts)
+			adminRoutes.GET("/concessions/products/:id", concessionController.GetProductByID)
+			adminRoutes.PUT("/concessions/products/:id", concessionController.UpdateProduct)
+			adminRoutes.DELETE("/concessions/products/:id", concessionController.DeleteProduct)
+			adminRoutes.PUT("/concessions/products/:id/stock", concessionController.SetStock)
+
+			// Bookings whose payment failed verification
+			adminRoutes.GET("/bookings/payment-review", bookingController.GetPaymentReviewBookings)
+			adminRoutes.POST("/bookings/:id/payment-review", bookingController.ResolvePaymentReview)
+
+			// Refunds (admins are not bound by the customer refund policy)
+			adminRoutes.POST("/bookings/:id/refund", bookingController.RefundBooking)
+			adminRoutes.GET("/bookings/:id/refunds", bookingController.GetBookingRefunds)
+			adminRoutes.GET("/bookings/:id/payments", bookingController.GetBookingPayments)
+
+			// Concession counter pickup
+			adminRoutes.POST("/bookings/:id/items/redeem", bookingController.RedeemBookingItems)
+
+			// Corrections made by the payment reconciliation job
+			adminRoutes.GET("/payment-reconciliations", bookingController.GetReconciliationReports)
+
+			// Payment webhook event log
+			adminRoutes.GET("/webhook-events", webhookController.ListWebhookEvents)
+			adminRoutes.GET("/webhook-events/:id", webhookController.GetWebhookEvent)
+			adminRoutes.POST("/webhook-events/:id/replay", webhookController.ReplayWebhookEvent)
+
 			// Example: User management (keep existing)
 			adminRoutes.GET("/users", s.getAllUsersHandler)
 			adminRoutes.DELETE("/users/:id", s.deleteUserHandler)
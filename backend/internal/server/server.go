@@ -10,6 +10,7 @@ import (
 	"gorm.io/gorm"
 
 	"absolutcinema-backend/internal/database"
+	"absolutcinema-backend/internal/services"
 	"absolutcinema-backend/internal/utils"
 )
 
@@ -17,6 +18,9 @@ type Server struct {
 	port int
 
 	db database.Service
+
+	// seatEventHub fans out live seat changes; started in RegisterRoutes
+	seatEventHub *services.SeatEventHub
 }
 
 func NewServer() *http.Server {
@@ -57,6 +61,9 @@ func NewServer() *http.Server {
 		WriteTimeout: 30 * time.Second,
 	}
 
+	// Stop long-lived seat streams when the server shuts down
+	server.RegisterOnShutdown(NewServer.seatEventHub.Stop)
+
 	return server
 }
 
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/google/uuid"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+// Booking document kinds
+const (
+	DocumentTicket  = "ticket"
+	DocumentReceipt = "receipt"
+
+	// maxCachedDocuments bounds the rendered PDFs kept in memory
+	maxCachedDocuments = 256
+
+	documentTimeFormat = "Mon, 02 Jan 2006 15:04"
+)
+
+// BookingDocument is a rendered PDF. ETag changes whenever anything printed on it does.
+type BookingDocument struct {
+	Data         []byte
+	ETag         string
+	Filename     string
+	LastModified time.Time
+}
+
+// documentCache keeps rendered PDFs by ETag, so repeated downloads skip rendering
+var documentCache = struct {
+	sync.Mutex
+	docs map[string]*BookingDocument
+}{docs: make(map[string]*BookingDocument)}
+
+// bookingDocumentData is everything printed on a booking's documents. The showtime
+// fields are repeated here because tickets leave their showtime out of JSON, and the
+// JSON is what the ETag is computed from.
+type bookingDocumentData struct {
+	Booking *models.Booking `json:"booking"`
+	Payment *models.Payment `json:"payment"`
+	Email   string          `json:"email"`
+	Movie   string          `json:"movie"`
+	Studio  string          `json:"studio"`
+	Start   time.Time       `json:"start"`
+}
+
+// GetBookingDocument returns a customer's e-ticket (paid bookings) or receipt (paid or
+// refunded bookings) as a PDF
+func (bs *BookingService) GetBookingDocument(kind string, bookingID, userID uuid.UUID) (*BookingDocument, error) {
+	booking, err := bs.GetBookingByID(bookingID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case DocumentTicket:
+		if booking.Status != BookingStatusPaid {
+			return nil, errors.New("tickets are only issued for paid bookings")
+		}
+		// Tickets paid before tokens existed are signed now
+		if err := issueTicketTokens(bs.db, booking.ID); err != nil {
+			return nil, err
+		}
+		if booking, err = bs.GetBookingByID(bookingID, userID); err != nil {
+			return nil, err
+		}
+	case DocumentReceipt:
+		switch booking.Status {
+		case BookingStatusPaid, BookingStatusPartiallyRefunded, BookingStatusRefunded:
+		default:
+			return nil, errors.New("receipts are only issued for paid bookings")
+		}
+	default:
+		return nil, fmt.Errorf("unknown document %s", kind)
+	}
+
+	data := bookingDocumentData{Booking: booking}
+	if len(booking.Tickets) > 0 {
+		showtime := booking.Tickets[0].Showtime
+		data.Movie, data.Studio, data.Start = showtime.Movie.Title, showtime.Studio.Name, showtime.StartTime
+	}
+	var payment models.Payment
+	err = bs.db.Where("booking_id = ? AND status = ?", booking.ID, models.PaymentStatusPaid).
+		Order("paid_at DESC").First(&payment).Error
+	if err == nil {
+		data.Payment = &payment
+	}
+	var user models.User
+	if err := bs.db.Select("email").First(&user, "id = ?", booking.UserID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	data.Email = user.Email
+
+	fingerprint, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fingerprint %s: %w", kind, err)
+	}
+	sum := sha256.Sum256(append([]byte(kind+":"), fingerprint...))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	documentCache.Lock()
+	cached := documentCache.docs[etag]
+	documentCache.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	var pdf []byte
+	if kind == DocumentTicket {
+		pdf, err = renderTicketPDF(&data)
+	} else {
+		pdf, err = renderReceiptPDF(&data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &BookingDocument{
+		Data:         pdf,
+		ETag:         etag,
+		Filename:     fmt.Sprintf("%s-%s.pdf", kind, booking.InvoiceNumber),
+		LastModified: documentModified(&data),
+	}
+
+	documentCache.Lock()
+	if len(documentCache.docs) >= maxCachedDocuments {
+		// Evicting an arbitrary entry is enough; any miss is just a re-render
+		for key := range documentCache.docs {
+			delete(documentCache.docs, key)
+			break
+		}
+	}
+	documentCache.docs[etag] = doc
+	documentCache.Unlock()
+
+	return doc, nil
+}
+
+// documentModified is the latest change printed on a document
+func documentModified(data *bookingDocumentData) time.Time {
+	modified := data.Booking.CreatedAt
+	if data.Payment != nil && data.Payment.PaidAt != nil && data.Payment.PaidAt.After(modified) {
+		modified = *data.Payment.PaidAt
+	}
+	for _, r := range data.Booking.Refunds {
+		if r.UpdatedAt.After(modified) {
+			modified = r.UpdatedAt
+		}
+	}
+	for _, t := range data.Booking.Tickets {
+		if t.CheckedInAt != nil && t.CheckedInAt.After(modified) {
+			modified = *t.CheckedInAt
+		}
+	}
+	return modified.UTC().Truncate(time.Second)
+}
+
+// newDocumentPDF starts an A4 document with the cinema's header on every page
+func newDocumentPDF(title string, created time.Time) (*fpdf.Fpdf, func(string) string) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetAuthor("AbsolutCinema", true)
+	pdf.SetCreationDate(created)
+	pdf.SetModificationDate(created)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+
+	// Core fonts are cp1252; translate so titles with accents still print
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont("Helvetica", "B", 16)
+		pdf.CellFormat(120, 10, "AbsolutCinema", "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(0, 10, tr(title), "", 1, "R", false, 0, "")
+		pdf.SetDrawColor(180, 180, 180)
+		pdf.Line(15, pdf.GetY(), 195, pdf.GetY())
+		pdf.Ln(6)
+	})
+	return pdf, tr
+}
+
+// renderTicketPDF prints one page per ticket, each with its QR code, and the
+// concession items to collect on the first page
+func renderTicketPDF(data *bookingDocumentData) ([]byte, error) {
+	booking := data.Booking
+	pdf, tr := newDocumentPDF("E-Ticket", booking.CreatedAt)
+
+	for i, ticket := range booking.Tickets {
+		pdf.AddPage()
+		showtime := ticket.Showtime
+
+		pdf.SetFont("Helvetica", "B", 20)
+		pdf.MultiCell(110, 9, tr(showtime.Movie.Title), "", "L", false)
+		pdf.Ln(2)
+
+		top := pdf.GetY()
+		rows := [][2]string{
+			{"Studio", showtime.Studio.Name},
+			{"Showtime", showtime.StartTime.Local().Format(documentTimeFormat)},
+			{"Seat", ticket.SeatNumber},
+		}
+		if ticket.SeatCategory != "" {
+			rows = append(rows, [2]string{"Seat category", ticket.SeatCategory})
+		}
+		if ticket.TicketTypeName != "" {
+			rows = append(rows, [2]string{"Ticket type", ticket.TicketTypeName})
+		}
+		rows = append(rows,
+			[2]string{"Invoice", booking.InvoiceNumber},
+			[2]string{"Booking", booking.ID.String()},
+			[2]string{"Ticket", fmt.Sprintf("%d of %d", i+1, len(booking.Tickets))},
+		)
+		writeDocumentRows(pdf, tr, rows, 30, 80)
+
+		// QR code to the right of the details
+		if ticket.QRToken != "" {
+			png, err := qrcode.Encode(ticket.QRToken, qrcode.Medium, 512)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode QR code: %w", err)
+			}
+			name := fmt.Sprintf("qr-%d", ticket.ID)
+			opts := fpdf.ImageOptions{ImageType: "PNG"}
+			pdf.RegisterImageOptionsReader(name, opts, bytes.NewReader(png))
+			pdf.ImageOptions(name, 130, top, 65, 65, false, opts, 0, "")
+			pdf.SetXY(130, top+66)
+			pdf.SetFont("Helvetica", "", 8)
+			pdf.CellFormat(65, 4, "Show this code at the door", "", 1, "C", false, 0, "")
+		}
+
+		if i == 0 && len(booking.Items) > 0 {
+			pdf.SetY(top + 80)
+			pdf.SetFont("Helvetica", "B", 12)
+			pdf.CellFormat(0, 8, "Food & beverage to collect at the counter", "", 1, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 10)
+			for _, item := range booking.Items {
+				pdf.CellFormat(0, 6, tr(fmt.Sprintf("%dx %s", item.Quantity, item.ProductName)), "", 1, "L", false, 0, "")
+			}
+		}
+	}
+
+	if pdf.Err() {
+		return nil, fmt.Errorf("failed to render ticket: %w", pdf.Error())
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render ticket: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderReceiptPDF prints the receipt: who sold what, what was paid and how, and any refunds
+func renderReceiptPDF(data *bookingDocumentData) ([]byte, error) {
+	booking := data.Booking
+	pdf, tr := newDocumentPDF("Receipt", booking.CreatedAt)
+	pdf.AddPage()
+
+	rows := [][2]string{
+		{"Invoice number", booking.InvoiceNumber},
+		{"Issued by", booking.BillingEntity},
+		{"Booked on", booking.CreatedAt.Local().Format(documentTimeFormat)},
+		{"Customer", data.Email},
+		{"Booking", booking.ID.String()},
+		{"Status", booking.Status},
+	}
+	if len(booking.Tickets) > 0 {
+		showtime := booking.Tickets[0].Showtime
+		rows = append(rows,
+			[2]string{"Movie", showtime.Movie.Title},
+			[2]string{"Showtime", showtime.StartTime.Local().Format(documentTimeFormat) + ", " + showtime.Studio.Name},
+		)
+	}
+	writeDocumentRows(pdf, tr, rows, 40, 140)
+	pdf.Ln(6)
+
+	// Line items
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.CellFormat(100, 7, "Item", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(15, 7, "Qty", "B", 0, "R", true, 0, "")
+	pdf.CellFormat(32, 7, "Unit price", "B", 0, "R", true, 0, "")
+	pdf.CellFormat(33, 7, "Amount", "B", 1, "R", true, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+
+	line := func(name string, qty int, unit, amount money.Money) {
+		pdf.CellFormat(100, 7, tr(name), "", 0, "L", false, 0, "")
+		pdf.CellFormat(15, 7, fmt.Sprintf("%d", qty), "", 0, "R", false, 0, "")
+		pdf.CellFormat(32, 7, unit.Decimal(), "", 0, "R", false, 0, "")
+		pdf.CellFormat(33, 7, amount.Decimal(), "", 1, "R", false, 0, "")
+	}
+
+	ticketsTotal, err := ticketsGross(booking)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(booking.Tickets) > 0 {
+		for _, ticket := range booking.Tickets {
+			line(invoiceItemName(ticket), 1, ticket.Price, ticket.Price)
+		}
+	} else if ticketsTotal.IsPositive() {
+		// Refunded bookings have released their seats
+		line("Tickets (seats released)", 1, ticketsTotal, ticketsTotal)
+	}
+	for _, item := range booking.Items {
+		line(item.ProductName, item.Quantity, item.UnitPrice, item.TotalPrice)
+	}
+	if booking.DiscountAmount.IsPositive() {
+		line(invoiceDiscountName(booking), 1, booking.DiscountAmount.Neg(), booking.DiscountAmount.Neg())
+	}
+
+	pdf.SetDrawColor(180, 180, 180)
+	pdf.Line(15, pdf.GetY()+1, 195, pdf.GetY()+1)
+	pdf.Ln(3)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(147, 8, "Total", "", 0, "R", false, 0, "")
+	pdf.CellFormat(33, 8, booking.TotalAmount.String(), "", 1, "R", false, 0, "")
+	pdf.Ln(4)
+
+	// Payment, from the paid invoice's callback data
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, "Payment", "", 1, "L", false, 0, "")
+	if data.Payment != nil {
+		payment := [][2]string{
+			{"Invoice", data.Payment.ProviderInvoiceID},
+			{"Amount paid", data.Payment.PaidAmount.String()},
+		}
+		if data.Payment.PaymentMethod != "" {
+			payment = append(payment, [2]string{"Method", data.Payment.PaymentMethod})
+		}
+		if data.Payment.PaymentChannel != "" {
+			payment = append(payment, [2]string{"Channel", data.Payment.PaymentChannel})
+		}
+		if data.Payment.PaidAt != nil {
+			payment = append(payment, [2]string{"Paid on", data.Payment.PaidAt.Local().Format(documentTimeFormat)})
+		}
+		writeDocumentRows(pdf, tr, payment, 40, 140)
+	} else {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 7, "No payment was needed for this booking.", "", 1, "L", false, 0, "")
+	}
+
+	// Refunds, each with its credit note
+	var refunds [][2]string
+	for _, r := range booking.Refunds {
+		if r.CreditNoteNumber == nil || r.Status == RefundStatusFailed {
+			continue
+		}
+		refunds = append(refunds, [2]string{*r.CreditNoteNumber, fmt.Sprintf("%s (%s)", r.Amount.Neg().String(), r.Status)})
+	}
+	if len(refunds) > 0 {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 8, "Refunds", "", 1, "L", false, 0, "")
+		writeDocumentRows(pdf, tr, refunds, 60, 120)
+	}
+
+	if pdf.Err() {
+		return nil, fmt.Errorf("failed to render receipt: %w", pdf.Error())
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render receipt: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeDocumentRows prints label/value pairs as two columns
+func writeDocumentRows(pdf *fpdf.Fpdf, tr func(string) string, rows [][2]string, labelWidth, valueWidth float64) {
+	for _, row := range rows {
+		pdf.SetX(15)
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(labelWidth, 6, tr(row[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(valueWidth, 6, tr(row[1]), "", 1, "L", false, 0, "")
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+func TestRenderBookingDocuments(t *testing.T) {
+	start := time.Date(2026, 3, 7, 19, 30, 0, 0, time.UTC)
+	showtime := models.Showtime{
+		StartTime: start,
+		Movie:     models.Movie{Title: "Amélie"},
+		Studio:    models.Studio{Name: "Studio 1"},
+	}
+	paidAt := start.Add(-24 * time.Hour)
+	data := bookingDocumentData{
+		Booking: &models.Booking{
+			ID:             uuid.New(),
+			InvoiceNumber:  "INV/2026/000042",
+			BillingEntity:  models.DefaultBillingEntity,
+			TotalAmount:    money.FromMajor(85000),
+			DiscountAmount: money.FromMajor(10000),
+			Status:         BookingStatusPaid,
+			Tickets: []models.Ticket{
+				{ID: 1, SeatNumber: "A5", Price: money.FromMajor(50000), QRToken: "AC1.1.1.x.sig", Showtime: showtime},
+			},
+			Items: []models.BookingItem{
+				{ProductName: "Popcorn", Quantity: 1, UnitPrice: money.FromMajor(45000), TotalPrice: money.FromMajor(45000)},
+			},
+		},
+		Payment: &models.Payment{ProviderInvoiceID: "inv_1", PaidAmount: money.FromMajor(85000), PaymentMethod: "EWALLET", PaidAt: &paidAt},
+		Email:   "customer@example.com",
+	}
+
+	ticket, err := renderTicketPDF(&data)
+	if err != nil {
+		t.Fatalf("ticket: %v", err)
+	}
+	receipt, err := renderReceiptPDF(&data)
+	if err != nil {
+		t.Fatalf("receipt: %v", err)
+	}
+	for name, pdf := range map[string][]byte{"ticket": ticket, "receipt": receipt} {
+		if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+			t.Errorf("%s is not a PDF", name)
+		}
+	}
+}
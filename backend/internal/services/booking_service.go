@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -10,8 +12,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
 )
 
 const (
@@ -23,18 +27,43 @@ const (
 	BookingStatusPaid      = "PAID"
 	BookingStatusCancelled = "CANCELLED"
 	BookingStatusExpired   = "EXPIRED"
+
+	// BookingStatusPaymentReview marks a booking whose payment callback failed
+	// verification (wrong amount, currency or invoice); an admin must resolve it
+	BookingStatusPaymentReview = "PAYMENT_REVIEW"
+
+	// Refunded bookings have released their seats; PARTIALLY_REFUNDED kept part of the payment
+	BookingStatusRefunded          = "REFUNDED"
+	BookingStatusPartiallyRefunded = "PARTIALLY_REFUNDED"
+
+	// BookingCurrency is the currency every invoice is issued in
+	BookingCurrency = string(money.DefaultCurrency)
 )
 
 // BookingService handles booking operations
 type BookingService struct {
-	db             *gorm.DB
-	paymentService *PaymentService
+	db              *gorm.DB
+	paymentProvider PaymentProvider
 }
 
 // CreateBookingRequest represents the request to create a booking
 type CreateBookingRequest struct {
 	ShowtimeID  FlexibleUint `json:"showtime_id" binding:"required"`
-	SeatNumbers []string     `json:"seat_numbers" binding:"required,min=1"`
+	SeatNumbers []string     `json:"seat_numbers" binding:"required_without=HoldID"`
+
+	// HoldID converts an existing seat hold into this booking.
+	// When SeatNumbers is empty the held seats are booked.
+	HoldID *uuid.UUID `json:"hold_id,omitempty"`
+
+	// TicketTypes maps a seat number to a ticket type code (e.g. {"A5": "CHILD"}).
+	// Seats not listed are booked as ADULT.
+	TicketTypes map[string]string `json:"ticket_types,omitempty"`
+
+	// PromoCode applies a voucher discount to the booking's tickets
+	PromoCode string `json:"promo_code,omitempty"`
+
+	// Products adds food and beverage items from the showtime's cinema
+	Products []ProductLine `json:"products,omitempty"`
 }
 
 // FlexibleUint is a uint that can be unmarshaled from both string and number JSON values
@@ -61,11 +90,12 @@ type BookingResult struct {
 	Message    string          `json:"message"`
 }
 
-// NewBookingService creates a new booking service
-func NewBookingService(db *gorm.DB, paymentService *PaymentService) *BookingService {
+// NewBookingService creates a new booking service.
+// paymentProvider may be nil, in which case bookings are created without payment links.
+func NewBookingService(db *gorm.DB, paymentProvider PaymentProvider) *BookingService {
 	return &BookingService{
-		db:             db,
-		paymentService: paymentService,
+		db:              db,
+		paymentProvider: paymentProvider,
 	}
 }
 
@@ -74,7 +104,7 @@ func (bs *BookingService) CreateBooking(userID uuid.UUID, req *CreateBookingRequ
 	// 1. Validate showtime exists and get price
 	var showtime models.Showtime
 	showtimeID := uint(req.ShowtimeID)
-	if err := bs.db.Preload("Studio").First(&showtime, showtimeID).Error; err != nil {
+	if err := bs.db.Preload("Studio").Preload("Movie").First(&showtime, showtimeID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("showtime not found")
 		}
@@ -86,19 +116,65 @@ func (bs *BookingService) CreateBooking(userID uuid.UUID, req *CreateBookingRequ
 		return nil, errors.New("cannot book seats for a showtime that has already started")
 	}
 
-	// 2. Validate seat numbers against studio dimensions
-	if err := bs.validateSeatNumbers(req.SeatNumbers, showtime.Studio.TotalRows, showtime.Studio.TotalCols); err != nil {
+	// The booking must be paid before the payment cutoff, so there must still be time to pay
+	paymentExpiresAt, err := paymentDeadline(showtime.StartTime, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve seats from the hold being converted, if any
+	if req.HoldID != nil {
+		hold, err := findActiveHold(bs.db, *req.HoldID)
+		if err != nil {
+			return nil, err
+		}
+		if hold[0].ShowtimeID != showtimeID {
+			return nil, errors.New("hold does not belong to this showtime")
+		}
+		if hold[0].UserID != nil && *hold[0].UserID != userID {
+			return nil, errors.New("hold not found")
+		}
+		if len(req.SeatNumbers) == 0 {
+			req.SeatNumbers = toHoldResult(hold).SeatNumbers
+		}
+	}
+
+	// 2. Validate seat numbers against the studio's seat map
+	if err := validateSeatsAgainstLayout(bs.db, showtime.StudioID, req.SeatNumbers); err != nil {
 		return nil, err
 	}
 
 	// 3. Remove duplicates from seat numbers
 	uniqueSeats := removeDuplicateSeats(req.SeatNumbers)
 
-	// 4. Calculate total amount
-	totalAmount := showtime.Price * float64(len(uniqueSeats))
+	// 4. Calculate total amount from each seat's category price and ticket type
+	seatPrices, err := resolveSeatPrices(bs.db, &showtime, uniqueSeats)
+	if err != nil {
+		return nil, err
+	}
 
-	// 5. Generate invoice number
-	invoiceNumber := generateInvoiceNumber()
+	ticketTypes, err := resolveTicketTypes(bs.db, uniqueSeats, req.TicketTypes, &showtime.Movie)
+	if err != nil {
+		return nil, err
+	}
+
+	unitPrices := make(map[string]money.Money, len(uniqueSeats))
+	var totalAmount money.Money
+	for _, seatNumber := range uniqueSeats {
+		unitPrices[seatNumber] = applyPriceModifier(seatPrices[seatNumber].Price, ticketTypes[seatNumber])
+		if totalAmount, err = totalAmount.Add(unitPrices[seatNumber]); err != nil {
+			return nil, err
+		}
+	}
+
+	// 5. Resolve the billing entity; its invoice number is taken inside the transaction so numbers have no gaps
+	entity := billingEntity(&showtime.Studio)
+
+	// Concession items are priced from the catalog; their stock is taken inside the transaction
+	items, itemsTotal, err := prepareBookingItems(bs.db, req.Products, entity)
+	if err != nil {
+		return nil, err
+	}
 
 	// 6. Get user email for payment invoice
 	var user models.User
@@ -108,25 +184,111 @@ func (bs *BookingService) CreateBooking(userID uuid.UUID, req *CreateBookingRequ
 
 	// 7. Create booking in a transaction
 	var booking models.Booking
-	err := bs.db.Transaction(func(tx *gorm.DB) error {
+	err = bs.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSeats(tx, showtimeID, uniqueSeats); err != nil {
+			return err
+		}
+
+		// Consume the customer's own holds on these seats so they don't block the tickets below
+		consume := tx.Where("showtime_id = ? AND seat_number IN ?", showtimeID, uniqueSeats)
+		if req.HoldID != nil {
+			consume = consume.Where("user_id = ? OR (hold_id = ? AND user_id IS NULL)", userID, *req.HoldID)
+		} else {
+			consume = consume.Where("user_id = ?", userID)
+		}
+		if err := consume.Delete(&models.SeatHold{}).Error; err != nil {
+			return fmt.Errorf("failed to consume seat holds: %w", err)
+		}
+
 		// Create booking record
+		grandTotal, err := totalAmount.Add(itemsTotal)
+		if err != nil {
+			return err
+		}
 		booking = models.Booking{
-			UserID:        userID,
-			InvoiceNumber: invoiceNumber,
-			TotalAmount:   totalAmount,
-			Status:        BookingStatusPending,
+			UserID:           userID,
+			BillingEntity:    entity,
+			TotalAmount:      grandTotal,
+			Status:           BookingStatusPending,
+			PaymentExpiresAt: &paymentExpiresAt,
+		}
+
+		// Apply the promo code under the voucher's row lock
+		var voucher *models.Voucher
+		if strings.TrimSpace(req.PromoCode) != "" {
+			var discount money.Money
+			var err error
+			voucher, discount, err = applyVoucher(tx, req.PromoCode, userID, &showtime, len(uniqueSeats), totalAmount)
+			if err != nil {
+				return err
+			}
+			booking.PromoCode = voucher.Code
+			booking.DiscountAmount = discount
+			booking.TotalAmount, err = money.Sum(totalAmount, discount.Neg(), itemsTotal)
+			if err != nil {
+				return err
+			}
+
+			// Nothing left to pay, so there is no invoice to wait for
+			if !booking.TotalAmount.IsPositive() {
+				booking.TotalAmount = money.Money{}
+				booking.Status = BookingStatusPaid
+				booking.PaymentExpiresAt = nil
+			}
+		}
+
+		// Taken just before the booking is written; the sequence row stays locked until commit
+		invoiceNumber, err := nextDocumentNumber(tx, entity, models.DocumentTypeInvoice, time.Now())
+		if err != nil {
+			return err
 		}
+		booking.InvoiceNumber = invoiceNumber
 
 		if err := tx.Create(&booking).Error; err != nil {
 			return fmt.Errorf("failed to create booking: %w", err)
 		}
 
+		if len(items) > 0 {
+			if err := reserveConcessionStock(tx, items, entity); err != nil {
+				return err
+			}
+			for i := range items {
+				items[i].BookingID = booking.ID
+			}
+			if err := tx.Omit("Booking").Create(&items).Error; err != nil {
+				return fmt.Errorf("failed to create booking items: %w", err)
+			}
+		}
+
+		if err := postBookingCreated(tx, &booking, studioRevenueAccount(showtime.StudioID), itemsTotal); err != nil {
+			return err
+		}
+
+		if voucher != nil {
+			redemption := models.VoucherRedemption{
+				VoucherID:      voucher.ID,
+				UserID:         userID,
+				BookingID:      booking.ID,
+				DiscountAmount: booking.DiscountAmount,
+				Status:         models.RedemptionStatusActive,
+			}
+			if err := tx.Omit("Voucher", "Booking").Create(&redemption).Error; err != nil {
+				return fmt.Errorf("failed to record promo code use: %w", err)
+			}
+		}
+
 		// Create ticket records for each seat
 		for _, seatNumber := range uniqueSeats {
 			ticket := models.Ticket{
-				BookingID:  booking.ID,
-				ShowtimeID: showtimeID,
-				SeatNumber: strings.ToUpper(seatNumber), // Normalize to uppercase
+				BookingID:    booking.ID,
+				ShowtimeID:   showtimeID,
+				SeatNumber:   strings.ToUpper(seatNumber), // Normalize to uppercase
+				Price:        unitPrices[seatNumber],
+				SeatCategory: seatPrices[seatNumber].Category,
+			}
+			if tt := ticketTypes[seatNumber]; tt != nil {
+				ticket.TicketType = tt.Code
+				ticket.TicketTypeName = tt.Name
 			}
 
 			if err := tx.Create(&ticket).Error; err != nil {
@@ -145,7 +307,14 @@ func (bs *BookingService) CreateBooking(userID uuid.UUID, req *CreateBookingRequ
 			}
 		}
 
-		return nil
+		// Fully discounted bookings are paid already, so their tickets are issued now
+		if booking.Status == BookingStatusPaid {
+			if err := confirmPaidBooking(tx, booking.ID); err != nil {
+				return err
+			}
+		}
+
+		return publishSeatEvent(tx, SeatEventTaken, SeatReasonBooked, showtimeID, uniqueSeats)
 	})
 
 	if err != nil {
@@ -157,39 +326,32 @@ func (bs *BookingService) CreateBooking(userID uuid.UUID, req *CreateBookingRequ
 		return nil, err
 	}
 
-	// 8. Load the complete booking with tickets
-	if err := bs.db.Preload("Tickets").First(&booking, "id = ?", booking.ID).Error; err != nil {
+	// 8. Load the complete booking with tickets and items
+	if err := bs.db.Preload("Tickets").Preload("Items").First(&booking, "id = ?", booking.ID).Error; err != nil {
 		return nil, fmt.Errorf("failed to load booking: %w", err)
 	}
 
-	// 9. Create payment invoice via Xendit
+	// 9. Create payment invoice via the payment provider
 	result := &BookingResult{
 		Booking: &booking,
 		Message: "Booking created successfully",
 	}
 
-	if bs.paymentService != nil {
-		invoiceResult, err := bs.paymentService.CreateInvoice(&booking, user.Email)
-		if err != nil {
+	if booking.Status == BookingStatusPaid {
+		result.Message = "Booking created and fully covered by promo code"
+		return result, nil
+	}
+
+	if bs.paymentProvider != nil {
+		if _, err := bs.openPaymentAttempt(&booking, user.Email); err != nil {
 			// Payment creation failed, but booking is created with PENDING status
 			// User can retry payment later
+			log.Printf("[BookingService] Failed to open payment for booking %s: %v", booking.ID, err)
 			result.Message = "Booking created but payment link generation failed. Please try again later."
 			return result, nil
 		}
 
-		// Update booking with payment URL and payment ID
-		updateResult := bs.db.Model(&models.Booking{}).Where("id = ?", booking.ID).Updates(map[string]interface{}{
-			"payment_url": invoiceResult.InvoiceURL,
-			"payment_id":  invoiceResult.InvoiceID,
-		})
-		if updateResult.Error != nil {
-			result.Message = fmt.Sprintf("Booking created but failed to save payment URL: %v", updateResult.Error)
-			result.PaymentURL = invoiceResult.InvoiceURL
-			return result, nil
-		}
-
-		result.PaymentURL = invoiceResult.InvoiceURL
-		result.Booking.PaymentURL = invoiceResult.InvoiceURL
+		result.PaymentURL = booking.PaymentURL
 	}
 
 	return result, nil
@@ -203,6 +365,7 @@ func (bs *BookingService) GetUserBookings(userID uuid.UUID) ([]models.Booking, e
 		Preload("Tickets.Showtime").
 		Preload("Tickets.Showtime.Movie").
 		Preload("Tickets.Showtime.Studio").
+		Preload("Items").
 		Where("user_id = ?", userID).
 		Order("created_at DESC").
 		Find(&bookings).Error
@@ -222,6 +385,8 @@ func (bs *BookingService) GetBookingByID(bookingID uuid.UUID, userID uuid.UUID)
 		Preload("Tickets.Showtime").
 		Preload("Tickets.Showtime.Movie").
 		Preload("Tickets.Showtime.Studio").
+		Preload("Items").
+		Preload("Refunds").
 		Where("id = ? AND user_id = ?", bookingID, userID).
 		First(&booking).Error
 
@@ -235,23 +400,34 @@ func (bs *BookingService) GetBookingByID(bookingID uuid.UUID, userID uuid.UUID)
 	return &booking, nil
 }
 
-// GetOccupiedSeats retrieves all occupied seats for a showtime
+// GetOccupiedSeats retrieves all occupied seats for a showtime,
+// including seats under an active hold
 func (bs *BookingService) GetOccupiedSeats(showtimeID uint) ([]string, error) {
 	var tickets []models.Ticket
 	err := bs.db.
 		Joins("JOIN bookings ON bookings.id = tickets.booking_id").
 		Where("tickets.showtime_id = ?", showtimeID).
-		Where("bookings.status != ?", BookingStatusCancelled).
+		Where("bookings.status != ? OR tickets.checked_in_at IS NOT NULL", BookingStatusCancelled).
 		Find(&tickets).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch occupied seats: %w", err)
 	}
 
-	seats := make([]string, len(tickets))
-	for i, ticket := range tickets {
-		seats[i] = ticket.SeatNumber
+	var heldSeats []string
+	err = bs.db.Model(&models.SeatHold{}).
+		Where("showtime_id = ? AND expires_at > ?", showtimeID, time.Now()).
+		Pluck("seat_number", &heldSeats).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch held seats: %w", err)
+	}
+
+	seats := make([]string, 0, len(tickets)+len(heldSeats))
+	for _, ticket := range tickets {
+		seats = append(seats, ticket.SeatNumber)
 	}
+	seats = append(seats, heldSeats...)
 
 	return seats, nil
 }
@@ -273,8 +449,20 @@ func (bs *BookingService) UpdateBookingStatus(bookingID uuid.UUID, status string
 	return nil
 }
 
-// CancelBooking cancels a booking and releases the seats
+// CancelBooking cancels a booking and releases the seats.
+// Paid bookings are refunded as their cancellation policy allows (see RefundBooking).
 func (bs *BookingService) CancelBooking(bookingID uuid.UUID, userID uuid.UUID) error {
+	var status string
+	if err := bs.db.Model(&models.Booking{}).
+		Where("id = ? AND user_id = ?", bookingID, userID).
+		Pluck("status", &status).Error; err != nil {
+		return fmt.Errorf("failed to fetch booking: %w", err)
+	}
+	if status == BookingStatusPaid {
+		_, err := bs.RefundBooking(bookingID, userID, false, &RefundRequest{})
+		return err
+	}
+
 	return bs.db.Transaction(func(tx *gorm.DB) error {
 		// Find the booking
 		var booking models.Booking
@@ -291,14 +479,44 @@ func (bs *BookingService) CancelBooking(bookingID uuid.UUID, userID uuid.UUID) e
 			return errors.New("booking is already cancelled")
 		}
 
-		// Check if already paid
+		// Check if already expired (seats were released by the reaper)
+		if booking.Status == BookingStatusExpired {
+			return errors.New("booking has already expired")
+		}
+
+		// Paid in the meantime; the caller can retry and go through the refund flow
 		if booking.Status == BookingStatusPaid {
 			return errors.New("cannot cancel a paid booking")
 		}
 
+		if booking.Status == BookingStatusRefunded || booking.Status == BookingStatusPartiallyRefunded {
+			return errors.New("booking has already been refunded")
+		}
+
+		// A payment was received but needs review; only an admin can resolve it
+		if booking.Status == BookingStatusPaymentReview {
+			return errors.New("booking is under payment review")
+		}
+
+		// Reverse the sale (before the tickets go, older bookings find their studio through them)
+		if err := postBookingReleased(tx, &booking, models.LedgerEventBookingCancelled); err != nil {
+			return err
+		}
+		if err := enqueueBookingEmail(tx, models.EmailBookingCancelled, bookingID, nil); err != nil {
+			return err
+		}
+
 		// Delete associated tickets to release seats
-		if err := tx.Where("booking_id = ?", bookingID).Delete(&models.Ticket{}).Error; err != nil {
-			return fmt.Errorf("failed to delete tickets: %w", err)
+		if err := releaseTickets(tx, bookingID, SeatReasonCancelled); err != nil {
+			return err
+		}
+
+		// Give the promo code use back
+		if err := releaseVoucherRedemption(tx, bookingID); err != nil {
+			return err
+		}
+		if err := releaseBookingItems(tx, bookingID); err != nil {
+			return err
 		}
 
 		// Update booking status
@@ -310,6 +528,117 @@ func (bs *BookingService) CancelBooking(bookingID uuid.UUID, userID uuid.UUID) e
 	})
 }
 
+// ExpireStalePendingBookings expires PENDING bookings whose payment deadline has passed,
+// and those without a deadline created more than maxAge ago. For each booking the payment
+// invoice is expired first so it can no longer be paid, then the booking moves to EXPIRED
+// and its tickets are deleted to release the seats.
+// Returns the number of bookings that were expired.
+func (bs *BookingService) ExpireStalePendingBookings(ctx context.Context, maxAge time.Duration) (int, error) {
+	var bookings []models.Booking
+	err := staleBookingCandidates(bs.db.WithContext(ctx), time.Now(), maxAge).Find(&bookings).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch stale bookings: %w", err)
+	}
+
+	var attempted []uuid.UUID
+	defer func() {
+		// Attempted bookings go to the back of the queue, so ones that keep failing
+		// (e.g. the gateway can't be asked about their invoice) don't block newer ones
+		if len(attempted) == 0 {
+			return
+		}
+		if err := bs.db.Model(&models.Booking{}).Where("id IN ?", attempted).
+			Update("last_expiry_attempt_at", time.Now()).Error; err != nil {
+			log.Printf("[BookingService] Failed to record expiry attempts: %v", err)
+		}
+	}()
+
+	expired := 0
+	for i := range bookings {
+		if ctx.Err() != nil {
+			break
+		}
+		attempted = append(attempted, bookings[i].ID)
+
+		ok, err := bs.expireBooking(&bookings[i])
+		if err != nil {
+			log.Printf("[BookingService] Failed to expire booking %s: %v", bookings[i].ID, err)
+			continue
+		}
+		if ok {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
+
+// expireBatchSize bounds the bookings one reaper pass tries to expire; a var so tests can shrink it
+var expireBatchSize = 100
+
+// staleBookingCandidates selects the PENDING bookings one reaper pass tries to expire.
+// The least recently attempted come first, then the oldest.
+func staleBookingCandidates(db *gorm.DB, now time.Time, maxAge time.Duration) *gorm.DB {
+	return db.Model(&models.Booking{}).
+		Where("status = ?", BookingStatusPending).
+		Where("payment_expires_at < ? OR (payment_expires_at IS NULL AND created_at < ?)", now, now.Add(-maxAge)).
+		Order("last_expiry_attempt_at ASC NULLS FIRST").
+		Order("created_at ASC").
+		Limit(expireBatchSize)
+}
+
+// expireBooking expires a single PENDING booking and releases its seats.
+// Returns false if the booking left PENDING in the meantime (e.g. it was paid).
+func (bs *BookingService) expireBooking(booking *models.Booking) (bool, error) {
+	// Expire the open invoices first so the customer can't pay for seats we are about to release.
+	// A failure usually means the provider already expired them, but the invoice may also have
+	// been paid with the webhook lost; in that case apply the payment instead of releasing the seats.
+	if bs.paymentProvider != nil && !bs.expireOpenPayments(booking.ID) {
+		paid, err := bs.checkInvoiceBeforeExpiry(booking)
+		if paid || err != nil {
+			return false, err
+		}
+	}
+
+	expired := false
+	err := bs.db.Transaction(func(tx *gorm.DB) error {
+		// Only move bookings that are still PENDING (guards against a concurrent PAID callback)
+		result := tx.Model(&models.Booking{}).
+			Where("id = ? AND status = ?", booking.ID, BookingStatusPending).
+			Update("status", BookingStatusExpired)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update booking status to EXPIRED: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := postBookingReleased(tx, booking, models.LedgerEventBookingExpired); err != nil {
+			return err
+		}
+		if err := enqueueBookingEmail(tx, models.EmailBookingExpired, booking.ID, nil); err != nil {
+			return err
+		}
+
+		// Delete tickets to release seats
+		if err := releaseTickets(tx, booking.ID, SeatReasonExpired); err != nil {
+			return err
+		}
+
+		if err := releaseVoucherRedemption(tx, booking.ID); err != nil {
+			return err
+		}
+		if err := releaseBookingItems(tx, booking.ID); err != nil {
+			return err
+		}
+
+		expired = true
+		return nil
+	})
+
+	return expired, err
+}
+
 // RetryPayment retries payment for a pending booking
 func (bs *BookingService) RetryPayment(bookingID uuid.UUID, userID uuid.UUID) (*BookingResult, error) {
 	// Get booking
@@ -329,102 +658,58 @@ func (bs *BookingService) RetryPayment(bookingID uuid.UUID, userID uuid.UUID) (*
 		return nil, fmt.Errorf("failed to fetch user: %w", err)
 	}
 
-	// Check if there's already a valid payment URL
-	if booking.PaymentURL != "" {
-		// Try to get invoice status
-		if bs.paymentService != nil {
-			invoiceResult, err := bs.paymentService.GetInvoiceByExternalID(bookingID)
-			if err == nil && invoiceResult.Status == "PENDING" {
-				// Invoice still valid
-				return &BookingResult{
-					Booking:    booking,
-					PaymentURL: booking.PaymentURL,
-					Message:    "Existing payment link is still valid",
-				}, nil
-			}
+	// Check if the latest payment attempt can still be paid
+	if booking.PaymentID != "" && bs.paymentProvider != nil {
+		invoiceResult, err := bs.paymentProvider.GetInvoice(booking.PaymentID)
+		if err == nil && invoiceResult.Status == models.PaymentStatusPending {
+			// Invoice still valid
+			return &BookingResult{
+				Booking:    booking,
+				PaymentURL: booking.PaymentURL,
+				Message:    "Existing payment link is still valid",
+			}, nil
 		}
 	}
 
-	// Create new invoice
-	if bs.paymentService == nil {
-		return nil, errors.New("payment service is not available")
-	}
-
-	invoiceResult, err := bs.paymentService.CreateInvoice(booking, user.Email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create payment invoice: %w", err)
-	}
-
-	// Update booking with new payment URL
-	if err := bs.db.Model(booking).Update("payment_url", invoiceResult.InvoiceURL).Error; err != nil {
-		return nil, fmt.Errorf("failed to save payment URL: %w", err)
+	// Open a new attempt; older invoices are expired so only the new one can be paid
+	if _, err := bs.openPaymentAttempt(booking, user.Email); err != nil {
+		return nil, err
 	}
 
-	booking.PaymentURL = invoiceResult.InvoiceURL
-
 	return &BookingResult{
 		Booking:    booking,
-		PaymentURL: invoiceResult.InvoiceURL,
+		PaymentURL: booking.PaymentURL,
 		Message:    "New payment link generated successfully",
 	}, nil
 }
 
-// validateSeatNumbers validates that all seat numbers are within studio dimensions
-func (bs *BookingService) validateSeatNumbers(seatNumbers []string, totalRows, totalCols int) error {
-	for _, seat := range seatNumbers {
-		if err := validateSeatNumber(seat, totalRows, totalCols); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// validateSeatNumber validates a single seat number against studio dimensions
-func validateSeatNumber(seatNumber string, totalRows, totalCols int) error {
-	seatNumber = strings.ToUpper(strings.TrimSpace(seatNumber))
-	
-	if len(seatNumber) < 2 {
-		return fmt.Errorf("invalid seat number format: %s", seatNumber)
-	}
-
-	// Parse row (letter) and column (number)
-	row := seatNumber[0]
-	colStr := seatNumber[1:]
-
-	// Validate row (A-Z maps to 0-25)
-	if row < 'A' || row > 'Z' {
-		return fmt.Errorf("invalid seat row: %s", seatNumber)
-	}
-
-	rowIndex := int(row - 'A')
-	if rowIndex >= totalRows {
-		return fmt.Errorf("seat row %c exceeds studio capacity (max row: %c)", row, rune('A'+totalRows-1))
+// releaseTickets deletes a booking's tickets and announces the freed seats.
+// Admitted tickets are kept: the seat was used, and their check-in record and
+// QR token stay as the admission history of a refunded booking.
+// Must be called inside the transaction that changes the booking status.
+func releaseTickets(tx *gorm.DB, bookingID uuid.UUID, reason string) error {
+	var tickets []models.Ticket
+	if err := tx.Clauses(clause.Returning{}).
+		Where("booking_id = ? AND checked_in_at IS NULL", bookingID).
+		Delete(&tickets).Error; err != nil {
+		return fmt.Errorf("failed to delete tickets: %w", err)
 	}
 
-	// Validate column
-	col, err := strconv.Atoi(colStr)
-	if err != nil {
-		return fmt.Errorf("invalid seat column: %s", seatNumber)
+	// Group freed seats per showtime (a booking normally has one)
+	seatsByShowtime := make(map[uint][]string)
+	for _, ticket := range tickets {
+		seatsByShowtime[ticket.ShowtimeID] = append(seatsByShowtime[ticket.ShowtimeID], ticket.SeatNumber)
 	}
 
-	if col < 1 || col > totalCols {
-		return fmt.Errorf("seat column %d exceeds studio capacity (1-%d)", col, totalCols)
+	for showtimeID, seats := range seatsByShowtime {
+		if err := publishSeatEvent(tx, SeatEventReleased, reason, showtimeID, seats); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// generateInvoiceNumber generates a unique invoice number
-func generateInvoiceNumber() string {
-	now := time.Now()
-	return fmt.Sprintf("INV-%d%02d%02d-%s",
-		now.Year(),
-		now.Month(),
-		now.Day(),
-		strings.ToUpper(uuid.New().String()[:8]),
-	)
-}
-
 // removeDuplicateSeats removes duplicate seat numbers
 func removeDuplicateSeats(seats []string) []string {
 	seen := make(map[string]bool)
@@ -464,12 +749,27 @@ func (bs *BookingService) HandleInvoiceCallback(payload *models.XenditInvoiceCal
 		return err
 	}
 
+	return bs.processInvoiceCallback(payload)
+}
+
+// processInvoiceCallback applies a callback whose token has already been verified
+func (bs *BookingService) processInvoiceCallback(payload *models.XenditInvoiceCallback) error {
 	// 2. Parse external_id (booking UUID)
 	bookingID, err := uuid.Parse(payload.ExternalID)
 	if err != nil {
 		return NewWebhookError(ErrCodeBookingNotFound, "invalid external_id format")
 	}
 
+	// The invoice ID identifies the payment attempt; it must belong to the booking it names.
+	// Invoices from before payment attempts were recorded are matched by external_id alone.
+	payment, err := findPaymentAttempt(bs.db, payload.ID)
+	if err != nil {
+		return err
+	}
+	if payment != nil && payment.BookingID != bookingID {
+		return NewWebhookError(ErrCodeInvalidPayload, "invoice does not belong to the booking in external_id")
+	}
+
 	// 3. Find the booking
 	var booking models.Booking
 	if err := bs.db.First(&booking, "id = ?", bookingID).Error; err != nil {
@@ -493,56 +793,227 @@ func (bs *BookingService) HandleInvoiceCallback(payload *models.XenditInvoiceCal
 	}
 }
 
-// handlePaymentSuccess handles successful payment (PAID or SETTLED status)
+// handlePaymentSuccess handles successful payment (PAID or SETTLED status).
+// The booking is only confirmed when the callback is for its current invoice and the
+// amount and currency match; anything else moves it to PAYMENT_REVIEW and raises an alert.
 func (bs *BookingService) handlePaymentSuccess(booking *models.Booking, payload *models.XenditInvoiceCallback) error {
-	// Idempotency check: if already paid, skip processing
-	if booking.Status == BookingStatusPaid {
-		// Already processed - return nil (success) for idempotency
-		return nil
-	}
+	return bs.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the booking so the reaper or a cancellation can't change it underneath us
+		var current models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&current, "id = ?", booking.ID).Error; err != nil {
+			return fmt.Errorf("failed to lock booking: %w", err)
+		}
 
-	// Update booking status to PAID
-	result := bs.db.Model(booking).Updates(map[string]interface{}{
-		"status":     BookingStatusPaid,
-		"payment_id": payload.ID, // Store Xendit invoice ID for reference
+		// Whatever happens to the booking, this attempt's invoice was paid
+		if err := markPaymentPaid(tx, payload); err != nil {
+			return err
+		}
+		if err := postPaymentReceived(tx, &current, payload); err != nil {
+			return err
+		}
+
+		switch current.Status {
+		case BookingStatusPaid:
+			// Idempotency check: the same invoice was already confirmed
+			if current.PaymentID == payload.ID {
+				return nil
+			}
+			// A second invoice was paid for a confirmed booking; keep the booking as is
+			raisePaymentAlert(current.ID, payload.ID, fmt.Sprintf(
+				"booking was already paid with invoice %s and received another payment", current.PaymentID))
+			return nil
+
+		case BookingStatusPaymentReview:
+			raisePaymentAlert(current.ID, payload.ID, "additional payment received for a booking under review")
+			return nil
+
+		case BookingStatusRefunded, BookingStatusPartiallyRefunded:
+			// A late or replayed callback for the invoice that was refunded
+			if current.PaymentID == payload.ID {
+				return nil
+			}
+			raisePaymentAlert(current.ID, payload.ID, "payment received for a refunded booking")
+			return nil
+		}
+
+		if reason := verifyPayment(&current, payload); reason != "" {
+			result := tx.Model(&current).Updates(map[string]interface{}{
+				"status":                BookingStatusPaymentReview,
+				"payment_review_reason": reason,
+			})
+			if result.Error != nil {
+				return fmt.Errorf("failed to move booking to PAYMENT_REVIEW: %w", result.Error)
+			}
+			raisePaymentAlert(current.ID, payload.ID, reason)
+			return nil
+		}
+
+		// Update booking status to PAID
+		if err := tx.Model(&current).Update("status", BookingStatusPaid).Error; err != nil {
+			return fmt.Errorf("failed to update booking status to PAID: %w", err)
+		}
+
+		return confirmPaidBooking(tx, current.ID)
 	})
+}
 
-	if result.Error != nil {
-		return fmt.Errorf("failed to update booking status to PAID: %w", result.Error)
+// confirmPaidBooking does what follows a booking becoming PAID: its tickets are signed so
+// they can be scanned at the door, the customer is emailed them and reminders are planned.
+// Must be called in the transaction that marks the booking PAID.
+func confirmPaidBooking(tx *gorm.DB, bookingID uuid.UUID) error {
+	if err := issueTicketTokens(tx, bookingID); err != nil {
+		return err
 	}
+	if err := enqueueBookingEmail(tx, models.EmailPaymentConfirmed, bookingID, nil); err != nil {
+		return err
+	}
+	return planReminders(tx, bookingID)
+}
 
-	// TODO: Trigger ticket generation or send confirmation email here
-	// Example:
-	// go bs.sendBookingConfirmationEmail(booking.ID)
-	// go bs.generateTicketPDF(booking.ID)
+// GetPaymentReviewBookings lists bookings waiting for payment review, oldest first
+func (bs *BookingService) GetPaymentReviewBookings() ([]models.Booking, error) {
+	var bookings []models.Booking
+	if err := bs.db.Preload("Tickets").
+		Where("status = ?", BookingStatusPaymentReview).
+		Order("created_at ASC").
+		Find(&bookings).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch bookings: %w", err)
+	}
+	return bookings, nil
+}
 
-	return nil
+// ResolvePaymentReview settles a booking in PAYMENT_REVIEW. Approving confirms it as PAID
+// (only possible while it still holds its seats); rejecting cancels it and releases the seats.
+// Any refund owed to the customer is handled separately.
+func (bs *BookingService) ResolvePaymentReview(bookingID uuid.UUID, approve bool) error {
+	return bs.db.Transaction(func(tx *gorm.DB) error {
+		var booking models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&booking, "id = ?", bookingID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("booking not found")
+			}
+			return err
+		}
+
+		if booking.Status != BookingStatusPaymentReview {
+			return errors.New("booking is not under payment review")
+		}
+
+		if approve {
+			var tickets int64
+			if err := tx.Model(&models.Ticket{}).Where("booking_id = ?", bookingID).Count(&tickets).Error; err != nil {
+				return fmt.Errorf("failed to count tickets: %w", err)
+			}
+			if tickets == 0 {
+				return errors.New("cannot approve a booking whose seats were released")
+			}
+			if err := tx.Model(&booking).Update("status", BookingStatusPaid).Error; err != nil {
+				return err
+			}
+			return confirmPaidBooking(tx, bookingID)
+		}
+
+		// The payment stays on the ledger; refunding it is a separate step
+		if err := postBookingReleased(tx, &booking, models.LedgerEventBookingCancelled); err != nil {
+			return err
+		}
+		if err := enqueueBookingEmail(tx, models.EmailBookingCancelled, bookingID, nil); err != nil {
+			return err
+		}
+		if err := releaseTickets(tx, bookingID, SeatReasonCancelled); err != nil {
+			return err
+		}
+		if err := releaseVoucherRedemption(tx, bookingID); err != nil {
+			return err
+		}
+		if err := releaseBookingItems(tx, bookingID); err != nil {
+			return err
+		}
+		return tx.Model(&booking).Update("status", BookingStatusCancelled).Error
+	})
 }
 
-// handlePaymentExpired handles expired payment (EXPIRED status)
-func (bs *BookingService) handlePaymentExpired(booking *models.Booking, payload *models.XenditInvoiceCallback) error {
-	// Idempotency check: if already cancelled or expired, skip processing
+// verifyPayment compares a PAID callback with the booking it claims to pay.
+// Returns the reason the payment can't be accepted, or "" if it matches.
+func verifyPayment(booking *models.Booking, payload *models.XenditInvoiceCallback) string {
 	if booking.Status == BookingStatusCancelled || booking.Status == BookingStatusExpired {
-		return nil
+		return fmt.Sprintf("payment received for a %s booking whose seats were released", strings.ToLower(booking.Status))
+	}
+	if booking.PaymentID == "" || payload.ID != booking.PaymentID {
+		return fmt.Sprintf("invoice %s is not the booking's current invoice %q", payload.ID, booking.PaymentID)
 	}
+	if !strings.EqualFold(payload.Currency, BookingCurrency) {
+		return fmt.Sprintf("currency %q does not match %s", payload.Currency, BookingCurrency)
+	}
+	if !payload.Amount.Equal(booking.TotalAmount) {
+		return fmt.Sprintf("invoice amount %s does not match booking total %s", payload.Amount, booking.TotalAmount)
+	}
+	if !payload.PaidAmount.Equal(booking.TotalAmount) {
+		return fmt.Sprintf("paid amount %s does not match booking total %s", payload.PaidAmount, booking.TotalAmount)
+	}
+	return ""
+}
 
-	// Don't expire if already paid (edge case where PAID and EXPIRED come out of order)
-	if booking.Status == BookingStatusPaid {
-		return nil
+// handlePaymentExpired handles expired payment (EXPIRED status)
+func (bs *BookingService) handlePaymentExpired(booking *models.Booking, payload *models.XenditInvoiceCallback) error {
+	if err := markPaymentExpired(bs.db, payload.ID); err != nil {
+		return err
 	}
 
-	// Update booking status to CANCELLED and release seats
+	// Expire the booking and release seats
 	return bs.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the booking so a retry, a PAID callback or the reconciler can't change it underneath us
+		var current models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&current, "id = ?", booking.ID).Error; err != nil {
+			return fmt.Errorf("failed to lock booking: %w", err)
+		}
+
+		// Only a booking still waiting for payment can expire. This skips repeats (already
+		// cancelled or expired), PAID and EXPIRED arriving out of order, payments waiting
+		// for review and refunded bookings.
+		if current.Status != BookingStatusPending {
+			return nil
+		}
+
+		// An older attempt expiring is expected after a retry; only the latest one decides
+		if current.PaymentID != "" && current.PaymentID != payload.ID {
+			return nil
+		}
+
+		// Only move bookings that are still PENDING, as expireBooking does
+		result := tx.Model(&models.Booking{}).
+			Where("id = ? AND status = ?", current.ID, BookingStatusPending).
+			Update("status", BookingStatusExpired)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update booking status to EXPIRED: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := postBookingReleased(tx, &current, models.LedgerEventBookingExpired); err != nil {
+			return err
+		}
+		if err := enqueueBookingEmail(tx, models.EmailBookingExpired, current.ID, nil); err != nil {
+			return err
+		}
+
 		// Delete tickets to release seats
-		if err := tx.Where("booking_id = ?", booking.ID).Delete(&models.Ticket{}).Error; err != nil {
-			return fmt.Errorf("failed to delete tickets: %w", err)
+		if err := releaseTickets(tx, current.ID, SeatReasonExpired); err != nil {
+			return err
 		}
 
-		// Update booking status
-		if err := tx.Model(booking).Update("status", BookingStatusCancelled).Error; err != nil {
-			return fmt.Errorf("failed to update booking status to CANCELLED: %w", err)
+		if err := releaseVoucherRedemption(tx, current.ID); err != nil {
+			return err
+		}
+		if err := releaseBookingItems(tx, current.ID); err != nil {
+			return err
 		}
 
+		booking.Status = BookingStatusExpired
 		return nil
 	})
 }
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"absolutcinema-backend/internal/database"
+	"absolutcinema-backend/internal/models"
+)
+
+func TestExpireStalePendingBookingsRotatesPastFailures(t *testing.T) {
+	db := newTestDB(t, database.Models()...)
+	defer func(size int) { expireBatchSize = size }(expireBatchSize)
+	expireBatchSize = 2
+
+	// The two oldest stale bookings have invoices the gateway can't be asked about
+	provider := &stubProvider{failing: map[string]bool{"inv-0": true, "inv-1": true}}
+	created := time.Now().Add(-2 * time.Hour)
+	var bookings []models.Booking
+	for i := 0; i < 3; i++ {
+		booking := seedBooking(t, db, BookingStatusPending, fmt.Sprintf("inv-%d", i))
+		if err := db.Model(&booking).Updates(map[string]interface{}{
+			"created_at":         created.Add(time.Duration(i) * time.Minute),
+			"payment_expires_at": time.Now().Add(-time.Hour),
+		}).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+		bookings = append(bookings, booking)
+	}
+
+	bs := NewBookingService(db, provider)
+	first, err := bs.ExpireStalePendingBookings(context.Background(), 30*time.Minute)
+	if err != nil || first != 0 {
+		t.Fatalf("first pass expired %d (err %v), want 0: its whole page keeps failing", first, err)
+	}
+	second, err := bs.ExpireStalePendingBookings(context.Background(), 30*time.Minute)
+	if err != nil || second != 1 {
+		t.Fatalf("second pass expired %d (err %v), want the booking the first pass left", second, err)
+	}
+
+	var newest models.Booking
+	if err := db.First(&newest, "id = ?", bookings[2].ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if newest.Status != BookingStatusExpired {
+		t.Errorf("newest stale booking is %s, want EXPIRED", newest.Status)
+	}
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+const (
+	// DefaultRefundCutoff is how close to the showtime customers can still cancel
+	// when no cancellation policy is configured
+	DefaultRefundCutoff = 2 * time.Hour
+
+	// DefaultRefundPercent is the share of the payment returned when no policy is configured
+	DefaultRefundPercent = 100
+)
+
+// policyScopeRank orders scopes from least to most specific
+var policyScopeRank = map[string]int{
+	models.PolicyScopeGlobal:   0,
+	models.PolicyScopeStudio:   1,
+	models.PolicyScopeMovie:    2,
+	models.PolicyScopeShowtime: 3,
+}
+
+// CancellationPolicyService handles cancellation policy management
+type CancellationPolicyService struct {
+	db *gorm.DB
+}
+
+// CancellationQuote is what cancelling a booking would return right now
+type CancellationQuote struct {
+	Policy        *models.CancellationPolicy `json:"policy,omitempty"`
+	Cancellable   bool                       `json:"cancellable"`
+	RefundPercent int64                      `json:"refund_percent"`
+	RefundAmount  money.Money                `json:"refund_amount"`
+	ValidUntil    *time.Time                 `json:"valid_until,omitempty"` // When the current refund tier ends
+	Reason        string                     `json:"reason,omitempty"`      // Why the booking can't be cancelled
+}
+
+// NewCancellationPolicyService creates a new cancellation policy service
+func NewCancellationPolicyService(db *gorm.DB) *CancellationPolicyService {
+	return &CancellationPolicyService{db: db}
+}
+
+// CreatePolicy creates a new cancellation policy with validation
+func (s *CancellationPolicyService) CreatePolicy(policy *models.CancellationPolicy) error {
+	if err := s.validatePolicy(0, policy); err != nil {
+		return err
+	}
+	return s.db.Create(policy).Error
+}
+
+// GetAllPolicies retrieves all cancellation policies, least specific first
+func (s *CancellationPolicyService) GetAllPolicies() ([]models.CancellationPolicy, error) {
+	var policies []models.CancellationPolicy
+	if err := s.db.Order("id ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	sort.SliceStable(policies, func(i, j int) bool {
+		return policyScopeRank[policies[i].Scope] < policyScopeRank[policies[j].Scope]
+	})
+	return policies, nil
+}
+
+// GetPolicyByID retrieves a cancellation policy by ID
+func (s *CancellationPolicyService) GetPolicyByID(id uint) (*models.CancellationPolicy, error) {
+	var policy models.CancellationPolicy
+	if err := s.db.First(&policy, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("cancellation policy not found")
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// UpdatePolicy updates an existing cancellation policy
+func (s *CancellationPolicyService) UpdatePolicy(id uint, updates *models.CancellationPolicy) error {
+	policy, err := s.GetPolicyByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.validatePolicy(id, updates); err != nil {
+		return err
+	}
+
+	updates.ID = policy.ID
+	updates.CreatedAt = policy.CreatedAt
+
+	return s.db.Save(updates).Error
+}
+
+// DeletePolicy soft deletes a cancellation policy
+func (s *CancellationPolicyService) DeletePolicy(id uint) error {
+	result := s.db.Delete(&models.CancellationPolicy{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("cancellation policy not found")
+	}
+	return nil
+}
+
+// validatePolicy checks the scope target and rules; excludeID is the policy being updated
+func (s *CancellationPolicyService) validatePolicy(excludeID uint, policy *models.CancellationPolicy) error {
+	policy.Name = strings.TrimSpace(policy.Name)
+	if policy.Name == "" {
+		return errors.New("policy name is required")
+	}
+
+	policy.Scope = strings.ToUpper(strings.TrimSpace(policy.Scope))
+	var target interface{}
+	switch policy.Scope {
+	case models.PolicyScopeGlobal:
+		policy.ScopeID = nil
+	case models.PolicyScopeStudio:
+		target = &models.Studio{}
+	case models.PolicyScopeMovie:
+		target = &models.Movie{}
+	case models.PolicyScopeShowtime:
+		target = &models.Showtime{}
+	default:
+		return errors.New("scope must be GLOBAL, STUDIO, MOVIE or SHOWTIME")
+	}
+
+	if target != nil {
+		if policy.ScopeID == nil {
+			return errors.New("scope_id is required for this scope")
+		}
+		var count int64
+		if err := s.db.Model(target).Where("id = ?", *policy.ScopeID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%s %d not found", strings.ToLower(policy.Scope), *policy.ScopeID)
+		}
+	}
+
+	// One policy per target, otherwise which one applies would be ambiguous
+	query := s.db.Model(&models.CancellationPolicy{}).Where("scope = ? AND id != ?", policy.Scope, excludeID)
+	if policy.ScopeID == nil {
+		query = query.Where("scope_id IS NULL")
+	} else {
+		query = query.Where("scope_id = ?", *policy.ScopeID)
+	}
+	var existing int64
+	if err := query.Count(&existing).Error; err != nil {
+		return err
+	}
+	if existing > 0 {
+		return errors.New("a cancellation policy is already attached to this target")
+	}
+
+	seen := make(map[int]bool, len(policy.Rules))
+	for _, rule := range policy.Rules {
+		if rule.MinutesBefore < 0 {
+			return errors.New("minutes_before cannot be negative")
+		}
+		if rule.RefundPercent < 0 || rule.RefundPercent > 100 {
+			return errors.New("refund_percent must be between 0 and 100")
+		}
+		if seen[rule.MinutesBefore] {
+			return fmt.Errorf("duplicate rule for %d minutes before start", rule.MinutesBefore)
+		}
+		seen[rule.MinutesBefore] = true
+	}
+
+	// Keep tiers sorted earliest first, which is also how they are evaluated
+	sort.Slice(policy.Rules, func(i, j int) bool {
+		return policy.Rules[i].MinutesBefore > policy.Rules[j].MinutesBefore
+	})
+	if policy.Rules == nil {
+		policy.Rules = []models.CancellationRule{}
+	}
+
+	return nil
+}
+
+// resolveCancellationPolicy returns the most specific policy attached to a showtime,
+// or the default policy when none is configured
+func resolveCancellationPolicy(db *gorm.DB, showtime *models.Showtime) (*models.CancellationPolicy, error) {
+	var policies []models.CancellationPolicy
+	err := db.Where("scope = ?", models.PolicyScopeGlobal).
+		Or("scope = ? AND scope_id = ?", models.PolicyScopeStudio, showtime.StudioID).
+		Or("scope = ? AND scope_id = ?", models.PolicyScopeMovie, showtime.MovieID).
+		Or("scope = ? AND scope_id = ?", models.PolicyScopeShowtime, showtime.ID).
+		Find(&policies).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cancellation policies: %w", err)
+	}
+
+	if len(policies) == 0 {
+		return defaultCancellationPolicy(), nil
+	}
+
+	best := &policies[0]
+	for i := range policies[1:] {
+		if policyScopeRank[policies[i+1].Scope] > policyScopeRank[best.Scope] {
+			best = &policies[i+1]
+		}
+	}
+	return best, nil
+}
+
+// defaultCancellationPolicy is used when no policy is configured. It has a single tier
+// read from REFUND_CUTOFF_HOURS and REFUND_PERCENT.
+func defaultCancellationPolicy() *models.CancellationPolicy {
+	cutoff := DefaultRefundCutoff
+	if hours, err := strconv.Atoi(os.Getenv("REFUND_CUTOFF_HOURS")); err == nil && hours >= 0 {
+		cutoff = time.Duration(hours) * time.Hour
+	}
+
+	percent := int64(DefaultRefundPercent)
+	if p, err := strconv.Atoi(os.Getenv("REFUND_PERCENT")); err == nil && p >= 0 && p <= 100 {
+		percent = int64(p)
+	}
+
+	return &models.CancellationPolicy{
+		Name:  "Default policy",
+		Scope: models.PolicyScopeGlobal,
+		Rules: []models.CancellationRule{
+			{MinutesBefore: int(cutoff / time.Minute), RefundPercent: percent},
+		},
+	}
+}
+
+// evaluateCancellationPolicy quotes cancelling a paid booking for a showtime starting at start
+func evaluateCancellationPolicy(policy *models.CancellationPolicy, start, now time.Time, refundable money.Money) *CancellationQuote {
+	quote := &CancellationQuote{Policy: policy}
+
+	var match *models.CancellationRule
+	for i, rule := range policy.Rules {
+		if start.Sub(now) < time.Duration(rule.MinutesBefore)*time.Minute {
+			continue
+		}
+		if match == nil || rule.MinutesBefore > match.MinutesBefore {
+			match = &policy.Rules[i]
+		}
+	}
+
+	if match == nil {
+		if start.Before(now) {
+			quote.Reason = "the showtime has already started"
+		} else {
+			quote.Reason = "this booking can no longer be cancelled"
+		}
+		return quote
+	}
+
+	validUntil := start.Add(-time.Duration(match.MinutesBefore) * time.Minute)
+	quote.Cancellable = true
+	quote.RefundPercent = match.RefundPercent
+	quote.RefundAmount = refundable.MulRatio(match.RefundPercent, 100)
+	quote.ValidUntil = &validUntil
+	return quote
+}
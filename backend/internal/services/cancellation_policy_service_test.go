@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+func TestEvaluateCancellationPolicyPicksEarliestMatchingTier(t *testing.T) {
+	// Full refund until 24 hours before start, half until 2 hours before, nothing after
+	policy := &models.CancellationPolicy{
+		Rules: []models.CancellationRule{
+			{MinutesBefore: 120, RefundPercent: 50},
+			{MinutesBefore: 24 * 60, RefundPercent: 100},
+		},
+	}
+	start := time.Date(2026, 1, 10, 19, 0, 0, 0, time.UTC)
+	paid := money.FromMajor(90000)
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		cancellable bool
+		refund      int64
+	}{
+		{"two days before", start.Add(-48 * time.Hour), true, 90000},
+		{"five hours before", start.Add(-5 * time.Hour), true, 45000},
+		{"one hour before", start.Add(-time.Hour), false, 0},
+	}
+
+	for _, tt := range tests {
+		quote := evaluateCancellationPolicy(policy, start, tt.now, paid)
+		if quote.Cancellable != tt.cancellable || quote.RefundAmount.Minor() != tt.refund {
+			t.Errorf("%s: got cancellable=%v refund=%s", tt.name, quote.Cancellable, quote.RefundAmount)
+		}
+	}
+}
@@ -0,0 +1,526 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+const (
+	// MaxConcessionQuantity is the most of one product a booking can include
+	MaxConcessionQuantity = 20
+)
+
+// ConcessionService handles the food and beverage catalog and its stock
+type ConcessionService struct {
+	db *gorm.DB
+}
+
+// ProductLine is a concession product requested with a booking
+type ProductLine struct {
+	ProductID FlexibleUint `json:"product_id" binding:"required"`
+	Quantity  int          `json:"quantity" binding:"required"`
+}
+
+// AvailableConcession is a product on sale for a showtime, with how many can still be ordered
+type AvailableConcession struct {
+	models.ConcessionProduct
+	Available int `json:"available"`
+}
+
+// NewConcessionService creates a new concession service
+func NewConcessionService(db *gorm.DB) *ConcessionService {
+	return &ConcessionService{db: db}
+}
+
+// CreateProduct creates a new product or combo with validation
+func (s *ConcessionService) CreateProduct(product *models.ConcessionProduct) error {
+	if err := s.validateProduct(product); err != nil {
+		return err
+	}
+	return s.db.Create(product).Error
+}
+
+// GetAllProducts retrieves all products with their combo components
+func (s *ConcessionService) GetAllProducts() ([]models.ConcessionProduct, error) {
+	var products []models.ConcessionProduct
+	if err := s.db.Preload("ComboItems.Product").Order("category ASC, name ASC").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// GetProductByID retrieves a product by ID
+func (s *ConcessionService) GetProductByID(id uint) (*models.ConcessionProduct, error) {
+	var product models.ConcessionProduct
+	if err := s.db.Preload("ComboItems.Product").First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// UpdateProduct updates a product; a combo's components are replaced by the ones given
+func (s *ConcessionService) UpdateProduct(id uint, updates *models.ConcessionProduct) error {
+	updates.ID = id
+	if err := s.validateProduct(updates); err != nil {
+		return err
+	}
+
+	var product models.ConcessionProduct
+	if err := s.db.First(&product, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("product not found")
+		}
+		return err
+	}
+
+	product.Code = updates.Code
+	product.Name = updates.Name
+	product.Description = updates.Description
+	product.Category = updates.Category
+	product.Price = updates.Price
+	product.Active = updates.Active
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("ComboItems").Save(&product).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("combo_id = ?", id).Delete(&models.ConcessionComboItem{}).Error; err != nil {
+			return err
+		}
+		for i := range updates.ComboItems {
+			updates.ComboItems[i].ID = 0
+			updates.ComboItems[i].ComboID = id
+		}
+		if len(updates.ComboItems) > 0 {
+			return tx.Omit("Product").Create(&updates.ComboItems).Error
+		}
+		return nil
+	})
+}
+
+// DeleteProduct soft deletes a product. Bookings keep their copy of its name and price.
+func (s *ConcessionService) DeleteProduct(id uint) error {
+	var combos int64
+	if err := s.db.Model(&models.ConcessionComboItem{}).
+		Joins("JOIN concession_products ON concession_products.id = concession_combo_items.combo_id AND concession_products.deleted_at IS NULL").
+		Where("concession_combo_items.product_id = ?", id).
+		Count(&combos).Error; err != nil {
+		return err
+	}
+	if combos > 0 {
+		return errors.New("product is part of a combo")
+	}
+
+	result := s.db.Delete(&models.ConcessionProduct{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("product not found")
+	}
+	return nil
+}
+
+// GetStock lists a product's stock at every cinema
+func (s *ConcessionService) GetStock(productID uint) ([]models.ConcessionStock, error) {
+	var stock []models.ConcessionStock
+	if err := s.db.Where("product_id = ?", productID).Order("cinema ASC").Find(&stock).Error; err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
+
+// SetStock sets how many of a product a cinema has. Combos have no stock of their own.
+func (s *ConcessionService) SetStock(productID uint, cinema string, quantity int) (*models.ConcessionStock, error) {
+	product, err := s.GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Category == models.ConcessionCategoryCombo {
+		return nil, errors.New("combos use the stock of their components")
+	}
+
+	cinema = strings.ToUpper(strings.TrimSpace(cinema))
+	if cinema == "" {
+		cinema = models.DefaultBillingEntity
+	}
+	if !billingEntityPattern.MatchString(cinema) {
+		return nil, errors.New("cinema must be 1-50 letters, digits, '-' or '_'")
+	}
+	if quantity < 0 {
+		return nil, errors.New("quantity cannot be negative")
+	}
+
+	stock := models.ConcessionStock{ProductID: productID, Cinema: cinema, Quantity: quantity}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "cinema"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+	}).Omit("Product").Create(&stock).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+// GetShowtimeConcessions lists the active products sold at a showtime's cinema.
+// Available is the stock left (for a combo, how many its components can still make).
+func (s *ConcessionService) GetShowtimeConcessions(showtimeID uint) ([]AvailableConcession, error) {
+	var showtime models.Showtime
+	if err := s.db.Preload("Studio").First(&showtime, showtimeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("showtime not found")
+		}
+		return nil, err
+	}
+	cinema := billingEntity(&showtime.Studio)
+
+	var products []models.ConcessionProduct
+	if err := s.db.Preload("ComboItems").Where("active = ?", true).Order("category ASC, name ASC").Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	var stock []models.ConcessionStock
+	if err := s.db.Where("cinema = ?", cinema).Find(&stock).Error; err != nil {
+		return nil, err
+	}
+	levels := make(map[uint]int, len(stock))
+	for _, st := range stock {
+		levels[st.ProductID] = st.Quantity
+	}
+
+	available := make([]AvailableConcession, 0, len(products))
+	for _, p := range products {
+		count, sold := productAvailability(&p, levels)
+		if !sold {
+			continue
+		}
+		available = append(available, AvailableConcession{ConcessionProduct: p, Available: count})
+	}
+	return available, nil
+}
+
+// productAvailability returns how many of a product can be ordered given stock levels,
+// and whether the cinema sells it at all
+func productAvailability(product *models.ConcessionProduct, levels map[uint]int) (int, bool) {
+	if product.Category != models.ConcessionCategoryCombo {
+		level, ok := levels[product.ID]
+		return level, ok
+	}
+
+	count := -1
+	for _, item := range product.ComboItems {
+		level, ok := levels[item.ProductID]
+		if !ok || item.Quantity <= 0 {
+			return 0, false
+		}
+		if n := level / item.Quantity; count < 0 || n < count {
+			count = n
+		}
+	}
+	if count < 0 {
+		return 0, false
+	}
+	return count, true
+}
+
+// validateProduct validates product data
+func (s *ConcessionService) validateProduct(product *models.ConcessionProduct) error {
+	product.Code = strings.ToUpper(strings.TrimSpace(product.Code))
+	if product.Code == "" {
+		return errors.New("product code is required")
+	}
+	product.Name = strings.TrimSpace(product.Name)
+	if product.Name == "" {
+		return errors.New("product name is required")
+	}
+	if !product.Price.IsPositive() {
+		return errors.New("price must be positive")
+	}
+
+	product.Category = strings.ToUpper(strings.TrimSpace(product.Category))
+	switch product.Category {
+	case models.ConcessionCategorySnack, models.ConcessionCategoryDrink:
+		if len(product.ComboItems) > 0 {
+			return errors.New("only combos can have combo items")
+		}
+		return nil
+	case models.ConcessionCategoryCombo:
+	default:
+		return errors.New("category must be SNACK, DRINK or COMBO")
+	}
+
+	if len(product.ComboItems) == 0 {
+		return errors.New("a combo needs at least one item")
+	}
+	seen := make(map[uint]bool, len(product.ComboItems))
+	ids := make([]uint, 0, len(product.ComboItems))
+	for _, item := range product.ComboItems {
+		if item.Quantity <= 0 {
+			return errors.New("combo item quantity must be positive")
+		}
+		if seen[item.ProductID] || (product.ID != 0 && item.ProductID == product.ID) {
+			return fmt.Errorf("product %d is listed more than once in the combo", item.ProductID)
+		}
+		seen[item.ProductID] = true
+		ids = append(ids, item.ProductID)
+	}
+
+	var components int64
+	if err := s.db.Model(&models.ConcessionProduct{}).
+		Where("id IN ? AND category != ?", ids, models.ConcessionCategoryCombo).
+		Count(&components).Error; err != nil {
+		return err
+	}
+	if int(components) != len(ids) {
+		return errors.New("combo items must be existing products that are not combos")
+	}
+	return nil
+}
+
+// prepareBookingItems turns requested product lines into booking items priced from the catalog.
+// Repeated products are merged into one line.
+func prepareBookingItems(db *gorm.DB, lines []ProductLine, cinema string) ([]models.BookingItem, money.Money, error) {
+	if len(lines) == 0 {
+		return nil, money.Money{}, nil
+	}
+
+	quantities := make(map[uint]int, len(lines))
+	order := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		id := uint(line.ProductID)
+		if line.Quantity <= 0 {
+			return nil, money.Money{}, errors.New("product quantity must be positive")
+		}
+		if _, ok := quantities[id]; !ok {
+			order = append(order, id)
+		}
+		quantities[id] += line.Quantity
+		if quantities[id] > MaxConcessionQuantity {
+			return nil, money.Money{}, fmt.Errorf("product quantity cannot exceed %d", MaxConcessionQuantity)
+		}
+	}
+
+	var products []models.ConcessionProduct
+	if err := db.Where("id IN ? AND active = ?", order, true).Find(&products).Error; err != nil {
+		return nil, money.Money{}, fmt.Errorf("failed to load products: %w", err)
+	}
+	byID := make(map[uint]*models.ConcessionProduct, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	items := make([]models.BookingItem, 0, len(order))
+	var total money.Money
+	for _, id := range order {
+		product, ok := byID[id]
+		if !ok {
+			return nil, money.Money{}, fmt.Errorf("product %d not found", id)
+		}
+		qty := quantities[id]
+		item := models.BookingItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Category:    product.Category,
+			Cinema:      cinema,
+			Quantity:    qty,
+			UnitPrice:   product.Price,
+			TotalPrice:  product.Price.MulInt(int64(qty)),
+			Status:      models.BookingItemStatusActive,
+		}
+		var err error
+		if total, err = total.Add(item.TotalPrice); err != nil {
+			return nil, money.Money{}, err
+		}
+		items = append(items, item)
+	}
+	return items, total, nil
+}
+
+// bookingItemComponents fills in the stock each booking item takes at today's catalog;
+// combos are broken down into their components
+func bookingItemComponents(tx *gorm.DB, items []models.BookingItem) error {
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	var parts []models.ConcessionComboItem
+	if err := tx.Where("combo_id IN ?", ids).Order("product_id").Find(&parts).Error; err != nil {
+		return fmt.Errorf("failed to load combo items: %w", err)
+	}
+	byCombo := make(map[uint][]models.ConcessionComboItem)
+	for _, part := range parts {
+		byCombo[part.ComboID] = append(byCombo[part.ComboID], part)
+	}
+
+	for i := range items {
+		item := &items[i]
+		item.Components = nil
+		if parts, ok := byCombo[item.ProductID]; ok && item.Category == models.ConcessionCategoryCombo {
+			for _, part := range parts {
+				item.Components = append(item.Components, models.BookingItemComponent{
+					ProductID: part.ProductID,
+					Quantity:  part.Quantity * item.Quantity,
+				})
+			}
+			continue
+		}
+		item.Components = []models.BookingItemComponent{{ProductID: item.ProductID, Quantity: item.Quantity}}
+	}
+	return nil
+}
+
+// stockNeeds returns how much of each stocked product a set of booking items takes
+func stockNeeds(items []models.BookingItem) map[uint]int {
+	needs := make(map[uint]int)
+	for _, item := range items {
+		for _, component := range item.Components {
+			needs[component.ProductID] += component.Quantity
+		}
+	}
+	return needs
+}
+
+// sortedProductIDs returns the keys of needs in ascending order, so concurrent
+// bookings lock stock rows in the same order
+func sortedProductIDs(needs map[uint]int) []uint {
+	ids := make([]uint, 0, len(needs))
+	for id := range needs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// reserveConcessionStock takes the stock for a booking's items at its cinema and
+// records it in their Components, which are saved with the items.
+// Must run in the booking transaction, so a failed booking takes nothing.
+func reserveConcessionStock(tx *gorm.DB, items []models.BookingItem, cinema string) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if err := bookingItemComponents(tx, items); err != nil {
+		return err
+	}
+	needs := stockNeeds(items)
+
+	for _, id := range sortedProductIDs(needs) {
+		result := tx.Model(&models.ConcessionStock{}).
+			Where("product_id = ? AND cinema = ? AND quantity >= ?", id, cinema, needs[id]).
+			Update("quantity", gorm.Expr("quantity - ?", needs[id]))
+		if result.Error != nil {
+			return fmt.Errorf("failed to reserve stock: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			var name string
+			tx.Model(&models.ConcessionProduct{}).Where("id = ?", id).Pluck("name", &name)
+			return fmt.Errorf("product %s is out of stock", name)
+		}
+	}
+	return nil
+}
+
+// releaseBookingItems returns the stock of a booking's items that were not picked up,
+// as recorded when they were booked.
+// Must be called inside the transaction that cancels, expires or refunds the booking.
+func releaseBookingItems(tx *gorm.DB, bookingID uuid.UUID) error {
+	var items []models.BookingItem
+	if err := tx.Preload("Components").
+		Where("booking_id = ? AND status = ?", bookingID, models.BookingItemStatusActive).
+		Find(&items).Error; err != nil {
+		return fmt.Errorf("failed to fetch booking items: %w", err)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	needs := stockNeeds(items)
+	cinema := items[0].Cinema
+	for _, id := range sortedProductIDs(needs) {
+		if err := tx.Model(&models.ConcessionStock{}).
+			Where("product_id = ? AND cinema = ?", id, cinema).
+			Update("quantity", gorm.Expr("quantity + ?", needs[id])).Error; err != nil {
+			return fmt.Errorf("failed to return stock: %w", err)
+		}
+	}
+
+	if err := tx.Model(&models.BookingItem{}).
+		Where("booking_id = ? AND status = ?", bookingID, models.BookingItemStatusActive).
+		Update("status", models.BookingItemStatusReleased).Error; err != nil {
+		return fmt.Errorf("failed to release booking items: %w", err)
+	}
+	return nil
+}
+
+// RedeemBookingItems hands out a paid booking's concession items at the counter.
+// With no item IDs every item not yet picked up is redeemed.
+func (bs *BookingService) RedeemBookingItems(bookingID uuid.UUID, itemIDs []uint, staffID uuid.UUID) ([]models.BookingItem, error) {
+	var redeemed []models.BookingItem
+	err := bs.db.Transaction(func(tx *gorm.DB) error {
+		var booking models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, "id = ?", bookingID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("booking not found")
+			}
+			return err
+		}
+		if booking.Status != BookingStatusPaid {
+			return errors.New("only paid bookings can be redeemed")
+		}
+
+		var items []models.BookingItem
+		query := tx.Where("booking_id = ?", bookingID)
+		if len(itemIDs) > 0 {
+			query = query.Where("id IN ?", itemIDs)
+		} else {
+			query = query.Where("status = ?", models.BookingItemStatusActive)
+		}
+		if err := query.Order("id ASC").Find(&items).Error; err != nil {
+			return fmt.Errorf("failed to fetch booking items: %w", err)
+		}
+		if len(items) == 0 || len(items) != len(itemIDs) && len(itemIDs) > 0 {
+			return errors.New("no items to redeem")
+		}
+		for _, item := range items {
+			if item.Status != models.BookingItemStatusActive {
+				return fmt.Errorf("item %d was already redeemed", item.ID)
+			}
+		}
+
+		now := time.Now()
+		ids := make([]uint, len(items))
+		for i := range items {
+			ids[i] = items[i].ID
+			items[i].Status = models.BookingItemStatusRedeemed
+			items[i].RedeemedAt = &now
+			items[i].RedeemedBy = &staffID
+		}
+		if err := tx.Model(&models.BookingItem{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":      models.BookingItemStatusRedeemed,
+			"redeemed_at": now,
+			"redeemed_by": staffID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to redeem items: %w", err)
+		}
+
+		redeemed = items
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return redeemed, nil
+}
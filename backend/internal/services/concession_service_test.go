@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+
+	"absolutcinema-backend/internal/models"
+)
+
+func TestProductAvailability(t *testing.T) {
+	popcorn := models.ConcessionProduct{ID: 1, Category: models.ConcessionCategorySnack}
+	cola := models.ConcessionProduct{ID: 2, Category: models.ConcessionCategoryDrink}
+	combo := models.ConcessionProduct{ID: 3, Category: models.ConcessionCategoryCombo, ComboItems: []models.ConcessionComboItem{
+		{ProductID: 1, Quantity: 1},
+		{ProductID: 2, Quantity: 2},
+	}}
+	levels := map[uint]int{1: 10, 2: 7}
+
+	tests := []struct {
+		name     string
+		product  models.ConcessionProduct
+		levels   map[uint]int
+		want     int
+		wantSold bool
+	}{
+		{"stocked product", popcorn, levels, 10, true},
+		{"combo limited by its scarcest component", combo, levels, 3, true},
+		{"product without stock row", cola, map[uint]int{1: 10}, 0, false},
+		{"combo with a component not sold here", combo, map[uint]int{1: 10}, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, sold := productAvailability(&tt.product, tt.levels)
+		if got != tt.want || sold != tt.wantSold {
+			t.Errorf("%s: got (%d, %v), want (%d, %v)", tt.name, got, sold, tt.want, tt.wantSold)
+		}
+	}
+}
+
+func TestStockNeedsUsesRecordedComponents(t *testing.T) {
+	// The combo was booked as 1 popcorn + 2 cola; the recipe stored in the catalog no longer matters
+	items := []models.BookingItem{
+		{ProductID: 3, Category: models.ConcessionCategoryCombo, Quantity: 2, Components: []models.BookingItemComponent{
+			{ProductID: 1, Quantity: 2},
+			{ProductID: 2, Quantity: 4},
+		}},
+		{ProductID: 2, Category: models.ConcessionCategoryDrink, Quantity: 1, Components: []models.BookingItemComponent{
+			{ProductID: 2, Quantity: 1},
+		}},
+	}
+
+	needs := stockNeeds(items)
+	if len(needs) != 2 || needs[1] != 2 || needs[2] != 5 {
+		t.Errorf("stockNeeds() = %v, want map[1:2 2:5]", needs)
+	}
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+const (
+	// FakeCheckoutPath is where the fake provider's checkout pages are served
+	FakeCheckoutPath = "/fake-checkout"
+
+	// fakeCallbackPath is the webhook route the fake provider calls, same as Xendit
+	fakeCallbackPath = "/webhooks/xendit"
+)
+
+// FakePaymentProvider is an in-memory PaymentProvider for local development and
+// integration tests. Its invoice URLs open a simulated checkout page; paying or
+// expiring there sends the same invoice callback Xendit would, through the
+// app's own router, so a full booking → paid cycle runs without any network.
+// Invoices live in memory and are lost on restart.
+type FakePaymentProvider struct {
+	baseURL string
+
+	mu              sync.Mutex
+	invoices        map[string]*FakeInvoice
+	callbackHandler http.Handler
+}
+
+// FakeInvoice is an invoice held by the fake provider
+type FakeInvoice struct {
+	InvoiceResult
+	Description        string            `json:"description"`
+	PayerEmail         string            `json:"payer_email"`
+	Items              []FakeInvoiceItem `json:"items"`
+	SuccessRedirectURL string            `json:"success_redirect_url"`
+	FailureRedirectURL string            `json:"failure_redirect_url"`
+	RefundedAmount     money.Money       `json:"refunded_amount"`
+	CreatedAt          time.Time         `json:"created_at"`
+}
+
+// FakeInvoiceItem is one line on a fake invoice (negative prices are discounts)
+type FakeInvoiceItem struct {
+	Name  string      `json:"name"`
+	Price money.Money `json:"price"`
+}
+
+// NewFakePaymentProvider creates a fake provider whose checkout pages are served under baseURL
+func NewFakePaymentProvider(baseURL string) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		invoices: make(map[string]*FakeInvoice),
+	}
+}
+
+// SetCallbackHandler sets the HTTP handler that receives invoice callbacks (the app's router)
+func (fp *FakePaymentProvider) SetCallbackHandler(handler http.Handler) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	fp.callbackHandler = handler
+}
+
+// Name returns the provider name
+func (fp *FakePaymentProvider) Name() string {
+	return PaymentProviderFake
+}
+
+// CreateInvoice creates a PENDING fake invoice for a booking
+func (fp *FakePaymentProvider) CreateInvoice(booking *models.Booking, userEmail string) (*InvoiceResult, error) {
+	invoiceID := "fake_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+
+	items := make([]FakeInvoiceItem, 0, len(booking.Tickets)+len(booking.Items)+1)
+	for _, ticket := range booking.Tickets {
+		items = append(items, FakeInvoiceItem{Name: invoiceItemName(ticket), Price: ticket.Price})
+	}
+	for _, bi := range booking.Items {
+		items = append(items, FakeInvoiceItem{Name: fmt.Sprintf("%s x%d", bi.ProductName, bi.Quantity), Price: bi.TotalPrice})
+	}
+	if booking.DiscountAmount.IsPositive() {
+		items = append(items, FakeInvoiceItem{Name: invoiceDiscountName(booking), Price: booking.DiscountAmount.Neg()})
+	}
+
+	inv := &FakeInvoice{
+		InvoiceResult: InvoiceResult{
+			InvoiceID:  invoiceID,
+			InvoiceURL: fmt.Sprintf("%s%s/%s", fp.baseURL, FakeCheckoutPath, invoiceID),
+			ExternalID: booking.ID.String(),
+			Amount:     booking.TotalAmount,
+			Status:     "PENDING",
+			Currency:   BookingCurrency,
+			ExpiryDate: invoiceExpiry(booking).Format(time.RFC3339),
+		},
+		Description:        fmt.Sprintf("AbsolutCinema Booking - Invoice %s", booking.InvoiceNumber),
+		PayerEmail:         userEmail,
+		Items:              items,
+		SuccessRedirectURL: getSuccessRedirectURL(),
+		FailureRedirectURL: getFailureRedirectURL(),
+		CreatedAt:          time.Now(),
+	}
+
+	fp.mu.Lock()
+	fp.invoices[invoiceID] = inv
+	fp.mu.Unlock()
+
+	result := inv.InvoiceResult
+	return &result, nil
+}
+
+// GetInvoice retrieves a fake invoice by ID
+func (fp *FakePaymentProvider) GetInvoice(invoiceID string) (*InvoiceResult, error) {
+	inv, err := fp.Checkout(invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	return &inv.InvoiceResult, nil
+}
+
+// GetInvoiceByExternalID retrieves the most recent fake invoice for a booking
+func (fp *FakePaymentProvider) GetInvoiceByExternalID(bookingID uuid.UUID) (*InvoiceResult, error) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	var latest *FakeInvoice
+	for _, inv := range fp.invoices {
+		if inv.ExternalID == bookingID.String() && (latest == nil || inv.CreatedAt.After(latest.CreatedAt)) {
+			latest = inv
+		}
+	}
+	if latest == nil {
+		return nil, errors.New("invoice not found")
+	}
+
+	result := latest.InvoiceResult
+	return &result, nil
+}
+
+// ExpireInvoice expires a PENDING fake invoice. Like an API-initiated expiry it
+// sends no callback; the caller already knows.
+func (fp *FakePaymentProvider) ExpireInvoice(invoiceID string) error {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	inv, ok := fp.invoices[invoiceID]
+	if !ok {
+		return errors.New("invoice not found")
+	}
+	if inv.Status != "PENDING" {
+		return fmt.Errorf("invoice is already %s", inv.Status)
+	}
+	inv.Status = models.XenditStatusExpired
+	return nil
+}
+
+// Refund refunds part or all of a paid fake invoice; fake refunds succeed immediately
+func (fp *FakePaymentProvider) Refund(invoiceID string, amount money.Money, reason string) (*RefundResult, error) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	inv, ok := fp.invoices[invoiceID]
+	if !ok {
+		return nil, errors.New("invoice not found")
+	}
+	if inv.Status != models.XenditStatusPaid && inv.Status != models.XenditStatusSettled {
+		return nil, errors.New("only paid invoices can be refunded")
+	}
+	refundable, err := inv.Amount.Sub(inv.RefundedAmount)
+	if err != nil {
+		return nil, err
+	}
+	exceeds, err := amount.GreaterThan(refundable)
+	if err != nil {
+		return nil, err
+	}
+	if !amount.IsPositive() || exceeds {
+		return nil, errors.New("refund amount exceeds the refundable amount")
+	}
+
+	if inv.RefundedAmount, err = inv.RefundedAmount.Add(amount); err != nil {
+		return nil, err
+	}
+
+	return &RefundResult{
+		RefundID:  "fake_rfd_" + strings.ReplaceAll(uuid.New().String(), "-", ""),
+		InvoiceID: invoiceID,
+		Amount:    amount,
+		Status:    RefundStatusSucceeded,
+	}, nil
+}
+
+// Checkout returns a copy of a fake invoice for the checkout page
+func (fp *FakePaymentProvider) Checkout(invoiceID string) (*FakeInvoice, error) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	inv, ok := fp.invoices[invoiceID]
+	if !ok {
+		return nil, errors.New("invoice not found")
+	}
+	clone := *inv
+	return &clone, nil
+}
+
+// Pay simulates the customer paying a fake invoice and sends the PAID callback
+func (fp *FakePaymentProvider) Pay(invoiceID string) error {
+	return fp.complete(invoiceID, models.XenditStatusPaid)
+}
+
+// Expire simulates a fake invoice running out of time and sends the EXPIRED callback
+func (fp *FakePaymentProvider) Expire(invoiceID string) error {
+	return fp.complete(invoiceID, models.XenditStatusExpired)
+}
+
+// complete moves a PENDING invoice to status and delivers the callback.
+// If the app rejects the callback the invoice goes back to PENDING so it can be retried.
+func (fp *FakePaymentProvider) complete(invoiceID, status string) error {
+	fp.mu.Lock()
+	inv, ok := fp.invoices[invoiceID]
+	if !ok {
+		fp.mu.Unlock()
+		return errors.New("invoice not found")
+	}
+	if inv.Status != "PENDING" {
+		fp.mu.Unlock()
+		return fmt.Errorf("invoice is already %s", inv.Status)
+	}
+	inv.Status = status
+	payload := fp.buildCallback(inv)
+	handler := fp.callbackHandler
+	fp.mu.Unlock()
+
+	if err := deliverFakeCallback(handler, payload); err != nil {
+		fp.mu.Lock()
+		inv.Status = "PENDING"
+		fp.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// buildCallback builds the Xendit-shaped callback payload for an invoice; caller must hold fp.mu
+func (fp *FakePaymentProvider) buildCallback(inv *FakeInvoice) *models.XenditInvoiceCallback {
+	now := time.Now().UTC()
+	payload := &models.XenditInvoiceCallback{
+		ID:           inv.InvoiceID,
+		ExternalID:   inv.ExternalID,
+		UserID:       "fake-merchant",
+		Status:       inv.Status,
+		MerchantName: "AbsolutCinema (fake)",
+		Amount:       inv.Amount,
+		PayerEmail:   inv.PayerEmail,
+		Description:  inv.Description,
+		Created:      inv.CreatedAt.UTC(),
+		Updated:      now,
+		Currency:     inv.Currency,
+	}
+	if inv.Status == models.XenditStatusPaid {
+		payload.PaidAmount = inv.Amount
+		payload.PaidAt = &now
+		payload.PaymentMethod = "FAKE"
+		payload.PaymentChannel = "FAKE"
+	}
+	return payload
+}
+
+// deliverFakeCallback sends a callback through the app's router with the configured webhook token
+func deliverFakeCallback(handler http.Handler, payload *models.XenditInvoiceCallback) error {
+	if handler == nil {
+		return errors.New("fake payment callback handler is not configured")
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode callback: %w", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, fakeCallbackPath, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-callback-token", os.Getenv("XENDIT_WEBHOOK_TOKEN"))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	if rec.Code != http.StatusOK || resp.Status != "success" {
+		return fmt.Errorf("callback was rejected (HTTP %d): %s", rec.Code, resp.Message)
+	}
+	return nil
+}
+
+// getFakePaymentBaseURL returns the public base URL of this API, used in fake invoice URLs
+func getFakePaymentBaseURL() string {
+	if baseURL := os.Getenv("FAKE_PAYMENT_BASE_URL"); baseURL != "" {
+		return baseURL
+	}
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	return "http://localhost:" + port
+}
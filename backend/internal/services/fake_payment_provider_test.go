@@ -0,0 +1,80 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+func TestFakePaymentProviderPayDeliversCallback(t *testing.T) {
+	t.Setenv("XENDIT_WEBHOOK_TOKEN", "test-token")
+
+	var received models.XenditInvoiceCallback
+	var token string
+	r := gin.New()
+	r.POST("/webhooks/xendit", func(c *gin.Context) {
+		token = c.GetHeader("x-callback-token")
+		if err := json.NewDecoder(c.Request.Body).Decode(&received); err != nil {
+			t.Fatal(err)
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "success", "message": "webhook processed"})
+	})
+
+	provider := NewFakePaymentProvider("http://localhost:8080")
+	provider.SetCallbackHandler(r)
+
+	booking := &models.Booking{ID: uuid.New(), InvoiceNumber: "INV-TEST", TotalAmount: money.FromMajor(50000)}
+	inv, err := provider.CreateInvoice(booking, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := provider.Pay(inv.InvoiceID); err != nil {
+		t.Fatalf("Pay returned error: %v", err)
+	}
+
+	if token != "test-token" {
+		t.Errorf("Callback sent wrong token: got %q", token)
+	}
+	if received.ID != inv.InvoiceID || received.ExternalID != booking.ID.String() || received.Status != models.XenditStatusPaid {
+		t.Errorf("Callback has unexpected payload: %+v", received)
+	}
+
+	// A paid invoice can't be paid again
+	if err := provider.Pay(inv.InvoiceID); err == nil {
+		t.Error("Expected error when paying an already paid invoice")
+	}
+}
+
+func TestFakePaymentProviderRejectedCallbackKeepsInvoicePending(t *testing.T) {
+	r := gin.New()
+	r.POST("/webhooks/xendit", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "error", "message": "unauthorized"})
+	})
+
+	provider := NewFakePaymentProvider("http://localhost:8080")
+	provider.SetCallbackHandler(r)
+
+	inv, err := provider.CreateInvoice(&models.Booking{ID: uuid.New(), TotalAmount: money.FromMajor(50000)}, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := provider.Pay(inv.InvoiceID); err == nil {
+		t.Fatal("Expected error when the callback is rejected")
+	}
+
+	got, err := provider.GetInvoice(inv.InvoiceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != "PENDING" {
+		t.Errorf("Invoice status: got %v want PENDING", got.Status)
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"absolutcinema-backend/internal/models"
+)
+
+const (
+	// DefaultSeatHoldTTL is used when SEAT_HOLD_TTL_MINUTES is not set
+	DefaultSeatHoldTTL = 10 * time.Minute
+)
+
+// HoldService handles temporary seat holds placed before a booking is created
+type HoldService struct {
+	db *gorm.DB
+}
+
+// CreateHoldRequest represents the request to hold seats for a showtime
+type CreateHoldRequest struct {
+	SeatNumbers []string `json:"seat_numbers" binding:"required,min=1"`
+}
+
+// HoldResult represents an active seat hold
+type HoldResult struct {
+	HoldID      uuid.UUID `json:"hold_id"`
+	ShowtimeID  uint      `json:"showtime_id"`
+	SeatNumbers []string  `json:"seat_numbers"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// NewHoldService creates a new hold service
+func NewHoldService(db *gorm.DB) *HoldService {
+	return &HoldService{db: db}
+}
+
+// CreateHold locks the given seats for the configured TTL.
+// userID may be nil for customers who have not logged in yet; such holds can be
+// claimed by whoever presents the hold ID when creating the booking.
+func (hs *HoldService) CreateHold(showtimeID uint, userID *uuid.UUID, seatNumbers []string) (*HoldResult, error) {
+	// 1. Validate showtime exists and has not started
+	var showtime models.Showtime
+	if err := hs.db.First(&showtime, showtimeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("showtime not found")
+		}
+		return nil, fmt.Errorf("failed to fetch showtime: %w", err)
+	}
+
+	if showtime.StartTime.Before(time.Now()) {
+		return nil, errors.New("cannot hold seats for a showtime that has already started")
+	}
+
+	// 2. Validate seat numbers against the studio's seat map
+	if err := validateSeatsAgainstLayout(hs.db, showtime.StudioID, seatNumbers); err != nil {
+		return nil, err
+	}
+
+	uniqueSeats := removeDuplicateSeats(seatNumbers)
+	holdID := uuid.New()
+	expiresAt := time.Now().Add(getSeatHoldTTL())
+
+	err := hs.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSeats(tx, showtimeID, uniqueSeats); err != nil {
+			return err
+		}
+
+		// Expired holds no longer block anything; clear them so the unique index accepts new rows
+		if err := tx.Where("showtime_id = ? AND seat_number IN ? AND expires_at <= ?", showtimeID, uniqueSeats, time.Now()).
+			Delete(&models.SeatHold{}).Error; err != nil {
+			return fmt.Errorf("failed to clear expired holds: %w", err)
+		}
+
+		// Seats already sold cannot be held
+		var taken []string
+		if err := tx.Model(&models.Ticket{}).
+			Where("showtime_id = ? AND seat_number IN ?", showtimeID, uniqueSeats).
+			Pluck("seat_number", &taken).Error; err != nil {
+			return fmt.Errorf("failed to check seat availability: %w", err)
+		}
+		if len(taken) > 0 {
+			return &SeatConflictError{SeatNumber: taken[0]}
+		}
+
+		for _, seatNumber := range uniqueSeats {
+			hold := models.SeatHold{
+				HoldID:     holdID,
+				UserID:     userID,
+				ShowtimeID: showtimeID,
+				SeatNumber: seatNumber,
+				ExpiresAt:  expiresAt,
+			}
+
+			if err := tx.Create(&hold).Error; err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == PgUniqueViolationCode {
+					return &SeatConflictError{SeatNumber: seatNumber}
+				}
+				return fmt.Errorf("failed to hold seat %s: %w", seatNumber, err)
+			}
+		}
+
+		return publishSeatEvent(tx, SeatEventTaken, SeatReasonHeld, showtimeID, uniqueSeats)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &HoldResult{
+		HoldID:      holdID,
+		ShowtimeID:  showtimeID,
+		SeatNumbers: uniqueSeats,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// GetHold retrieves an active (non-expired) hold by ID
+func (hs *HoldService) GetHold(holdID uuid.UUID) (*HoldResult, error) {
+	holds, err := findActiveHold(hs.db, holdID)
+	if err != nil {
+		return nil, err
+	}
+	return toHoldResult(holds), nil
+}
+
+// ReleaseHold releases a hold before it expires.
+// Holds placed by a logged-in user can only be released by that user.
+func (hs *HoldService) ReleaseHold(holdID uuid.UUID, userID *uuid.UUID) error {
+	holds, err := findActiveHold(hs.db, holdID)
+	if err != nil {
+		return err
+	}
+
+	owner := holds[0].UserID
+	if owner != nil && (userID == nil || *owner != *userID) {
+		return errors.New("hold not found")
+	}
+
+	return hs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("hold_id = ?", holdID).Delete(&models.SeatHold{}).Error; err != nil {
+			return fmt.Errorf("failed to release hold: %w", err)
+		}
+
+		hold := toHoldResult(holds)
+		return publishSeatEvent(tx, SeatEventReleased, SeatReasonCancelled, hold.ShowtimeID, hold.SeatNumbers)
+	})
+}
+
+// PurgeExpiredHolds deletes expired hold rows, announces the freed seats,
+// and returns how many rows were removed
+func (hs *HoldService) PurgeExpiredHolds() (int64, error) {
+	var purged []models.SeatHold
+	err := hs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Returning{}).
+			Where("expires_at <= ?", time.Now()).
+			Delete(&purged).Error; err != nil {
+			return fmt.Errorf("failed to purge expired holds: %w", err)
+		}
+
+		seatsByShowtime := make(map[uint][]string)
+		for _, h := range purged {
+			seatsByShowtime[h.ShowtimeID] = append(seatsByShowtime[h.ShowtimeID], h.SeatNumber)
+		}
+		for showtimeID, seats := range seatsByShowtime {
+			if err := publishSeatEvent(tx, SeatEventReleased, SeatReasonExpired, showtimeID, seats); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
+
+// findActiveHold loads all seat rows for a hold that has not expired yet
+func findActiveHold(db *gorm.DB, holdID uuid.UUID) ([]models.SeatHold, error) {
+	var holds []models.SeatHold
+	if err := db.Where("hold_id = ? AND expires_at > ?", holdID, time.Now()).
+		Order("seat_number ASC").
+		Find(&holds).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch hold: %w", err)
+	}
+
+	if len(holds) == 0 {
+		return nil, errors.New("hold not found")
+	}
+
+	return holds, nil
+}
+
+// toHoldResult collapses per-seat hold rows into a single result
+func toHoldResult(holds []models.SeatHold) *HoldResult {
+	seats := make([]string, len(holds))
+	for i, h := range holds {
+		seats[i] = h.SeatNumber
+	}
+
+	return &HoldResult{
+		HoldID:      holds[0].HoldID,
+		ShowtimeID:  holds[0].ShowtimeID,
+		SeatNumbers: seats,
+		ExpiresAt:   holds[0].ExpiresAt,
+	}
+}
+
+// lockSeats takes transaction-scoped advisory locks on each (showtime, seat) pair.
+// Both hold creation and ticket creation take these locks, so a hold and a ticket
+// for the same seat can never be written concurrently.
+func lockSeats(tx *gorm.DB, showtimeID uint, seatNumbers []string) error {
+	// Lock in a stable order to avoid deadlocks between overlapping requests
+	sorted := append([]string(nil), seatNumbers...)
+	sort.Strings(sorted)
+
+	for _, seat := range sorted {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", int32(showtimeID), seat).Error; err != nil {
+			return fmt.Errorf("failed to lock seat %s: %w", seat, err)
+		}
+	}
+	return nil
+}
+
+// getSeatHoldTTL returns how long a seat hold stays valid
+func getSeatHoldTTL() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("SEAT_HOLD_TTL_MINUTES"))
+	if err != nil || minutes <= 0 {
+		return DefaultSeatHoldTTL
+	}
+	return time.Duration(minutes) * time.Minute
+}
@@ -0,0 +1,373 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+// LedgerService reports on the double-entry ledger. Postings are made by the
+// booking, payment and refund flows inside their own transactions.
+type LedgerService struct {
+	db *gorm.DB
+}
+
+// AccountBalance is an account's movement over a date range. Balances are
+// debit-positive: assets and expenses are positive, revenue and liabilities negative.
+type AccountBalance struct {
+	Account string      `json:"account"`
+	Opening money.Money `json:"opening"`
+	Debits  money.Money `json:"debits"`
+	Credits money.Money `json:"credits"`
+	Closing money.Money `json:"closing"`
+}
+
+// TrialBalanceLine is one account of a trial balance; only one side is non-zero
+type TrialBalanceLine struct {
+	Account string      `json:"account"`
+	Debit   money.Money `json:"debit"`
+	Credit  money.Money `json:"credit"`
+}
+
+// TrialBalance lists the net balance of every account over a date range
+type TrialBalance struct {
+	From        *time.Time         `json:"from,omitempty"`
+	To          *time.Time         `json:"to,omitempty"`
+	Lines       []TrialBalanceLine `json:"lines"`
+	TotalDebit  money.Money        `json:"total_debit"`
+	TotalCredit money.Money        `json:"total_credit"`
+	Balanced    bool               `json:"balanced"`
+}
+
+// ledgerLine is one line of a posting; zero lines are dropped
+type ledgerLine struct {
+	account string
+	debit   money.Money
+	credit  money.Money
+}
+
+// NewLedgerService creates a new ledger service
+func NewLedgerService(db *gorm.DB) *LedgerService {
+	return &LedgerService{db: db}
+}
+
+// GetBalances returns each account's opening balance at from, its debits and credits
+// in [from, to) and its closing balance at to. Either bound may be nil.
+func (s *LedgerService) GetBalances(from, to *time.Time) ([]AccountBalance, error) {
+	// Without a start, everything is movement and nothing is opening balance
+	var start time.Time
+	if from != nil {
+		start = *from
+	}
+
+	query := s.db.Model(&models.LedgerEntry{}).
+		Select(`account,
+			COALESCE(SUM(CASE WHEN occurred_at < ? THEN debit - credit ELSE 0 END), 0)::bigint AS opening,
+			COALESCE(SUM(CASE WHEN occurred_at >= ? THEN debit ELSE 0 END), 0)::bigint AS debits,
+			COALESCE(SUM(CASE WHEN occurred_at >= ? THEN credit ELSE 0 END), 0)::bigint AS credits,
+			COALESCE(SUM(debit - credit), 0)::bigint AS closing`, start, start, start).
+		Group("account").
+		Order("account ASC")
+	if to != nil {
+		query = query.Where("occurred_at < ?", *to)
+	}
+
+	var balances []AccountBalance
+	if err := query.Scan(&balances).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute balances: %w", err)
+	}
+	return balances, nil
+}
+
+// GetTrialBalance nets every account's entries in [from, to). Without from it is the
+// trial balance as of to. Every posting is balanced, so the totals always agree;
+// Balanced is false only if the ledger was tampered with.
+func (s *LedgerService) GetTrialBalance(from, to *time.Time) (*TrialBalance, error) {
+	query := s.db.Model(&models.LedgerEntry{}).
+		Select("account, COALESCE(SUM(debit - credit), 0)::bigint AS debit").
+		Group("account").
+		Order("account ASC")
+	if from != nil {
+		query = query.Where("occurred_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("occurred_at < ?", *to)
+	}
+
+	var nets []TrialBalanceLine
+	if err := query.Scan(&nets).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute trial balance: %w", err)
+	}
+
+	tb := &TrialBalance{From: from, To: to, Lines: make([]TrialBalanceLine, 0, len(nets))}
+	for _, net := range nets {
+		line := TrialBalanceLine{Account: net.Account}
+		switch {
+		case net.Debit.IsPositive():
+			line.Debit = net.Debit
+		case net.Debit.IsNegative():
+			line.Credit = net.Debit.Neg()
+		default:
+			continue
+		}
+		tb.Lines = append(tb.Lines, line)
+		var err error
+		if tb.TotalDebit, err = tb.TotalDebit.Add(line.Debit); err != nil {
+			return nil, err
+		}
+		if tb.TotalCredit, err = tb.TotalCredit.Add(line.Credit); err != nil {
+			return nil, err
+		}
+	}
+	tb.Balanced = tb.TotalDebit.Equal(tb.TotalCredit)
+	return tb, nil
+}
+
+// postLedger records a balanced transaction. Each event is posted once per reference;
+// posting it again is a no-op, so callers can post from retried or replayed flows.
+func postLedger(tx *gorm.DB, event, reference string, bookingID *uuid.UUID, description string, occurredAt time.Time, lines []ledgerLine) error {
+	var debits, credits money.Money
+	entries := make([]models.LedgerEntry, 0, len(lines))
+	for _, l := range lines {
+		if l.debit.IsZero() && l.credit.IsZero() {
+			continue
+		}
+		if l.debit.IsNegative() || l.credit.IsNegative() {
+			return fmt.Errorf("ledger %s %s: negative amount on %s", event, reference, l.account)
+		}
+		var err error
+		if debits, err = debits.Add(l.debit); err != nil {
+			return fmt.Errorf("ledger %s %s: %w", event, reference, err)
+		}
+		if credits, err = credits.Add(l.credit); err != nil {
+			return fmt.Errorf("ledger %s %s: %w", event, reference, err)
+		}
+		entries = append(entries, models.LedgerEntry{
+			Account:    l.account,
+			Debit:      l.debit,
+			Credit:     l.credit,
+			OccurredAt: occurredAt,
+		})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if !debits.Equal(credits) {
+		return fmt.Errorf("ledger %s %s is unbalanced: debits %s, credits %s", event, reference, debits, credits)
+	}
+
+	txn := models.LedgerTransaction{
+		BookingID:   bookingID,
+		Event:       event,
+		Reference:   reference,
+		Description: description,
+		OccurredAt:  occurredAt,
+	}
+	result := tx.Omit("Entries").Clauses(clause.OnConflict{DoNothing: true}).Create(&txn)
+	if result.Error != nil {
+		return fmt.Errorf("failed to post ledger transaction: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	for i := range entries {
+		entries[i].TransactionID = txn.ID
+	}
+	if err := tx.Create(&entries).Error; err != nil {
+		return fmt.Errorf("failed to post ledger entries: %w", err)
+	}
+	return nil
+}
+
+// ticketsGross is the list price of a booking's tickets: what was charged plus the
+// discount, less the concession items (which must be loaded)
+func ticketsGross(booking *models.Booking) (money.Money, error) {
+	gross, err := money.Sum(booking.TotalAmount, booking.DiscountAmount)
+	if err != nil {
+		return money.Money{}, err
+	}
+	for _, item := range booking.Items {
+		if gross, err = gross.Sub(item.TotalPrice); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return gross, nil
+}
+
+// studioRevenueAccount is the revenue account of a studio
+func studioRevenueAccount(studioID uint) string {
+	return models.AccountRevenuePrefix + strconv.FormatUint(uint64(studioID), 10)
+}
+
+// postBookingCreated records a sale: the customer owes the total, the discount is
+// what the promo code took off, the studio earns the tickets' list price and
+// concessions earn the items
+func postBookingCreated(tx *gorm.DB, booking *models.Booking, revenueAccount string, itemsTotal money.Money) error {
+	tickets, err := money.Sum(booking.TotalAmount, booking.DiscountAmount, itemsTotal.Neg())
+	if err != nil {
+		return err
+	}
+	return postLedger(tx, models.LedgerEventBookingCreated, booking.ID.String(), &booking.ID,
+		"Booking "+booking.InvoiceNumber, booking.CreatedAt, []ledgerLine{
+			{account: models.AccountReceivables, debit: booking.TotalAmount},
+			{account: models.AccountDiscounts, debit: booking.DiscountAmount},
+			{account: revenueAccount, credit: tickets},
+			{account: models.AccountConcessionRevenue, credit: itemsTotal},
+		})
+}
+
+// bookingSaleEntries returns the entries a booking's sale was posted with.
+// Bookings made before the ledger existed are posted first, so later postings have
+// a sale to offset.
+func bookingSaleEntries(tx *gorm.DB, booking *models.Booking) ([]models.LedgerEntry, error) {
+	var entries []models.LedgerEntry
+	err := tx.Model(&models.LedgerEntry{}).
+		Joins("JOIN ledger_transactions ON ledger_transactions.id = ledger_entries.transaction_id").
+		Where("ledger_transactions.event = ? AND ledger_transactions.reference = ?", models.LedgerEventBookingCreated, booking.ID.String()).
+		Order("ledger_entries.id ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch booking sale: %w", err)
+	}
+	if len(entries) > 0 {
+		return entries, nil
+	}
+
+	// Studio 0 collects sales whose seats were already released
+	var studioID uint
+	showtime, err := bookingShowtime(tx, booking.ID)
+	if err == nil {
+		studioID = showtime.StudioID
+	} else if err.Error() != "booking has no tickets" {
+		return nil, err
+	}
+
+	var itemsTotal money.Money
+	if err := tx.Model(&models.BookingItem{}).
+		Select("COALESCE(SUM(total_price), 0)::bigint").
+		Where("booking_id = ?", booking.ID).
+		Scan(&itemsTotal).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum booking items: %w", err)
+	}
+
+	if err := postBookingCreated(tx, booking, studioRevenueAccount(studioID), itemsTotal); err != nil {
+		return nil, err
+	}
+	return bookingSaleEntries(tx, booking)
+}
+
+// postBookingReleased reverses the sale of a booking that expired or was cancelled unpaid.
+// A sale is only reversed once, whichever way the booking was released.
+func postBookingReleased(tx *gorm.DB, booking *models.Booking, event string) error {
+	var released int64
+	if err := tx.Model(&models.LedgerTransaction{}).
+		Where("reference = ? AND event IN ?", booking.ID.String(),
+			[]string{models.LedgerEventBookingExpired, models.LedgerEventBookingCancelled}).
+		Count(&released).Error; err != nil {
+		return fmt.Errorf("failed to check booking release: %w", err)
+	}
+	if released > 0 {
+		return nil
+	}
+
+	sale, err := bookingSaleEntries(tx, booking)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]ledgerLine, 0, len(sale))
+	for _, e := range sale {
+		lines = append(lines, ledgerLine{account: e.Account, debit: e.Credit, credit: e.Debit})
+	}
+	return postLedger(tx, event, booking.ID.String(), &booking.ID,
+		"Booking "+booking.InvoiceNumber, time.Now(), lines)
+}
+
+// postPaymentReceived records a paid invoice and the gateway's fee. It is posted for
+// every paid invoice, including ones put in review, so the ledger shows all money received.
+func postPaymentReceived(tx *gorm.DB, booking *models.Booking, payload *models.XenditInvoiceCallback) error {
+	if _, err := bookingSaleEntries(tx, booking); err != nil {
+		return err
+	}
+
+	paid := payload.PaidAmount
+	if paid.IsZero() {
+		paid = payload.Amount
+	}
+	occurredAt := time.Now()
+	if payload.PaidAt != nil {
+		occurredAt = *payload.PaidAt
+	}
+
+	return postLedger(tx, models.LedgerEventPaymentReceived, payload.ID, &booking.ID,
+		"Invoice "+payload.ID, occurredAt, []ledgerLine{
+			{account: models.AccountGatewayCash, debit: paid},
+			{account: models.AccountReceivables, credit: paid},
+			{account: models.AccountGatewayFees, debit: payload.FeesPaidAmount},
+			{account: models.AccountGatewayCash, credit: payload.FeesPaidAmount},
+		})
+}
+
+// postRefundIssued moves a granted refund out of the booking's revenue into refunds payable.
+// A booking with concessions has the refund split across its revenue accounts in
+// proportion to what each earned.
+func postRefundIssued(tx *gorm.DB, booking *models.Booking, refund *models.Refund) error {
+	sale, err := bookingSaleEntries(tx, booking)
+	if err != nil {
+		return err
+	}
+
+	var revenue []models.LedgerEntry
+	var gross money.Money
+	for _, e := range sale {
+		if e.Credit.IsPositive() {
+			revenue = append(revenue, e)
+			if gross, err = gross.Add(e.Credit); err != nil {
+				return err
+			}
+		}
+	}
+	if len(revenue) == 0 {
+		return fmt.Errorf("booking %s has no revenue to refund", booking.ID)
+	}
+
+	lines := make([]ledgerLine, 0, len(revenue)+1)
+	remaining := refund.Amount
+	for i, e := range revenue {
+		share := remaining
+		if i < len(revenue)-1 {
+			share = refund.Amount.MulRatio(e.Credit.Minor(), gross.Minor())
+			if remaining, err = remaining.Sub(share); err != nil {
+				return err
+			}
+		}
+		lines = append(lines, ledgerLine{account: e.Account, debit: share})
+	}
+	lines = append(lines, ledgerLine{account: models.AccountRefundsPayable, credit: refund.Amount})
+
+	return postLedger(tx, models.LedgerEventRefundIssued, strconv.FormatUint(uint64(refund.ID), 10), &booking.ID,
+		"Refund of booking "+booking.InvoiceNumber, time.Now(), lines)
+}
+
+// postRefundPaid records that the gateway paid a refund out. A refund that fails at
+// the gateway stays in refunds payable until staff pay it by hand.
+func postRefundPaid(tx *gorm.DB, refund *models.Refund) error {
+	if refund.ID == 0 {
+		return errors.New("refund has not been recorded")
+	}
+
+	return postLedger(tx, models.LedgerEventRefundPaid, strconv.FormatUint(uint64(refund.ID), 10), &refund.BookingID,
+		"Refund "+refund.ProviderRefundID, time.Now(), []ledgerLine{
+			{account: models.AccountRefundsPayable, debit: refund.Amount},
+			{account: models.AccountGatewayCash, credit: refund.Amount},
+		})
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSMTPHost and DefaultSMTPPort point at a local MailHog, which captures
+	// everything sent in development (web UI on port 8025)
+	DefaultSMTPHost = "localhost"
+	DefaultSMTPPort = 1025
+
+	DefaultMailFrom = "AbsolutCinema <no-reply@absolutcinema.local>"
+)
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// EmailMessage is an email ready to be sent
+type EmailMessage struct {
+	MessageID   string
+	To          string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []EmailAttachment
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(msg *EmailMessage) error
+}
+
+// SMTPMailer sends emails through an SMTP server, upgrading to TLS when the server offers it
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPMailer creates a mailer from SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD
+// and MAIL_FROM. Without them it delivers to a local MailHog.
+func NewSMTPMailer() *SMTPMailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		host = DefaultSMTPHost
+	}
+	port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil || port <= 0 {
+		port = DefaultSMTPPort
+	}
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = DefaultMailFrom
+	}
+
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		username: os.Getenv("SMTP_USERNAME"),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     from,
+	}
+}
+
+// Send implements Mailer
+func (m *SMTPMailer) Send(msg *EmailMessage) error {
+	from, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid MAIL_FROM: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	body, err := buildMIMEMessage(from, to, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	return smtp.SendMail(m.addr, auth, from.Address, []string{to.Address}, body)
+}
+
+// buildMIMEMessage encodes msg as a multipart/alternative text and HTML email, wrapped
+// in multipart/mixed when it has attachments
+func buildMIMEMessage(from, to *mail.Address, msg *EmailMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	domain := "absolutcinema.local"
+	if at := strings.LastIndex(from.Address, "@"); at >= 0 {
+		domain = from.Address[at+1:]
+	}
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", msg.MessageID, domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	alternative := func(w *multipart.Writer) error {
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain; charset=utf-8", msg.Text},
+			{"text/html; charset=utf-8", msg.HTML},
+		} {
+			pw, err := w.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return err
+			}
+			qp := quotedprintable.NewWriter(pw)
+			if _, err := qp.Write([]byte(part.body)); err != nil {
+				return err
+			}
+			if err := qp.Close(); err != nil {
+				return err
+			}
+		}
+		return w.Close()
+	}
+
+	if len(msg.Attachments) == 0 {
+		w := multipart.NewWriter(&buf)
+		fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", w.Boundary())
+		if err := alternative(w); err != nil {
+			return nil, fmt.Errorf("failed to encode email: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mixed.Boundary())
+
+	var inner bytes.Buffer
+	alt := multipart.NewWriter(&inner)
+	if err := alternative(alt); err != nil {
+		return nil, fmt.Errorf("failed to encode email: %w", err)
+	}
+	pw, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {fmt.Sprintf("multipart/alternative; boundary=%q", alt.Boundary())},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := pw.Write(inner.Bytes()); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range msg.Attachments {
+		pw, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(attachment.ContentType, map[string]string{"name": attachment.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+		// Lines of base64 must not exceed 76 characters
+		for len(encoded) > 76 {
+			fmt.Fprintf(pw, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(pw, "%s\r\n", encoded)
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode email: %w", err)
+	}
+	return buf.Bytes(), nil
+}
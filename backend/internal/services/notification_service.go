@@ -0,0 +1,299 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"os"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+)
+
+const (
+	// MaxEmailAttempts is how often delivery is tried before an email is marked FAILED
+	MaxEmailAttempts = 8
+
+	// EmailBatchSize is how many due emails one dispatcher pass sends
+	EmailBatchSize = 50
+)
+
+//go:embed email_templates/*.tmpl
+var emailTemplateFS embed.FS
+
+var emailTemplateFuncs = map[string]interface{}{
+	"join": strings.Join,
+	"button": func(url, label string) map[string]string {
+		return map[string]string{"URL": url, "Label": label}
+	},
+}
+
+var (
+	textEmailTemplates = texttemplate.Must(texttemplate.New("emails").Funcs(emailTemplateFuncs).
+				ParseFS(emailTemplateFS, "email_templates/emails.txt.tmpl"))
+	htmlEmailTemplates = htmltemplate.Must(htmltemplate.New("emails").Funcs(emailTemplateFuncs).
+				ParseFS(emailTemplateFS, "email_templates/emails.html.tmpl"))
+)
+
+// emailSubjects are the subject lines per email kind; %s is the movie title
+var emailSubjects = map[string]string{
+	models.EmailBookingCreated:   "Complete your payment for %s",
+	models.EmailPaymentConfirmed: "Your tickets for %s",
+	models.EmailBookingExpired:   "Your booking for %s has expired",
+	models.EmailBookingCancelled: "Your booking for %s was cancelled",
+	models.EmailShowtimeChanged:  "Showtime changed: %s",
+	models.EmailShowtimeReminder: "Reminder: %s",
+}
+
+// emailData is what the email templates print
+type emailData struct {
+	Name            string
+	BookingID       string
+	InvoiceNumber   string
+	Movie           string
+	Studio          string
+	Start           string
+	Seats           []string
+	Items           []string
+	Total           string
+	PaymentURL      string
+	PaymentDeadline string
+	Refund          string
+	PreviousStart   string
+	PreviousStudio  string
+	Lead            string
+	AccountURL      string
+}
+
+// NotificationService delivers the emails queued in the outbox
+type NotificationService struct {
+	db             *gorm.DB
+	mailer         Mailer
+	bookingService *BookingService
+}
+
+// NewNotificationService creates a new notification service.
+// bookingService renders the documents attached to emails.
+func NewNotificationService(db *gorm.DB, mailer Mailer, bookingService *BookingService) *NotificationService {
+	return &NotificationService{
+		db:             db,
+		mailer:         mailer,
+		bookingService: bookingService,
+	}
+}
+
+// loadEmailData gathers what a booking's emails print. Tickets are deleted when a booking
+// is released, so this must run before releaseTickets in the same transaction.
+func loadEmailData(tx *gorm.DB, bookingID uuid.UUID) (*emailData, string, error) {
+	var booking models.Booking
+	if err := tx.Preload("Tickets").Preload("Items").First(&booking, "id = ?", bookingID).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch booking: %w", err)
+	}
+	var user models.User
+	if err := tx.Select("username", "email").First(&user, "id = ?", booking.UserID).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	data := &emailData{
+		Name:          user.Username,
+		BookingID:     booking.ID.String(),
+		InvoiceNumber: booking.InvoiceNumber,
+		Total:         booking.TotalAmount.String(),
+		PaymentURL:    booking.PaymentURL,
+		AccountURL:    getAccountURL(),
+	}
+	if booking.PaymentExpiresAt != nil {
+		data.PaymentDeadline = booking.PaymentExpiresAt.Local().Format(documentTimeFormat)
+	}
+	for _, ticket := range booking.Tickets {
+		data.Seats = append(data.Seats, ticket.SeatNumber)
+	}
+	for _, item := range booking.Items {
+		data.Items = append(data.Items, fmt.Sprintf("%dx %s", item.Quantity, item.ProductName))
+	}
+
+	if len(booking.Tickets) > 0 {
+		var showtime models.Showtime
+		if err := tx.Preload("Movie").Preload("Studio").First(&showtime, booking.Tickets[0].ShowtimeID).Error; err != nil {
+			return nil, "", fmt.Errorf("failed to fetch showtime: %w", err)
+		}
+		data.Movie = showtime.Movie.Title
+		data.Studio = showtime.Studio.Name
+		data.Start = showtime.StartTime.Local().Format(documentTimeFormat)
+	}
+
+	return data, user.Email, nil
+}
+
+// renderEmail renders the subject, text and HTML body of an email kind
+func renderEmail(kind string, data *emailData) (subject, text, html string, err error) {
+	format, ok := emailSubjects[kind]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown email %s", kind)
+	}
+	about := data.Movie
+	if about == "" {
+		about = "booking " + data.InvoiceNumber
+	}
+	subject = fmt.Sprintf(format, about)
+
+	var textBody, htmlBody bytes.Buffer
+	if err := textEmailTemplates.ExecuteTemplate(&textBody, kind, data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render %s text: %w", kind, err)
+	}
+	if err := htmlEmailTemplates.ExecuteTemplate(&htmlBody, kind, data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render %s html: %w", kind, err)
+	}
+	return subject, textBody.String(), htmlBody.String(), nil
+}
+
+// enqueueBookingEmail renders an email about a booking and writes it to the outbox.
+// Must be called in the transaction that makes the change it announces; customize
+// fills in what only the caller knows.
+func enqueueBookingEmail(tx *gorm.DB, kind string, bookingID uuid.UUID, customize func(*emailData)) error {
+	data, recipient, err := loadEmailData(tx, bookingID)
+	if err != nil {
+		return err
+	}
+	if customize != nil {
+		customize(data)
+	}
+
+	subject, text, html, err := renderEmail(kind, data)
+	if err != nil {
+		return err
+	}
+
+	email := models.EmailOutbox{
+		Kind:          kind,
+		BookingID:     &bookingID,
+		Recipient:     recipient,
+		Subject:       subject,
+		TextBody:      text,
+		HTMLBody:      html,
+		Status:        models.EmailStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if kind == models.EmailPaymentConfirmed {
+		email.Attachment = DocumentTicket
+	}
+	if err := tx.Create(&email).Error; err != nil {
+		return fmt.Errorf("failed to queue %s email: %w", kind, err)
+	}
+	return nil
+}
+
+// DeliverPendingEmails sends the outbox emails that are due. A failed delivery is
+// retried with exponential backoff until MaxEmailAttempts, then marked FAILED.
+// Returns the number of emails sent.
+func (ns *NotificationService) DeliverPendingEmails(ctx context.Context) (int, error) {
+	var emails []models.EmailOutbox
+	err := ns.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.EmailStatusPending, time.Now()).
+		Order("next_attempt_at ASC").
+		Limit(EmailBatchSize).
+		Find(&emails).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch pending emails: %w", err)
+	}
+
+	sent := 0
+	for i := range emails {
+		if ctx.Err() != nil {
+			break
+		}
+
+		email := &emails[i]
+		updates := map[string]interface{}{"attempts": email.Attempts + 1}
+		if err := ns.mailer.Send(ns.buildMessage(email)); err != nil {
+			log.Printf("[Notifications] Failed to send email %d (%s) to %s: %v", email.ID, email.Kind, email.Recipient, err)
+			updates["last_error"] = err.Error()
+			if email.Attempts+1 >= MaxEmailAttempts {
+				updates["status"] = models.EmailStatusFailed
+			} else {
+				updates["next_attempt_at"] = time.Now().Add(emailRetryDelay(email.Attempts + 1))
+			}
+		} else {
+			updates["status"] = models.EmailStatusSent
+			updates["sent_at"] = time.Now()
+			updates["last_error"] = ""
+			sent++
+		}
+
+		if err := ns.db.Model(email).Updates(updates).Error; err != nil {
+			// A sent email may go out again on the next pass; better than losing a failed one
+			log.Printf("[Notifications] Failed to record delivery of email %d: %v", email.ID, err)
+		}
+	}
+
+	return sent, nil
+}
+
+// buildMessage turns an outbox row into a message, rendering its attachment
+func (ns *NotificationService) buildMessage(email *models.EmailOutbox) *EmailMessage {
+	msg := &EmailMessage{
+		MessageID: fmt.Sprintf("outbox-%d.%d", email.ID, email.CreatedAt.Unix()),
+		To:        email.Recipient,
+		Subject:   email.Subject,
+		Text:      email.TextBody,
+		HTML:      email.HTMLBody,
+	}
+
+	if email.Attachment != "" && email.BookingID != nil {
+		doc, err := ns.bookingDocument(email.Attachment, *email.BookingID)
+		if err != nil {
+			// The booking may have been refunded since; the email is still worth sending
+			log.Printf("[Notifications] Sending email %d without its %s: %v", email.ID, email.Attachment, err)
+		} else {
+			msg.Attachments = append(msg.Attachments, EmailAttachment{
+				Filename:    doc.Filename,
+				ContentType: "application/pdf",
+				Data:        doc.Data,
+			})
+		}
+	}
+	return msg
+}
+
+// bookingDocument renders a booking document on behalf of the booking's owner
+func (ns *NotificationService) bookingDocument(kind string, bookingID uuid.UUID) (*BookingDocument, error) {
+	if ns.bookingService == nil {
+		return nil, errors.New("documents are not available")
+	}
+	var booking models.Booking
+	if err := ns.db.Select("id", "user_id").First(&booking, "id = ?", bookingID).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch booking: %w", err)
+	}
+	return ns.bookingService.GetBookingDocument(kind, bookingID, booking.UserID)
+}
+
+// emailRetryDelay is the wait before the next delivery attempt: one minute after the
+// first failure, doubling every time, at most six hours
+func emailRetryDelay(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < 6*time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > 6*time.Hour {
+		delay = 6 * time.Hour
+	}
+	return delay
+}
+
+// getAccountURL returns the frontend page listing the customer's bookings
+func getAccountURL() string {
+	baseURL := os.Getenv("FRONTEND_URL")
+	if baseURL == "" {
+		baseURL = "https://absolut-cinema-umwih.ondigitalocean.app"
+	}
+	return baseURL + "/account"
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"absolutcinema-backend/internal/models"
+)
+
+func TestRenderEmails(t *testing.T) {
+	data := &emailData{
+		Name:          "<b>budi</b>",
+		BookingID:     "0f8fad5b-d9cb-469f-a165-70867728950e",
+		InvoiceNumber: "INV/2026/000042",
+		Movie:         "Amélie",
+		Studio:        "Studio 1",
+		Start:         "Sat, 07 Mar 2026 19:30",
+		Seats:         []string{"A5", "A6"},
+		Items:         []string{"1x Popcorn"},
+		Total:         "IDR 85000",
+		PaymentURL:    "https://checkout.example.com/inv_1",
+		AccountURL:    "https://cinema.example.com/account",
+	}
+
+	for kind := range emailSubjects {
+		subject, text, html, err := renderEmail(kind, data)
+		if err != nil {
+			t.Fatalf("%s: %v", kind, err)
+		}
+		if !strings.Contains(subject, "Amélie") {
+			t.Errorf("%s subject = %q", kind, subject)
+		}
+		if !strings.Contains(text, "A5, A6") || !strings.Contains(html, "A5, A6") {
+			t.Errorf("%s does not list the seats", kind)
+		}
+		if strings.Contains(html, "<b>budi</b>") {
+			t.Errorf("%s html does not escape the customer name", kind)
+		}
+	}
+	if _, _, html, _ := renderEmail(models.EmailBookingCreated, data); !strings.Contains(html, data.PaymentURL) {
+		t.Error("booking created email has no payment link")
+	}
+}
+
+func TestEmailRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{20, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := emailRetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("emailRetryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestBuildMIMEMessageWithAttachment(t *testing.T) {
+	from := &mail.Address{Name: "AbsolutCinema", Address: "no-reply@cinema.example.com"}
+	to := &mail.Address{Address: "customer@example.com"}
+	body, err := buildMIMEMessage(from, to, &EmailMessage{
+		MessageID: "outbox-1.1",
+		To:        to.Address,
+		Subject:   "Your tickets for Amélie",
+		Text:      "plain",
+		HTML:      "<p>html</p>",
+		Attachments: []EmailAttachment{
+			{Filename: "ticket.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.3")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); subject != "Your tickets for Amélie" {
+		t.Errorf("subject = %q", subject)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("content type = %q, %v", mediaType, err)
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		types = append(types, mediaType)
+		if mediaType == "application/pdf" && part.FileName() != "ticket.pdf" {
+			t.Errorf("attachment filename = %q", part.FileName())
+		}
+	}
+	if strings.Join(types, ",") != "multipart/alternative,application/pdf" {
+		t.Errorf("parts = %v", types)
+	}
+}
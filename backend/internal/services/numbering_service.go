@@ -0,0 +1,240 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+)
+
+// numberFormatToken matches one {TOKEN} of a number series format
+var numberFormatToken = regexp.MustCompile(`\{([A-Z]+)(?::(\d+))?\}`)
+
+// billingEntityPattern is what an entity code may look like
+var billingEntityPattern = regexp.MustCompile(`^[A-Z0-9_-]{1,50}$`)
+
+// NumberingService manages the number series used for invoices and credit notes
+type NumberingService struct {
+	db *gorm.DB
+}
+
+// NewNumberingService creates a new numbering service
+func NewNumberingService(db *gorm.DB) *NumberingService {
+	return &NumberingService{db: db}
+}
+
+// GetAllSeries retrieves all number series
+func (s *NumberingService) GetAllSeries() ([]models.NumberSeries, error) {
+	var series []models.NumberSeries
+	if err := s.db.Order("entity ASC, document_type ASC").Find(&series).Error; err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// CreateSeries creates a number series for an entity and document type
+func (s *NumberingService) CreateSeries(series *models.NumberSeries) error {
+	if err := s.validateSeries(0, series); err != nil {
+		return err
+	}
+	return s.db.Create(series).Error
+}
+
+// UpdateSeries changes a series' format or reset period. Numbers already issued keep their
+// old format; the count carries on within the current period.
+func (s *NumberingService) UpdateSeries(id uint, updates *models.NumberSeries) error {
+	var series models.NumberSeries
+	if err := s.db.First(&series, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("number series not found")
+		}
+		return err
+	}
+
+	// The entity and document type identify the series and can't change
+	updates.Entity = series.Entity
+	updates.DocumentType = series.DocumentType
+	if err := s.validateSeries(id, updates); err != nil {
+		return err
+	}
+
+	series.Format = updates.Format
+	series.ResetPeriod = updates.ResetPeriod
+	return s.db.Save(&series).Error
+}
+
+// validateSeries normalizes and checks a series; excludeID is the series being updated
+func (s *NumberingService) validateSeries(excludeID uint, series *models.NumberSeries) error {
+	series.Entity = strings.ToUpper(strings.TrimSpace(series.Entity))
+	if !billingEntityPattern.MatchString(series.Entity) {
+		return errors.New("entity must be 1-50 letters, digits, '-' or '_'")
+	}
+
+	series.DocumentType = strings.ToUpper(strings.TrimSpace(series.DocumentType))
+	switch series.DocumentType {
+	case models.DocumentTypeInvoice, models.DocumentTypeCreditNote:
+	default:
+		return errors.New("document_type must be INVOICE or CREDIT_NOTE")
+	}
+
+	series.ResetPeriod = strings.ToUpper(strings.TrimSpace(series.ResetPeriod))
+	if series.ResetPeriod == "" {
+		series.ResetPeriod = models.NumberResetYearly
+	}
+
+	series.Format = strings.TrimSpace(series.Format)
+	if err := validateNumberFormat(series.Format, series.ResetPeriod); err != nil {
+		return err
+	}
+
+	// Invoice numbers are unique, so two series must never be able to produce the same one
+	var count int64
+	if err := s.db.Model(&models.NumberSeries{}).
+		Where("format = ? AND id != ?", series.Format, excludeID).
+		Where("entity = ? OR format NOT LIKE ?", series.Entity, "%{ENTITY}%").
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("another number series already uses this format")
+	}
+
+	return nil
+}
+
+// validateNumberFormat checks that a format has one sequence token, no unknown tokens,
+// and the date tokens needed to keep numbers unique across the reset period
+func validateNumberFormat(format, resetPeriod string) error {
+	if format == "" {
+		return errors.New("format is required")
+	}
+
+	tokens := make(map[string]int)
+	for _, m := range numberFormatToken.FindAllStringSubmatch(format, -1) {
+		switch m[1] {
+		case "ENTITY", "YYYY", "YY", "MM", "DD":
+			if m[2] != "" {
+				return fmt.Errorf("{%s} does not take a width", m[1])
+			}
+		case "SEQ":
+			if width, _ := strconv.Atoi(m[2]); width > 12 {
+				return errors.New("{SEQ} width can be at most 12")
+			}
+		default:
+			return fmt.Errorf("unknown format token {%s}", m[1])
+		}
+		tokens[m[1]]++
+	}
+	if tokens["SEQ"] != 1 {
+		return errors.New("format must contain {SEQ} exactly once")
+	}
+
+	hasYear := tokens["YYYY"] > 0 || tokens["YY"] > 0
+	switch resetPeriod {
+	case models.NumberResetNever:
+	case models.NumberResetYearly:
+		if !hasYear {
+			return errors.New("a yearly series needs {YYYY} or {YY} in its format")
+		}
+	case models.NumberResetMonthly:
+		if !hasYear || tokens["MM"] == 0 {
+			return errors.New("a monthly series needs the year and {MM} in its format")
+		}
+	case models.NumberResetDaily:
+		if !hasYear || tokens["MM"] == 0 || tokens["DD"] == 0 {
+			return errors.New("a daily series needs the year, {MM} and {DD} in its format")
+		}
+	default:
+		return errors.New("reset_period must be NEVER, YEARLY, MONTHLY or DAILY")
+	}
+
+	return nil
+}
+
+// billingEntity returns the entity that numbers a studio's documents
+func billingEntity(studio *models.Studio) string {
+	if entity := strings.ToUpper(strings.TrimSpace(studio.BillingEntity)); entity != "" {
+		return entity
+	}
+	return models.DefaultBillingEntity
+}
+
+// nextDocumentNumber issues the next number of an entity's series for a document type,
+// falling back to the default entity's series. It must run inside the transaction that
+// stores the number: the sequence row stays locked until it commits, and a rollback
+// returns the number, so issued numbers have no gaps.
+func nextDocumentNumber(tx *gorm.DB, entity, documentType string, now time.Time) (string, error) {
+	if entity == "" {
+		entity = models.DefaultBillingEntity
+	}
+
+	var series models.NumberSeries
+	err := tx.Where("entity = ? AND document_type = ?", entity, documentType).First(&series).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) && entity != models.DefaultBillingEntity {
+		err = tx.Where("entity = ? AND document_type = ?", models.DefaultBillingEntity, documentType).First(&series).Error
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("no %s number series is configured", strings.ToLower(documentType))
+		}
+		return "", fmt.Errorf("failed to fetch number series: %w", err)
+	}
+
+	var seq int64
+	err = tx.Raw(`
+		INSERT INTO number_sequences (series_id, period, last_value, updated_at)
+		VALUES (?, ?, 1, NOW())
+		ON CONFLICT (series_id, period)
+		DO UPDATE SET last_value = number_sequences.last_value + 1, updated_at = NOW()
+		RETURNING last_value
+	`, series.ID, numberPeriod(series.ResetPeriod, now)).Scan(&seq).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to take the next %s number: %w", strings.ToLower(documentType), err)
+	}
+
+	return formatDocumentNumber(series.Format, series.Entity, now, seq), nil
+}
+
+// numberPeriod returns the period a number issued at t counts in
+func numberPeriod(resetPeriod string, t time.Time) string {
+	switch resetPeriod {
+	case models.NumberResetYearly:
+		return t.Format("2006")
+	case models.NumberResetMonthly:
+		return t.Format("2006-01")
+	case models.NumberResetDaily:
+		return t.Format("2006-01-02")
+	default:
+		return ""
+	}
+}
+
+// formatDocumentNumber fills in a series format
+func formatDocumentNumber(format, entity string, t time.Time, seq int64) string {
+	return numberFormatToken.ReplaceAllStringFunc(format, func(token string) string {
+		m := numberFormatToken.FindStringSubmatch(token)
+		switch m[1] {
+		case "ENTITY":
+			return entity
+		case "YYYY":
+			return t.Format("2006")
+		case "YY":
+			return t.Format("06")
+		case "MM":
+			return t.Format("01")
+		case "DD":
+			return t.Format("02")
+		case "SEQ":
+			width, _ := strconv.Atoi(m[2])
+			return fmt.Sprintf("%0*d", width, seq)
+		default:
+			return token
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"absolutcinema-backend/internal/models"
+)
+
+func TestFormatDocumentNumber(t *testing.T) {
+	at := time.Date(2026, 3, 7, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"INV/{YYYY}/{SEQ:6}", "INV/2026/000042"},
+		{"{ENTITY}-{YY}{MM}{DD}-{SEQ}", "JKT-260307-42"},
+		{"CN{SEQ:2}", "CN42"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDocumentNumber(tt.format, "JKT", at, 42); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestValidateNumberFormatNeedsPeriodTokens(t *testing.T) {
+	if err := validateNumberFormat("INV/{SEQ}", models.NumberResetYearly); err == nil {
+		t.Error("yearly series without a year was accepted")
+	}
+	if err := validateNumberFormat("INV/{YYYY}/{MM}/{SEQ:5}", models.NumberResetMonthly); err != nil {
+		t.Errorf("valid monthly format rejected: %v", err)
+	}
+	if err := validateNumberFormat("INV/{YYYY}/{NUM}", models.NumberResetYearly); err == nil {
+		t.Error("unknown token was accepted")
+	}
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+// raisePaymentAlert reports a payment problem that needs a human: it is logged
+// and, when Sentry is configured, captured as a warning tagged with the booking
+func raisePaymentAlert(bookingID uuid.UUID, invoiceID, reason string) {
+	message := fmt.Sprintf("Payment alert for booking %s (invoice %s): %s", bookingID, invoiceID, reason)
+	log.Printf("[PaymentAlert] %s", message)
+
+	sentry.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentry.LevelWarning)
+		scope.SetTag("booking_id", bookingID.String())
+		scope.SetTag("invoice_id", invoiceID)
+		sentry.CaptureMessage(message)
+	})
+}
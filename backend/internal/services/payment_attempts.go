@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+)
+
+const (
+	// DefaultPaymentCutoff is used when PAYMENT_CUTOFF_MINUTES is not set
+	DefaultPaymentCutoff = 15 * time.Minute
+
+	// DefaultPaymentWindow is used when PAYMENT_WINDOW_MAX_MINUTES is not set
+	DefaultPaymentWindow = 30 * time.Minute
+)
+
+// paymentDeadline returns when a booking made now for a showtime starting at start must be paid:
+// the payment cutoff before the showtime, but no later than the maximum payment window.
+// Fails once the cutoff has passed.
+func paymentDeadline(start, now time.Time) (time.Time, error) {
+	deadline := start.Add(-getPaymentCutoff())
+	if !deadline.After(now) {
+		return time.Time{}, errors.New("booking for this showtime has closed")
+	}
+	if latest := now.Add(getPaymentWindow()); latest.Before(deadline) {
+		deadline = latest
+	}
+	return deadline.Truncate(time.Second), nil
+}
+
+// getPaymentCutoff returns how long before the showtime payment must be completed
+func getPaymentCutoff() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("PAYMENT_CUTOFF_MINUTES"))
+	if err != nil || minutes < 0 {
+		return DefaultPaymentCutoff
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// getPaymentWindow returns the longest a booking may stay unpaid
+func getPaymentWindow() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("PAYMENT_WINDOW_MAX_MINUTES"))
+	if err != nil || minutes <= 0 {
+		return DefaultPaymentWindow
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// invoiceExpiry returns when a booking's invoice should expire.
+// Bookings from before payment deadlines existed get the old 24 hours.
+func invoiceExpiry(booking *models.Booking) time.Time {
+	if booking.PaymentExpiresAt != nil {
+		return *booking.PaymentExpiresAt
+	}
+	return time.Now().Add(24 * time.Hour)
+}
+
+// openPaymentAttempt expires the booking's older open invoices, creates a new invoice and
+// records it as a payment attempt. The booking's payment_url/payment_id move to the new attempt,
+// so only its callbacks can confirm the booking.
+func (bs *BookingService) openPaymentAttempt(booking *models.Booking, userEmail string) (*models.Payment, error) {
+	if bs.paymentProvider == nil {
+		return nil, errors.New("payment service is not available")
+	}
+	// A new invoice can't extend the booking's deadline, so there must be time left to pay
+	if booking.PaymentExpiresAt != nil && time.Until(*booking.PaymentExpiresAt) < time.Minute {
+		return nil, errors.New("payment deadline for this booking has passed")
+	}
+
+	// If an old invoice can't be expired and is paid anyway, the callback puts the booking in review
+	bs.expireOpenPayments(booking.ID)
+
+	invoiceResult, err := bs.paymentProvider.CreateInvoice(booking, userEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment invoice: %w", err)
+	}
+
+	payment := models.Payment{
+		BookingID:         booking.ID,
+		Provider:          bs.paymentProvider.Name(),
+		ProviderInvoiceID: invoiceResult.InvoiceID,
+		InvoiceURL:        invoiceResult.InvoiceURL,
+		Amount:            booking.TotalAmount,
+		Currency:          invoiceResult.Currency,
+		Status:            models.PaymentStatusPending,
+	}
+	if payment.Currency == "" {
+		payment.Currency = BookingCurrency
+	}
+	if expiry, err := time.Parse(time.RFC3339, invoiceResult.ExpiryDate); err == nil {
+		payment.ExpiresAt = &expiry
+	}
+
+	err = bs.db.Transaction(func(tx *gorm.DB) error {
+		var attempts int64
+		if err := tx.Model(&models.Payment{}).Where("booking_id = ?", booking.ID).Count(&attempts).Error; err != nil {
+			return fmt.Errorf("failed to count payment attempts: %w", err)
+		}
+		if err := tx.Omit("Booking").Create(&payment).Error; err != nil {
+			return fmt.Errorf("failed to record payment attempt: %w", err)
+		}
+		err := tx.Model(&models.Booking{}).Where("id = ?", booking.ID).Updates(map[string]interface{}{
+			"payment_url": invoiceResult.InvoiceURL,
+			"payment_id":  invoiceResult.InvoiceID,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// The booking's first payment link is what the customer is emailed; retries show it on screen
+		if attempts == 0 {
+			return enqueueBookingEmail(tx, models.EmailBookingCreated, booking.ID, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		// The invoice exists but nothing points at it; expire it so it can't be paid
+		if expErr := bs.paymentProvider.ExpireInvoice(invoiceResult.InvoiceID); expErr != nil {
+			log.Printf("[Payments] Could not expire unrecorded invoice %s for booking %s: %v", invoiceResult.InvoiceID, booking.ID, expErr)
+		}
+		return nil, err
+	}
+
+	booking.PaymentURL = invoiceResult.InvoiceURL
+	booking.PaymentID = invoiceResult.InvoiceID
+	return &payment, nil
+}
+
+// expireOpenPayments expires every PENDING payment attempt of a booking at the provider.
+// Returns false if any invoice could not be expired (it may have been paid).
+func (bs *BookingService) expireOpenPayments(bookingID uuid.UUID) bool {
+	var open []models.Payment
+	if err := bs.db.Where("booking_id = ? AND status = ?", bookingID, models.PaymentStatusPending).Find(&open).Error; err != nil {
+		log.Printf("[Payments] Failed to fetch open payment attempts for booking %s: %v", bookingID, err)
+		return false
+	}
+
+	ok := true
+	for _, payment := range open {
+		if bs.paymentProvider != nil {
+			if err := bs.paymentProvider.ExpireInvoice(payment.ProviderInvoiceID); err != nil {
+				log.Printf("[Payments] Could not expire invoice %s for booking %s: %v", payment.ProviderInvoiceID, bookingID, err)
+				ok = false
+				continue
+			}
+		}
+		if err := markPaymentExpired(bs.db, payment.ProviderInvoiceID); err != nil {
+			log.Printf("[Payments] %v", err)
+		}
+	}
+	return ok
+}
+
+// findPaymentAttempt returns the payment attempt for a provider invoice ID, or nil if it is unknown
+func findPaymentAttempt(db *gorm.DB, invoiceID string) (*models.Payment, error) {
+	var payment models.Payment
+	if err := db.Where("provider_invoice_id = ?", invoiceID).First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch payment attempt: %w", err)
+	}
+	return &payment, nil
+}
+
+// markPaymentPaid records a PAID callback on its payment attempt
+func markPaymentPaid(tx *gorm.DB, payload *models.XenditInvoiceCallback) error {
+	paidAt := time.Now()
+	if payload.PaidAt != nil {
+		paidAt = *payload.PaidAt
+	}
+
+	err := tx.Model(&models.Payment{}).
+		Where("provider_invoice_id = ?", payload.ID).
+		Updates(map[string]interface{}{
+			"status":                   models.PaymentStatusPaid,
+			"paid_amount":              payload.PaidAmount,
+			"fees_paid_amount":         payload.FeesPaidAmount,
+			"adjusted_received_amount": payload.AdjustedReceivedAmount,
+			"payment_method":           payload.PaymentMethod,
+			"payment_channel":          payload.PaymentChannel,
+			"paid_at":                  paidAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record payment for invoice %s: %w", payload.ID, err)
+	}
+	return nil
+}
+
+// markPaymentExpired records that an attempt's invoice can no longer be paid
+func markPaymentExpired(tx *gorm.DB, invoiceID string) error {
+	err := tx.Model(&models.Payment{}).
+		Where("provider_invoice_id = ? AND status = ?", invoiceID, models.PaymentStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.PaymentStatusExpired,
+			"expired_at": time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark invoice %s as expired: %w", invoiceID, err)
+	}
+	return nil
+}
+
+// GetBookingPayments lists the payment attempts of a booking, oldest first
+func (bs *BookingService) GetBookingPayments(bookingID uuid.UUID) ([]models.Payment, error) {
+	var payments []models.Payment
+	if err := bs.db.Where("booking_id = ?", bookingID).Order("created_at ASC").Find(&payments).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch payments: %w", err)
+	}
+	return payments, nil
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaymentDeadlineStopsAtShowtimeCutoff(t *testing.T) {
+	t.Setenv("PAYMENT_CUTOFF_MINUTES", "15")
+	t.Setenv("PAYMENT_WINDOW_MAX_MINUTES", "30")
+	now := time.Date(2026, 1, 10, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		want  time.Time
+		fails bool
+	}{
+		{"showtime far away", now.Add(5 * time.Hour), now.Add(30 * time.Minute), false},
+		{"showtime in 40 minutes", now.Add(40 * time.Minute), now.Add(25 * time.Minute), false},
+		{"showtime in 10 minutes", now.Add(10 * time.Minute), time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := paymentDeadline(tt.start, now)
+		if (err != nil) != tt.fails || !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, %v", tt.name, got, err)
+		}
+	}
+}
@@ -1,205 +1,138 @@
 package services
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"os"
-	"time"
+	"strings"
+	"sync"
 
 	"github.com/google/uuid"
-	xendit "github.com/xendit/xendit-go/v6"
-	invoice "github.com/xendit/xendit-go/v6/invoice"
 
 	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
 )
 
-// PaymentService handles payment operations via Xendit
-type PaymentService struct {
-	client *xendit.APIClient
-}
-
-// InvoiceResult contains the result of creating an invoice
-type InvoiceResult struct {
-	InvoiceID   string  `json:"invoice_id"`
-	InvoiceURL  string  `json:"invoice_url"`
-	ExternalID  string  `json:"external_id"`
-	Amount      float64 `json:"amount"`
-	Status      string  `json:"status"`
-	ExpiryDate  string  `json:"expiry_date"`
-}
-
-// NewPaymentService creates a new payment service with Xendit client
-func NewPaymentService() (*PaymentService, error) {
-	secretKey := os.Getenv("XENDIT_SECRET_KEY")
-	if secretKey == "" {
-		return nil, errors.New("XENDIT_SECRET_KEY environment variable is not set")
-	}
-
-	client := xendit.NewClient(secretKey)
-
-	return &PaymentService{
-		client: client,
-	}, nil
-}
-
-// CreateInvoice creates a Xendit invoice for a booking
-func (ps *PaymentService) CreateInvoice(booking *models.Booking, userEmail string) (*InvoiceResult, error) {
-	if ps.client == nil {
-		return nil, errors.New("payment client is not initialized")
-	}
-
-	ctx := context.Background()
-
-	// External ID should be the booking ID (UUID)
-	externalID := booking.ID.String()
+// Payment provider names accepted by PAYMENT_PROVIDER
+const (
+	PaymentProviderXendit = "xendit"
+	PaymentProviderFake   = "fake"
+)
 
-	// Invoice description
-	description := fmt.Sprintf("AbsolutCinema Booking - Invoice %s", booking.InvoiceNumber)
+// Refund statuses
+const (
+	RefundStatusPending   = "PENDING"
+	RefundStatusSucceeded = "SUCCEEDED"
+	RefundStatusFailed    = "FAILED"
+)
 
-	// Set invoice expiry (24 hours from now)
-	expiryDate := time.Now().Add(24 * time.Hour)
+// Refund reasons (the values Xendit accepts)
+const (
+	RefundReasonCancellation        = "CANCELLATION"
+	RefundReasonRequestedByCustomer = "REQUESTED_BY_CUSTOMER"
+	RefundReasonDuplicate           = "DUPLICATE"
+	RefundReasonOthers              = "OTHERS"
+)
 
-	// Build invoice items from tickets
-	items := buildInvoiceItems(booking)
+// PaymentProvider is a payment gateway that can take payment for a booking.
+// Providers report payment results through the invoice callback
+// (POST /webhooks/xendit), which BookingService.HandleInvoiceCallback processes.
+type PaymentProvider interface {
+	// Name returns the provider name (e.g. "xendit")
+	Name() string
 
-	// Create invoice request
-	invoiceRequest := *invoice.NewCreateInvoiceRequest(externalID, float64(booking.TotalAmount))
-	invoiceRequest.SetDescription(description)
-	invoiceRequest.SetPayerEmail(userEmail)
-	invoiceRequest.SetCurrency("IDR")
-	invoiceRequest.SetSuccessRedirectUrl(getSuccessRedirectURL())
-	invoiceRequest.SetFailureRedirectUrl(getFailureRedirectURL())
-	
-	if len(items) > 0 {
-		invoiceRequest.SetItems(items)
-	}
+	// CreateInvoice creates a payable invoice for a booking; the booking ID is the external ID
+	CreateInvoice(booking *models.Booking, userEmail string) (*InvoiceResult, error)
 
-	// Create the invoice via Xendit API
-	resp, _, err := ps.client.InvoiceApi.CreateInvoice(ctx).
-		CreateInvoiceRequest(invoiceRequest).
-		Execute()
+	// GetInvoice retrieves an invoice by the provider's invoice ID
+	GetInvoice(invoiceID string) (*InvoiceResult, error)
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Xendit invoice: %w", err)
-	}
+	// GetInvoiceByExternalID retrieves the most recent invoice for a booking
+	GetInvoiceByExternalID(bookingID uuid.UUID) (*InvoiceResult, error)
 
-	result := &InvoiceResult{
-		InvoiceID:   *resp.Id,
-		InvoiceURL:  resp.InvoiceUrl,
-		ExternalID:  resp.ExternalId,
-		Amount:      resp.Amount,
-		Status:      string(resp.Status),
-		ExpiryDate:  expiryDate.Format(time.RFC3339),
-	}
+	// ExpireInvoice expires an unpaid invoice so it can no longer be paid
+	ExpireInvoice(invoiceID string) error
 
-	return result, nil
+	// Refund refunds part or all of a paid invoice
+	Refund(invoiceID string, amount money.Money, reason string) (*RefundResult, error)
 }
 
-// GetInvoice retrieves an invoice by ID
-func (ps *PaymentService) GetInvoice(invoiceID string) (*InvoiceResult, error) {
-	if ps.client == nil {
-		return nil, errors.New("payment client is not initialized")
-	}
-
-	ctx := context.Background()
-
-	resp, _, err := ps.client.InvoiceApi.GetInvoiceById(ctx, invoiceID).Execute()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get Xendit invoice: %w", err)
-	}
-
-	result := &InvoiceResult{
-		InvoiceID:   *resp.Id,
-		InvoiceURL:  resp.InvoiceUrl,
-		ExternalID:  resp.ExternalId,
-		Amount:      resp.Amount,
-		Status:      string(resp.Status),
-	}
-
-	return result, nil
+// InvoiceResult contains the result of creating an invoice
+type InvoiceResult struct {
+	InvoiceID  string      `json:"invoice_id"`
+	InvoiceURL string      `json:"invoice_url"`
+	ExternalID string      `json:"external_id"`
+	Amount     money.Money `json:"amount"`
+	Status     string      `json:"status"`
+	Currency   string      `json:"currency,omitempty"`
+	ExpiryDate string      `json:"expiry_date"`
 }
 
-// GetInvoiceByExternalID retrieves an invoice by external ID (booking ID)
-func (ps *PaymentService) GetInvoiceByExternalID(bookingID uuid.UUID) (*InvoiceResult, error) {
-	if ps.client == nil {
-		return nil, errors.New("payment client is not initialized")
-	}
-
-	ctx := context.Background()
-
-	resp, _, err := ps.client.InvoiceApi.GetInvoices(ctx).
-		ExternalId(bookingID.String()).
-		Execute()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get Xendit invoice by external ID: %w", err)
-	}
-
-	if len(resp) == 0 {
-		return nil, errors.New("invoice not found")
-	}
+// RefundResult contains the result of requesting a refund
+type RefundResult struct {
+	RefundID  string      `json:"refund_id"`
+	InvoiceID string      `json:"invoice_id"`
+	Amount    money.Money `json:"amount"`
+	Status    string      `json:"status"`
+}
 
-	// Get the most recent invoice
-	inv := resp[0]
+var (
+	fakeProviderOnce sync.Once
+	fakeProvider     *FakePaymentProvider
+)
 
-	result := &InvoiceResult{
-		InvoiceID:   *inv.Id,
-		InvoiceURL:  inv.InvoiceUrl,
-		ExternalID:  inv.ExternalId,
-		Amount:      inv.Amount,
-		Status:      string(inv.Status),
+// NewPaymentProvider creates the payment provider selected by PAYMENT_PROVIDER
+// ("xendit" by default, or "fake" for local development and integration tests).
+func NewPaymentProvider() (PaymentProvider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("PAYMENT_PROVIDER")))
+
+	switch name {
+	case "", PaymentProviderXendit:
+		return NewXenditProvider()
+
+	case PaymentProviderFake:
+		if os.Getenv("APP_ENV") == "production" {
+			return nil, errors.New("the fake payment provider cannot be used in production")
+		}
+		// The fake keeps its invoices in memory, so the API server and the
+		// background jobs must share one instance
+		fakeProviderOnce.Do(func() {
+			fakeProvider = NewFakePaymentProvider(getFakePaymentBaseURL())
+		})
+		return fakeProvider, nil
+
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", name)
 	}
-
-	return result, nil
 }
 
-// ExpireInvoice expires/cancels an invoice
-func (ps *PaymentService) ExpireInvoice(invoiceID string) error {
-	if ps.client == nil {
-		return errors.New("payment client is not initialized")
+// invoiceItemName describes a ticket on an invoice, e.g. "Seat A5 (Premium) - Child ticket"
+func invoiceItemName(ticket models.Ticket) string {
+	name := fmt.Sprintf("Seat %s", ticket.SeatNumber)
+	if ticket.SeatCategory != "" {
+		name = fmt.Sprintf("Seat %s (%s)", ticket.SeatNumber, ticket.SeatCategory)
 	}
-
-	ctx := context.Background()
-
-	_, _, err := ps.client.InvoiceApi.ExpireInvoice(ctx, invoiceID).Execute()
-	if err != nil {
-		return fmt.Errorf("failed to expire Xendit invoice: %w", err)
+	if ticket.TicketTypeName != "" {
+		name = fmt.Sprintf("%s - %s ticket", name, ticket.TicketTypeName)
 	}
-
-	return nil
+	return name
 }
 
-// buildInvoiceItems creates invoice items from booking tickets
-func buildInvoiceItems(booking *models.Booking) []invoice.InvoiceItem {
-	if len(booking.Tickets) == 0 {
-		return nil
-	}
-
-	items := make([]invoice.InvoiceItem, 0, len(booking.Tickets))
-
-	// Group tickets by showtime for better invoice display
-	// For simplicity, we create one item per ticket
-	pricePerSeat := float32(booking.TotalAmount / float64(len(booking.Tickets)))
-	
-	for _, ticket := range booking.Tickets {
-		item := *invoice.NewInvoiceItem(
-			fmt.Sprintf("Seat %s", ticket.SeatNumber),
-			1, // Quantity
-			pricePerSeat,
-		)
-		items = append(items, item)
+// invoiceDiscountName describes a booking's promo discount on an invoice
+func invoiceDiscountName(booking *models.Booking) string {
+	if booking.PromoCode != "" {
+		return fmt.Sprintf("Discount (%s)", booking.PromoCode)
 	}
-
-	return items
+	return "Discount"
 }
 
 // getSuccessRedirectURL returns the URL to redirect after successful payment
 func getSuccessRedirectURL() string {
 	baseURL := os.Getenv("FRONTEND_URL")
 	if baseURL == "" {
-		baseURL = "https://absolut-cinema-umwih.ondigitalocean.app" 
+		baseURL = "https://absolut-cinema-umwih.ondigitalocean.app"
 	}
-	return baseURL + "/booking/success"   
+	return baseURL + "/booking/success"
 }
 
 // getFailureRedirectURL returns the URL to redirect after failed payment
@@ -227,6 +160,8 @@ const (
 	ErrCodeBookingNotFound  = "BOOKING_NOT_FOUND"
 	ErrCodeInvalidStatus    = "INVALID_STATUS"
 	ErrCodeAlreadyProcessed = "ALREADY_PROCESSED"
+	ErrCodeInvalidPayload   = "INVALID_PAYLOAD"
+	ErrCodeRefundNotFound   = "REFUND_NOT_FOUND"
 )
 
 // NewWebhookError creates a new webhook error
@@ -236,6 +171,7 @@ func NewWebhookError(code, message string) *WebhookError {
 
 // ValidateCallbackToken validates the Xendit callback token from the request header
 // This is critical for security - ensures the webhook is actually from Xendit
+// (the fake provider sends the same token)
 func ValidateCallbackToken(token string) error {
 	expectedToken := os.Getenv("XENDIT_WEBHOOK_TOKEN")
 	if expectedToken == "" {
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+)
+
+const (
+	// Reconciliation sources
+	ReconcileSourceScheduled = "scheduled"
+	ReconcileSourceReaper    = "reaper"
+
+	// reconcileGracePeriod leaves fresh PENDING bookings to the webhook
+	reconcileGracePeriod = 5 * time.Minute
+)
+
+// reconcileBatchSize bounds the gateway calls made per pass; a var so tests can shrink it
+var reconcileBatchSize = 200
+
+// ReconciliationSummary summarizes one reconciliation pass
+type ReconciliationSummary struct {
+	RunID     uuid.UUID `json:"run_id"`
+	Checked   int       `json:"checked"`
+	Corrected int       `json:"corrected"`
+	Failed    int       `json:"failed"`
+}
+
+// ReconcilePayments compares PENDING bookings, and CANCELLED/EXPIRED bookings created
+// within lookback, with their invoice at the payment provider. Drift is fixed through
+// the same code paths as the webhook, and every correction is recorded.
+func (bs *BookingService) ReconcilePayments(ctx context.Context, lookback time.Duration) (*ReconciliationSummary, error) {
+	summary := &ReconciliationSummary{RunID: uuid.New()}
+	if bs.paymentProvider == nil {
+		return summary, nil
+	}
+
+	var bookings []models.Booking
+	if err := reconcileCandidates(bs.db.WithContext(ctx), time.Now(), lookback).Find(&bookings).Error; err != nil {
+		return summary, fmt.Errorf("failed to fetch bookings to reconcile: %w", err)
+	}
+
+	var checked []uuid.UUID
+	defer func() {
+		// Checked bookings go to the back of the queue, whatever the outcome
+		if len(checked) == 0 {
+			return
+		}
+		if err := bs.db.Model(&models.Booking{}).Where("id IN ?", checked).
+			Update("last_reconciled_at", time.Now()).Error; err != nil {
+			log.Printf("[Reconciliation] Failed to record checked bookings: %v", err)
+		}
+	}()
+
+	for i := range bookings {
+		if ctx.Err() != nil {
+			break
+		}
+		summary.Checked++
+		checked = append(checked, bookings[i].ID)
+
+		inv, err := bs.paymentProvider.GetInvoiceByExternalID(bookings[i].ID)
+		if err != nil {
+			if err.Error() != "invoice not found" {
+				summary.Failed++
+				log.Printf("[Reconciliation] Failed to fetch invoice for booking %s: %v", bookings[i].ID, err)
+			}
+			continue
+		}
+
+		corrected, err := bs.applyInvoiceState(&bookings[i], inv, summary.RunID, ReconcileSourceScheduled)
+		if err != nil {
+			summary.Failed++
+			log.Printf("[Reconciliation] Failed to reconcile booking %s: %v", bookings[i].ID, err)
+			continue
+		}
+		if corrected {
+			summary.Corrected++
+		}
+	}
+
+	return summary, nil
+}
+
+// reconcileCandidates selects the bookings one pass checks: PENDING bookings past the grace
+// period and CANCELLED/EXPIRED ones created within lookback. The least recently checked come
+// first, so a backlog larger than one batch is worked through over several passes instead of
+// the same oldest bookings being checked every time.
+func reconcileCandidates(db *gorm.DB, now time.Time, lookback time.Duration) *gorm.DB {
+	return db.Model(&models.Booking{}).
+		Where("(status = ? AND created_at < ?) OR (status IN ? AND created_at > ?)",
+			BookingStatusPending, now.Add(-reconcileGracePeriod),
+			[]string{BookingStatusCancelled, BookingStatusExpired}, now.Add(-lookback)).
+		Order("last_reconciled_at ASC NULLS FIRST").
+		Order("created_at ASC").
+		Limit(reconcileBatchSize)
+}
+
+// GetReconciliationReports lists recorded corrections, newest first
+func (bs *BookingService) GetReconciliationReports(limit, offset int) ([]models.PaymentReconciliation, int64, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	var total int64
+	if err := bs.db.Model(&models.PaymentReconciliation{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count reconciliation reports: %w", err)
+	}
+
+	var reports []models.PaymentReconciliation
+	if err := bs.db.Order("created_at DESC").Limit(limit).Offset(offset).Find(&reports).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch reconciliation reports: %w", err)
+	}
+
+	return reports, total, nil
+}
+
+// applyInvoiceState brings a booking in line with its invoice at the provider, as if the
+// matching callback had arrived, and records the correction. Returns whether anything changed.
+func (bs *BookingService) applyInvoiceState(booking *models.Booking, inv *InvoiceResult, runID uuid.UUID, source string) (bool, error) {
+	payload := &models.XenditInvoiceCallback{
+		ID:         inv.InvoiceID,
+		ExternalID: inv.ExternalID,
+		Status:     inv.Status,
+		Amount:     inv.Amount,
+		Currency:   inv.Currency,
+	}
+
+	switch inv.Status {
+	case models.XenditStatusPaid, models.XenditStatusSettled:
+		if booking.Status == BookingStatusPaid && booking.PaymentID == inv.InvoiceID {
+			return false, nil
+		}
+		// Invoices are always paid in full
+		payload.PaidAmount = inv.Amount
+		if err := bs.handlePaymentSuccess(booking, payload); err != nil {
+			return false, err
+		}
+
+	case models.XenditStatusExpired:
+		if err := markPaymentExpired(bs.db, inv.InvoiceID); err != nil {
+			return false, err
+		}
+		// Only the booking's current invoice decides; an older one expiring is expected after a retry
+		if booking.Status != BookingStatusPending || (booking.PaymentID != "" && booking.PaymentID != inv.InvoiceID) {
+			return false, nil
+		}
+		if err := bs.handlePaymentExpired(booking, payload); err != nil {
+			return false, err
+		}
+
+	case "PENDING":
+		// A booking that was given up on must not stay payable
+		if booking.Status == BookingStatusCancelled || booking.Status == BookingStatusExpired {
+			if err := bs.paymentProvider.ExpireInvoice(inv.InvoiceID); err != nil {
+				return false, fmt.Errorf("failed to expire open invoice %s: %w", inv.InvoiceID, err)
+			}
+			if err := markPaymentExpired(bs.db, inv.InvoiceID); err != nil {
+				return false, err
+			}
+			log.Printf("[Reconciliation] Expired open invoice %s of %s booking %s", inv.InvoiceID, booking.Status, booking.ID)
+		}
+		return false, nil
+
+	default:
+		return false, nil
+	}
+
+	var updated models.Booking
+	if err := bs.db.First(&updated, "id = ?", booking.ID).Error; err != nil {
+		return false, fmt.Errorf("failed to reload booking: %w", err)
+	}
+	if updated.Status == booking.Status {
+		return false, nil
+	}
+
+	note := ""
+	switch {
+	case updated.Status == BookingStatusPaymentReview:
+		note = updated.PaymentReviewReason
+	case updated.Status == BookingStatusPaid:
+		note = "payment callback was never received"
+	case updated.Status == BookingStatusExpired:
+		note = "expiry callback was never received; seats released"
+	}
+
+	report := models.PaymentReconciliation{
+		RunID:          runID,
+		Source:         source,
+		BookingID:      booking.ID,
+		InvoiceID:      inv.InvoiceID,
+		ProviderStatus: inv.Status,
+		PreviousStatus: booking.Status,
+		NewStatus:      updated.Status,
+		Note:           note,
+	}
+	if err := bs.db.Create(&report).Error; err != nil {
+		// The correction itself is committed; losing the report must not undo it
+		log.Printf("[Reconciliation] Failed to record correction for booking %s: %v", booking.ID, err)
+	}
+
+	log.Printf("[Reconciliation] Booking %s: %s -> %s (invoice %s is %s)",
+		booking.ID, booking.Status, updated.Status, inv.InvoiceID, inv.Status)
+
+	*booking = updated
+	return true, nil
+}
+
+// checkInvoiceBeforeExpiry asks the provider whether a stale PENDING booking was actually
+// paid. If so the payment is applied instead of expiring the booking, and true is returned.
+func (bs *BookingService) checkInvoiceBeforeExpiry(booking *models.Booking) (bool, error) {
+	if bs.paymentProvider == nil || booking.PaymentID == "" {
+		return false, nil
+	}
+
+	inv, err := bs.paymentProvider.GetInvoice(booking.PaymentID)
+	if err != nil {
+		return false, fmt.Errorf("could not check invoice status: %w", err)
+	}
+	if inv.Status != models.XenditStatusPaid && inv.Status != models.XenditStatusSettled {
+		return false, nil
+	}
+
+	_, err = bs.applyInvoiceState(booking, inv, uuid.New(), ReconcileSourceReaper)
+	return true, err
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"absolutcinema-backend/internal/database"
+	"absolutcinema-backend/internal/models"
+)
+
+func TestReconcilePaymentsRotatesThroughBacklog(t *testing.T) {
+	db := newTestDB(t, database.Models()...)
+	defer func(size int) { reconcileBatchSize = size }(reconcileBatchSize)
+	reconcileBatchSize = 2
+
+	// Three PENDING bookings past the grace period, one more than a pass checks
+	created := time.Now().Add(-time.Hour)
+	var bookings []models.Booking
+	for i := 0; i < 3; i++ {
+		booking := seedBooking(t, db, BookingStatusPending, "")
+		if err := db.Model(&booking).Update("created_at", created.Add(time.Duration(i)*time.Minute)).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+		bookings = append(bookings, booking)
+	}
+	invoice := func(b models.Booking) string { return "inv-" + b.ID.String() }
+
+	provider := &stubProvider{}
+	bs := NewBookingService(db, provider)
+	if _, err := bs.ReconcilePayments(context.Background(), 24*time.Hour); err != nil {
+		t.Fatalf("first pass: %v", err)
+	}
+	if want := []string{invoice(bookings[0]), invoice(bookings[1])}; !slices.Equal(provider.checked, want) {
+		t.Fatalf("first pass checked %v, want the two oldest %v", provider.checked, want)
+	}
+
+	provider.checked = nil
+	if _, err := bs.ReconcilePayments(context.Background(), 24*time.Hour); err != nil {
+		t.Fatalf("second pass: %v", err)
+	}
+	if len(provider.checked) == 0 || provider.checked[0] != invoice(bookings[2]) {
+		t.Errorf("second pass checked %v, want it to start with the booking the first pass left (%s)",
+			provider.checked, invoice(bookings[2]))
+	}
+}
+
+func TestApplyInvoiceStateRecordsExpiry(t *testing.T) {
+	db := newTestDB(t, database.Models()...)
+	booking := seedBooking(t, db, BookingStatusPending, "inv-expired")
+	bs := NewBookingService(db, nil)
+
+	inv := &InvoiceResult{InvoiceID: "inv-expired", Status: models.XenditStatusExpired, Amount: booking.TotalAmount, Currency: "IDR"}
+	changed, err := bs.applyInvoiceState(&booking, inv, uuid.New(), "test")
+	if err != nil {
+		t.Fatalf("applyInvoiceState: %v", err)
+	}
+	if !changed || booking.Status != BookingStatusExpired {
+		t.Fatalf("changed = %v, status = %s; want a correction to EXPIRED", changed, booking.Status)
+	}
+
+	var report models.PaymentReconciliation
+	if err := db.First(&report, "booking_id = ?", booking.ID).Error; err != nil {
+		t.Fatalf("no correction recorded: %v", err)
+	}
+	if report.PreviousStatus != BookingStatusPending || report.NewStatus != BookingStatusExpired {
+		t.Errorf("recorded %s -> %s, want PENDING -> EXPIRED", report.PreviousStatus, report.NewStatus)
+	}
+	if report.Note == "" {
+		t.Error("expiry correction has no note")
+	}
+
+	var tickets int64
+	db.Model(&models.Ticket{}).Where("booking_id = ?", booking.ID).Count(&tickets)
+	if tickets != 0 {
+		t.Errorf("%d tickets still hold seats", tickets)
+	}
+}
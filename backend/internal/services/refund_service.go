@@ -0,0 +1,411 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+// RefundRequest is the body of a refund request
+type RefundRequest struct {
+	// Reason is one of the refund reasons; customers always refund as REQUESTED_BY_CUSTOMER
+	Reason string `json:"reason,omitempty"`
+	Note   string `json:"note,omitempty"`
+
+	// Amount lets an admin refund less than everything still refundable
+	Amount *money.Money `json:"amount,omitempty"`
+}
+
+// RefundBooking refunds a paid booking through the payment provider, releases its seats
+// and moves it to REFUNDED, PARTIALLY_REFUNDED when part of the payment is kept, or
+// CANCELLED when the policy refunds nothing (the returned refund is then nil).
+// Customers can only refund their own bookings, as the booking's cancellation policy
+// allows; admins can refund any paid booking and choose the amount.
+func (bs *BookingService) RefundBooking(bookingID, requesterID uuid.UUID, isAdmin bool, req *RefundRequest) (*models.Refund, error) {
+	reason := RefundReasonRequestedByCustomer
+	if isAdmin {
+		reason = RefundReasonOthers
+		if req.Reason != "" {
+			reason = req.Reason
+		}
+		switch reason {
+		case RefundReasonCancellation, RefundReasonRequestedByCustomer, RefundReasonDuplicate, RefundReasonOthers:
+		default:
+			return nil, errors.New("invalid refund reason")
+		}
+	}
+
+	// 1. Check the booking and reserve the amount as a PENDING refund. Pending refunds
+	// count against what is refundable, so a concurrent request can't refund twice.
+	var booking models.Booking
+	var refund models.Refund
+	err := bs.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", bookingID)
+		if !isAdmin {
+			query = query.Where("user_id = ?", requesterID)
+		}
+		if err := query.First(&booking).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("booking not found")
+			}
+			return fmt.Errorf("failed to fetch booking: %w", err)
+		}
+
+		switch booking.Status {
+		case BookingStatusPaid:
+		case BookingStatusPartiallyRefunded:
+			// Only staff can return what the policy kept
+			if !isAdmin {
+				return errors.New("booking has already been refunded")
+			}
+		case BookingStatusRefunded:
+			return errors.New("booking has already been refunded")
+		default:
+			return errors.New("only paid bookings can be refunded")
+		}
+
+		refundable, err := refundableAmount(tx, &booking)
+		if err != nil {
+			return err
+		}
+
+		var amount money.Money
+		if isAdmin {
+			amount = refundable
+			if req.Amount != nil {
+				amount = *req.Amount
+			}
+		} else {
+			// Admitted tickets were used; only staff can refund them
+			var admitted int64
+			if err := tx.Model(&models.Ticket{}).
+				Where("booking_id = ? AND checked_in_at IS NOT NULL", booking.ID).
+				Count(&admitted).Error; err != nil {
+				return fmt.Errorf("failed to check admissions: %w", err)
+			}
+			if admitted > 0 {
+				return errors.New("tickets have already been checked in")
+			}
+
+			showtime, err := bookingShowtime(tx, booking.ID)
+			if err != nil {
+				return err
+			}
+			policy, err := resolveCancellationPolicy(tx, showtime)
+			if err != nil {
+				return err
+			}
+			quote := evaluateCancellationPolicy(policy, showtime.StartTime, time.Now(), refundable)
+			if !quote.Cancellable {
+				return errors.New(quote.Reason)
+			}
+			amount = quote.RefundAmount
+		}
+		exceeds, err := amount.GreaterThan(refundable)
+		if err != nil {
+			return err
+		}
+		if amount.IsNegative() || exceeds {
+			return errors.New("refund amount exceeds the refundable amount")
+		}
+
+		// Nothing to send back (the policy keeps everything, or nothing was paid):
+		// the seats are simply released below
+		if amount.IsZero() {
+			return nil
+		}
+
+		refund = models.Refund{
+			BookingID:        booking.ID,
+			InvoiceID:        booking.PaymentID,
+			Amount:           amount,
+			Reason:           reason,
+			Note:             req.Note,
+			Status:           RefundStatusPending,
+			RequestedBy:      requesterID,
+			RequestedByAdmin: isAdmin,
+		}
+		if bs.paymentProvider != nil {
+			refund.Provider = bs.paymentProvider.Name()
+		}
+		if err := tx.Omit("Booking").Create(&refund).Error; err != nil {
+			return fmt.Errorf("failed to record refund: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Ask the provider to send the money back (outside the transaction; it's a network call)
+	var result *RefundResult
+	if refund.ID != 0 {
+		if bs.paymentProvider == nil {
+			err = errors.New("payment provider is not configured")
+		} else {
+			result, err = bs.paymentProvider.Refund(booking.PaymentID, refund.Amount, refund.Reason)
+		}
+		if err != nil {
+			now := time.Now()
+			if uerr := bs.db.Model(&refund).Updates(map[string]interface{}{
+				"status":     RefundStatusFailed,
+				"error":      err.Error(),
+				"settled_at": &now,
+			}).Error; uerr != nil {
+				log.Printf("[Refund] Failed to mark refund %d as failed: %v", refund.ID, uerr)
+			}
+			return nil, fmt.Errorf("refund was rejected by the payment provider: %w", err)
+		}
+	}
+
+	// 3. Record the provider's answer, release the seats and settle the booking status
+	err = bs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, "id = ?", booking.ID).Error; err != nil {
+			return fmt.Errorf("failed to lock booking: %w", err)
+		}
+
+		if result != nil {
+			creditNote, err := nextDocumentNumber(tx, booking.BillingEntity, models.DocumentTypeCreditNote, time.Now())
+			if err != nil {
+				return err
+			}
+			updates := map[string]interface{}{
+				"provider_refund_id": result.RefundID,
+				"status":             result.Status,
+				"credit_note_number": creditNote,
+			}
+			if result.Status != RefundStatusPending {
+				updates["settled_at"] = time.Now()
+			}
+			if err := tx.Model(&refund).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to update refund: %w", err)
+			}
+
+			if err := postRefundIssued(tx, &booking, &refund); err != nil {
+				return err
+			}
+			if result.Status == RefundStatusSucceeded {
+				if err := postRefundPaid(tx, &refund); err != nil {
+					return err
+				}
+			}
+		}
+
+		err := enqueueBookingEmail(tx, models.EmailBookingCancelled, booking.ID, func(data *emailData) {
+			if refund.ID != 0 {
+				data.Refund = refund.Amount.String()
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := cancelReminders(tx, booking.ID); err != nil {
+			return err
+		}
+
+		if err := releaseTickets(tx, booking.ID, SeatReasonRefunded); err != nil {
+			return err
+		}
+		if err := releaseVoucherRedemption(tx, booking.ID); err != nil {
+			return err
+		}
+		if err := releaseBookingItems(tx, booking.ID); err != nil {
+			return err
+		}
+
+		remaining, err := refundableAmount(tx, &booking)
+		if err != nil {
+			return err
+		}
+		refunded, err := booking.TotalAmount.Sub(remaining)
+		if err != nil {
+			return err
+		}
+		status := BookingStatusCancelled
+		switch {
+		case refunded.IsPositive() && remaining.IsZero():
+			status = BookingStatusRefunded
+		case refunded.IsPositive():
+			status = BookingStatusPartiallyRefunded
+		}
+		if err := tx.Model(&booking).Update("status", status).Error; err != nil {
+			return fmt.Errorf("failed to update booking status: %w", err)
+		}
+		return nil
+	})
+	if err != nil && result != nil {
+		// The provider already accepted the refund; staff must finish this one by hand
+		raisePaymentAlert(booking.ID, booking.PaymentID, fmt.Sprintf(
+			"refund %d was sent to the provider but could not be recorded: %v", refund.ID, err))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if refund.ID == 0 {
+		return nil, nil
+	}
+	if err := bs.db.First(&refund, refund.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload refund: %w", err)
+	}
+	return &refund, nil
+}
+
+// GetCancellationQuote returns the cancellation policy that applies to a booking and what
+// cancelling it would refund right now. The booking's tickets and showtimes must be loaded.
+func (bs *BookingService) GetCancellationQuote(booking *models.Booking) (*CancellationQuote, error) {
+	switch booking.Status {
+	case BookingStatusPending, BookingStatusPaid:
+	case BookingStatusPaymentReview:
+		return &CancellationQuote{Reason: "booking is under payment review"}, nil
+	case BookingStatusRefunded, BookingStatusPartiallyRefunded:
+		return &CancellationQuote{Reason: "booking has already been refunded"}, nil
+	default:
+		return &CancellationQuote{Reason: "booking is " + strings.ToLower(booking.Status)}, nil
+	}
+
+	var showtime *models.Showtime
+	for i := range booking.Tickets {
+		if st := &booking.Tickets[i].Showtime; showtime == nil || st.StartTime.Before(showtime.StartTime) {
+			showtime = st
+		}
+	}
+	if showtime == nil {
+		return &CancellationQuote{Reason: "booking has no tickets"}, nil
+	}
+
+	policy, err := resolveCancellationPolicy(bs.db, showtime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nothing was paid yet; cancelling just releases the seats
+	if booking.Status == BookingStatusPending {
+		return &CancellationQuote{Policy: policy, Cancellable: true}, nil
+	}
+
+	refundable, err := refundableAmount(bs.db, booking)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateCancellationPolicy(policy, showtime.StartTime, time.Now(), refundable), nil
+}
+
+// GetBookingRefunds lists the refunds of a booking, oldest first
+func (bs *BookingService) GetBookingRefunds(bookingID uuid.UUID) ([]models.Refund, error) {
+	var refunds []models.Refund
+	if err := bs.db.Where("booking_id = ?", bookingID).Order("created_at ASC").Find(&refunds).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch refunds: %w", err)
+	}
+	return refunds, nil
+}
+
+// HandleRefundCallback processes Xendit refund webhook callbacks.
+// A refund is only settled once; repeated callbacks are ignored.
+func (bs *BookingService) HandleRefundCallback(payload *models.XenditRefundCallback, callbackToken string) error {
+	if err := ValidateCallbackToken(callbackToken); err != nil {
+		return err
+	}
+	if payload.Data.ID == "" {
+		return NewWebhookError(ErrCodeInvalidPayload, "missing refund id")
+	}
+
+	var refund models.Refund
+	if err := bs.db.Where("provider_refund_id = ?", payload.Data.ID).First(&refund).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return NewWebhookError(ErrCodeRefundNotFound, "refund not found")
+		}
+		return fmt.Errorf("failed to fetch refund: %w", err)
+	}
+
+	if refund.Status != RefundStatusPending {
+		return nil
+	}
+
+	switch payload.Data.Status {
+	case RefundStatusSucceeded, RefundStatusFailed:
+	default:
+		return nil
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":     payload.Data.Status,
+		"settled_at": &now,
+	}
+	if payload.Data.Status == RefundStatusFailed {
+		updates["error"] = payload.Data.FailureCode
+	}
+	var settled bool
+	err := bs.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Refund{}).
+			Where("id = ? AND status = ?", refund.ID, RefundStatusPending).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update refund: %w", result.Error)
+		}
+		settled = result.RowsAffected > 0
+
+		if settled && payload.Data.Status == RefundStatusSucceeded {
+			return postRefundPaid(tx, &refund)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The seats are already released, so the customer is owed money someone must send by hand
+	if settled && payload.Data.Status == RefundStatusFailed {
+		raisePaymentAlert(refund.BookingID, refund.InvoiceID, fmt.Sprintf(
+			"refund %s of %s failed at the provider: %s", payload.Data.ID, refund.Amount, payload.Data.FailureCode))
+	}
+
+	return nil
+}
+
+// refundableAmount returns what is left of a booking's payment after its pending and succeeded refunds
+func refundableAmount(tx *gorm.DB, booking *models.Booking) (money.Money, error) {
+	var refunds []models.Refund
+	if err := tx.Where("booking_id = ? AND status != ?", booking.ID, RefundStatusFailed).Find(&refunds).Error; err != nil {
+		return money.Money{}, fmt.Errorf("failed to fetch refunds: %w", err)
+	}
+
+	remaining := booking.TotalAmount
+	for _, r := range refunds {
+		var err error
+		if remaining, err = remaining.Sub(r.Amount); err != nil {
+			return money.Money{}, err
+		}
+	}
+	if remaining.IsNegative() {
+		return money.Money{}, nil
+	}
+	return remaining, nil
+}
+
+// bookingShowtime returns the booking's (earliest) showtime
+func bookingShowtime(tx *gorm.DB, bookingID uuid.UUID) (*models.Showtime, error) {
+	var showtime models.Showtime
+	err := tx.Joins("JOIN tickets ON tickets.showtime_id = showtimes.id").
+		Where("tickets.booking_id = ?", bookingID).
+		Order("showtimes.start_time ASC").
+		First(&showtime).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("booking has no tickets")
+		}
+		return nil, fmt.Errorf("failed to fetch showtime: %w", err)
+	}
+	return &showtime, nil
+}
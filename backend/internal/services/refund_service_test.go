@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"absolutcinema-backend/internal/database"
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+func TestRefundBookingKeepsAdmittedTickets(t *testing.T) {
+	db := newTestDB(t, database.Models()...)
+	booking := seedBooking(t, db, BookingStatusPaid, "inv-admitted")
+
+	var admitted models.Ticket
+	if err := db.First(&admitted, "booking_id = ?", booking.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	checkedInAt := time.Now().Truncate(time.Second)
+	if err := db.Model(&admitted).Updates(map[string]interface{}{
+		"qr_token": "signed-token", "checked_in_at": checkedInAt, "check_in_gate": "gate-1",
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+	unused := models.Ticket{BookingID: booking.ID, ShowtimeID: admitted.ShowtimeID, SeatNumber: "A2", Price: admitted.Price}
+	if err := db.Create(&unused).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is sent back, so no provider is needed to settle the refund
+	zero := money.Money{}
+	bs := NewBookingService(db, nil)
+	if _, err := bs.RefundBooking(booking.ID, uuid.New(), true, &RefundRequest{Amount: &zero}); err != nil {
+		t.Fatalf("RefundBooking: %v", err)
+	}
+
+	var tickets []models.Ticket
+	if err := db.Where("booking_id = ?", booking.ID).Find(&tickets).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 || tickets[0].ID != admitted.ID {
+		t.Fatalf("tickets after refund = %+v, want only the admitted one", tickets)
+	}
+	kept := tickets[0]
+	if kept.CheckedInAt == nil || kept.CheckInGate != "gate-1" || kept.QRToken != "signed-token" {
+		t.Errorf("admission record lost: %+v", kept)
+	}
+
+	occupied, err := bs.GetOccupiedSeats(admitted.ShowtimeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(occupied) != 1 || occupied[0] != "A1" {
+		t.Errorf("occupied seats = %v, want [A1]", occupied)
+	}
+}
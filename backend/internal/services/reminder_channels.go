@@ -0,0 +1,134 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+)
+
+// Reminder is a showtime reminder ready to go out through a channel
+type Reminder struct {
+	BookingID    uuid.UUID
+	UserID       uuid.UUID
+	Phone        string
+	PushEndpoint string
+	Lead         string // "24 hours"
+	Title        string
+	Body         string
+}
+
+// ReminderChannel delivers showtime reminders. Email is always available; SMS and
+// web push are registered when their gateway is configured.
+type ReminderChannel interface {
+	// Name is the channel's key in user preferences (see models.ReminderChannel*)
+	Name() string
+
+	Send(reminder *Reminder) error
+}
+
+// NewReminderChannels returns the channels configured for this deployment.
+// SMS_GATEWAY_URL and PUSH_GATEWAY_URL enable the SMS and web push channels.
+func NewReminderChannels(db *gorm.DB) []ReminderChannel {
+	channels := []ReminderChannel{NewEmailReminderChannel(db)}
+	if url := os.Getenv("SMS_GATEWAY_URL"); url != "" {
+		channels = append(channels, NewGatewayReminderChannel(models.ReminderChannelSMS, url))
+	}
+	if url := os.Getenv("PUSH_GATEWAY_URL"); url != "" {
+		channels = append(channels, NewGatewayReminderChannel(models.ReminderChannelPush, url))
+	}
+	return channels
+}
+
+// EmailReminderChannel queues reminders in the email outbox, which retries delivery
+type EmailReminderChannel struct {
+	db *gorm.DB
+}
+
+// NewEmailReminderChannel creates a new email reminder channel
+func NewEmailReminderChannel(db *gorm.DB) *EmailReminderChannel {
+	return &EmailReminderChannel{db: db}
+}
+
+// Name implements ReminderChannel
+func (c *EmailReminderChannel) Name() string {
+	return models.ReminderChannelEmail
+}
+
+// Send implements ReminderChannel
+func (c *EmailReminderChannel) Send(reminder *Reminder) error {
+	return enqueueBookingEmail(c.db, models.EmailShowtimeReminder, reminder.BookingID, func(data *emailData) {
+		data.Lead = reminder.Lead
+	})
+}
+
+// GatewayReminderChannel hands reminders to an HTTP gateway that sends the SMS or
+// web push notification, e.g. a provider's API or a small relay service
+type GatewayReminderChannel struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+// gatewayMessage is the JSON body posted to a reminder gateway
+type gatewayMessage struct {
+	Channel   string    `json:"channel"`
+	To        string    `json:"to"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	BookingID uuid.UUID `json:"booking_id"`
+}
+
+// NewGatewayReminderChannel creates a channel that posts reminders to url
+func NewGatewayReminderChannel(name, url string) *GatewayReminderChannel {
+	return &GatewayReminderChannel{
+		name:   name,
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name implements ReminderChannel
+func (c *GatewayReminderChannel) Name() string {
+	return c.name
+}
+
+// Send implements ReminderChannel
+func (c *GatewayReminderChannel) Send(reminder *Reminder) error {
+	to := reminder.Phone
+	if c.name == models.ReminderChannelPush {
+		to = reminder.PushEndpoint
+	}
+	if to == "" {
+		return fmt.Errorf("no %s address for user %s", c.name, reminder.UserID)
+	}
+
+	body, err := json.Marshal(gatewayMessage{
+		Channel:   c.name,
+		To:        to,
+		Title:     reminder.Title,
+		Body:      reminder.Body,
+		BookingID: reminder.BookingID,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s gateway: %w", c.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.New(c.name + " gateway returned " + resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"absolutcinema-backend/internal/models"
+)
+
+const (
+	// MaxReminderAttempts is how often a reminder is tried when every channel fails
+	MaxReminderAttempts = 3
+
+	// ReminderRetryDelay is the wait before a failed reminder is tried again
+	ReminderRetryDelay = 5 * time.Minute
+
+	// ReminderBatchSize is how many due reminders one pass sends
+	ReminderBatchSize = 100
+)
+
+// ReminderLeadTimes are how long before the showtime paid bookings are reminded, longest first
+var ReminderLeadTimes = []time.Duration{24 * time.Hour, 2 * time.Hour}
+
+// ReminderService sends showtime reminders and manages reminder preferences
+type ReminderService struct {
+	db       *gorm.DB
+	channels map[string]ReminderChannel
+}
+
+// NewReminderService creates a reminder service sending through channels
+func NewReminderService(db *gorm.DB, channels []ReminderChannel) *ReminderService {
+	rs := &ReminderService{db: db, channels: make(map[string]ReminderChannel)}
+	for _, channel := range channels {
+		rs.channels[channel.Name()] = channel
+	}
+	return rs
+}
+
+// planReminders plans a paid booking's reminders from its showtime's start.
+// Lead times already past are left out, so a booking paid two hours before
+// the show gets no 24 hour reminder. Must be called in the transaction that
+// marks the booking PAID.
+func planReminders(tx *gorm.DB, bookingID uuid.UUID) error {
+	showtime, err := bookingShowtime(tx, bookingID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, lead := range ReminderLeadTimes {
+		dueAt := showtime.StartTime.Add(-lead)
+		if !dueAt.After(now) {
+			continue
+		}
+		reminder := models.ShowtimeReminder{
+			BookingID:   bookingID,
+			ShowtimeID:  showtime.ID,
+			LeadMinutes: int(lead / time.Minute),
+			DueAt:       dueAt,
+			Status:      models.ReminderStatusPending,
+		}
+		// A pending reminder for this lead time already exists when the booking is confirmed twice
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&reminder).Error; err != nil {
+			return fmt.Errorf("failed to plan reminder: %w", err)
+		}
+	}
+	return nil
+}
+
+// cancelReminders cancels a booking's pending reminders.
+// Must be called in the transaction that releases the booking.
+func cancelReminders(tx *gorm.DB, bookingID uuid.UUID) error {
+	err := tx.Model(&models.ShowtimeReminder{}).
+		Where("booking_id = ? AND status = ?", bookingID, models.ReminderStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.ReminderStatusCancelled,
+			"last_error": "booking was cancelled",
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to cancel reminders: %w", err)
+	}
+	return nil
+}
+
+// rescheduleReminders plans the reminders of a showtime's paid bookings again after
+// it moved. Reminders already sent stay sent; new ones are planned for lead times
+// that are still ahead of the new start.
+func rescheduleReminders(tx *gorm.DB, showtimeID uint) error {
+	err := tx.Model(&models.ShowtimeReminder{}).
+		Where("showtime_id = ? AND status = ?", showtimeID, models.ReminderStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.ReminderStatusCancelled,
+			"last_error": "showtime was rescheduled",
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to cancel reminders: %w", err)
+	}
+
+	bookingIDs, err := paidBookingsForShowtime(tx, showtimeID)
+	if err != nil {
+		return err
+	}
+	for _, bookingID := range bookingIDs {
+		if err := planReminders(tx, bookingID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendDueReminders sends the pending reminders that are due. Reminders for bookings
+// that are no longer paid, showtimes that started, or that a shorter lead time has
+// overtaken (e.g. after downtime) are skipped instead.
+// Returns the number of reminders sent.
+func (rs *ReminderService) SendDueReminders(ctx context.Context) (int, error) {
+	var reminders []models.ShowtimeReminder
+	err := rs.db.WithContext(ctx).
+		Where("status = ? AND due_at <= ?", models.ReminderStatusPending, time.Now()).
+		Order("due_at ASC").
+		Limit(ReminderBatchSize).
+		Find(&reminders).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch due reminders: %w", err)
+	}
+
+	sent := 0
+	for i := range reminders {
+		if ctx.Err() != nil {
+			break
+		}
+
+		reminder := &reminders[i]
+		updates := rs.deliver(reminder)
+		if updates["status"] == models.ReminderStatusSent {
+			sent++
+		}
+		if err := rs.db.Model(reminder).Updates(updates).Error; err != nil {
+			log.Printf("[Reminders] Failed to record reminder %d: %v", reminder.ID, err)
+		}
+	}
+
+	return sent, nil
+}
+
+// deliver sends one reminder through the user's channels and returns the reminder's updates
+func (rs *ReminderService) deliver(reminder *models.ShowtimeReminder) map[string]interface{} {
+	skip := func(status, reason string) map[string]interface{} {
+		return map[string]interface{}{"status": status, "last_error": reason}
+	}
+
+	var booking models.Booking
+	if err := rs.db.Select("id", "user_id", "status").First(&booking, "id = ?", reminder.BookingID).Error; err != nil {
+		return skip(models.ReminderStatusCancelled, "booking not found")
+	}
+	if booking.Status != BookingStatusPaid {
+		return skip(models.ReminderStatusCancelled, "booking is "+strings.ToLower(booking.Status))
+	}
+
+	var showtime models.Showtime
+	if err := rs.db.Preload("Movie").Preload("Studio").First(&showtime, reminder.ShowtimeID).Error; err != nil {
+		return skip(models.ReminderStatusCancelled, "showtime not found")
+	}
+	now := time.Now()
+	if !showtime.StartTime.After(now) {
+		return skip(models.ReminderStatusSkipped, "showtime has already started")
+	}
+	if reminderSuperseded(reminder.LeadMinutes, showtime.StartTime, now) {
+		return skip(models.ReminderStatusSkipped, "a later reminder is already due")
+	}
+
+	prefs, err := rs.GetPreferences(booking.UserID)
+	if err != nil {
+		return rs.retry(reminder, err.Error())
+	}
+
+	var seats []string
+	if err := rs.db.Model(&models.Ticket{}).Where("booking_id = ?", booking.ID).
+		Order("seat_number").Pluck("seat_number", &seats).Error; err != nil {
+		return rs.retry(reminder, err.Error())
+	}
+	lead := formatLeadTime(reminder.LeadMinutes)
+	message := &Reminder{
+		BookingID:    booking.ID,
+		UserID:       booking.UserID,
+		Phone:        prefs.Phone,
+		PushEndpoint: prefs.PushEndpoint,
+		Lead:         lead,
+		Title:        fmt.Sprintf("%s starts in %s", showtime.Movie.Title, lead),
+		Body: fmt.Sprintf("%s, %s. Seats %s.", showtime.StartTime.Local().Format(documentTimeFormat),
+			showtime.Studio.Name, strings.Join(seats, ", ")),
+	}
+
+	var sentVia, failures []string
+	for _, name := range enabledChannels(prefs) {
+		channel, ok := rs.channels[name]
+		if !ok {
+			continue
+		}
+		if err := channel.Send(message); err != nil {
+			failures = append(failures, name+": "+err.Error())
+			continue
+		}
+		sentVia = append(sentVia, name)
+	}
+
+	switch {
+	case len(sentVia) > 0:
+		return map[string]interface{}{
+			"status":     models.ReminderStatusSent,
+			"channels":   strings.Join(sentVia, ","),
+			"sent_at":    now,
+			"attempts":   reminder.Attempts + 1,
+			"last_error": strings.Join(failures, "; "),
+		}
+	case len(failures) > 0:
+		log.Printf("[Reminders] Failed to send reminder %d: %s", reminder.ID, strings.Join(failures, "; "))
+		return rs.retry(reminder, strings.Join(failures, "; "))
+	default:
+		return skip(models.ReminderStatusSkipped, "no reminder channel enabled")
+	}
+}
+
+// retry schedules another attempt of a reminder, or gives up on it
+func (rs *ReminderService) retry(reminder *models.ShowtimeReminder, reason string) map[string]interface{} {
+	updates := map[string]interface{}{
+		"attempts":   reminder.Attempts + 1,
+		"last_error": reason,
+	}
+	if reminder.Attempts+1 >= MaxReminderAttempts {
+		updates["status"] = models.ReminderStatusFailed
+	} else {
+		updates["due_at"] = time.Now().Add(ReminderRetryDelay)
+	}
+	return updates
+}
+
+// reminderSuperseded reports whether a shorter lead time than leadMinutes is already
+// due, in which case only that reminder is worth sending
+func reminderSuperseded(leadMinutes int, start, now time.Time) bool {
+	for _, lead := range ReminderLeadTimes {
+		if lead < time.Duration(leadMinutes)*time.Minute && !start.Add(-lead).After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatLeadTime describes a lead time for people, e.g. "24 hours" or "30 minutes"
+func formatLeadTime(minutes int) string {
+	switch {
+	case minutes == 60:
+		return "1 hour"
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d hours", minutes/60)
+	case minutes == 1:
+		return "1 minute"
+	default:
+		return fmt.Sprintf("%d minutes", minutes)
+	}
+}
+
+// enabledChannels lists the channels a user wants reminders through
+func enabledChannels(prefs *models.NotificationPreference) []string {
+	var channels []string
+	if prefs.Email {
+		channels = append(channels, models.ReminderChannelEmail)
+	}
+	if prefs.SMS {
+		channels = append(channels, models.ReminderChannelSMS)
+	}
+	if prefs.Push {
+		channels = append(channels, models.ReminderChannelPush)
+	}
+	return channels
+}
+
+// AvailableChannels lists the reminder channels this deployment can send through
+func (rs *ReminderService) AvailableChannels() []string {
+	var names []string
+	for _, name := range []string{models.ReminderChannelEmail, models.ReminderChannelSMS, models.ReminderChannelPush} {
+		if _, ok := rs.channels[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GetPreferences returns a user's reminder preferences; email only if never set
+func (rs *ReminderService) GetPreferences(userID uuid.UUID) (*models.NotificationPreference, error) {
+	prefs := models.NotificationPreference{UserID: userID, Email: true}
+	err := rs.db.First(&prefs, "user_id = ?", userID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to fetch preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+// UpdatePreferences replaces a user's reminder preferences
+func (rs *ReminderService) UpdatePreferences(userID uuid.UUID, prefs *models.NotificationPreference) (*models.NotificationPreference, error) {
+	prefs.UserID = userID
+	prefs.Phone = strings.TrimSpace(prefs.Phone)
+	prefs.PushEndpoint = strings.TrimSpace(prefs.PushEndpoint)
+
+	for _, name := range enabledChannels(prefs) {
+		if _, ok := rs.channels[name]; !ok {
+			return nil, fmt.Errorf("%s reminders are not available", name)
+		}
+	}
+	if prefs.SMS && prefs.Phone == "" {
+		return nil, errors.New("phone is required for sms reminders")
+	}
+	if len(prefs.Phone) > 30 {
+		return nil, errors.New("phone must be at most 30 characters")
+	}
+	if prefs.Push && !strings.HasPrefix(prefs.PushEndpoint, "https://") {
+		return nil, errors.New("push_endpoint must be an https URL for push reminders")
+	}
+
+	prefs.UpdatedAt = time.Now()
+	if err := rs.db.Save(prefs).Error; err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return prefs, nil
+}
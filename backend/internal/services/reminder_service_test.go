@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReminderSuperseded(t *testing.T) {
+	start := time.Date(2026, 3, 7, 19, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		leadMinutes int
+		now         time.Time
+		want        bool
+	}{
+		{"24h reminder on time", 24 * 60, start.Add(-24 * time.Hour), false},
+		{"24h reminder late but before the 2h one", 24 * 60, start.Add(-3 * time.Hour), false},
+		{"24h reminder once the 2h one is due", 24 * 60, start.Add(-90 * time.Minute), true},
+		{"2h reminder is never superseded", 2 * 60, start.Add(-time.Minute), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reminderSuperseded(tt.leadMinutes, start, tt.now); got != tt.want {
+				t.Errorf("reminderSuperseded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatLeadTime(t *testing.T) {
+	tests := map[int]string{1440: "24 hours", 120: "2 hours", 60: "1 hour", 30: "30 minutes"}
+	for minutes, want := range tests {
+		if got := formatLeadTime(minutes); got != want {
+			t.Errorf("formatLeadTime(%d) = %q, want %q", minutes, got, want)
+		}
+	}
+}
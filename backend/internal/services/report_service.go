@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+// Sales report groupings
+const (
+	ReportGroupDay      = "day"
+	ReportGroupWeek     = "week"
+	ReportGroupMovie    = "movie"
+	ReportGroupStudio   = "studio"
+	ReportGroupSlot     = "slot"     // Hour of day the showtime starts
+	ReportGroupShowtime = "showtime" // Each showtime on its own
+)
+
+// reportGrouping is the SQL for one grouping over showtimes s, movies m and studios st.
+// s.local_start is the start time in the report time zone.
+type reportGrouping struct {
+	key, label string
+	orderBy    string // key, label or first_start
+}
+
+// reportGroupings are the groupings GetSalesReport accepts
+var reportGroupings = map[string]reportGrouping{
+	ReportGroupDay:      {"to_char(s.local_start, 'YYYY-MM-DD')", "to_char(s.local_start, 'Dy DD Mon YYYY')", "key"},
+	ReportGroupWeek:     {"to_char(s.local_start, 'IYYY-\"W\"IW')", "'Week of ' || to_char(date_trunc('week', s.local_start), 'DD Mon YYYY')", "key"},
+	ReportGroupMovie:    {"m.id::text", "m.title", "label"},
+	ReportGroupStudio:   {"st.id::text", "st.name", "label"},
+	ReportGroupSlot:     {"to_char(s.local_start, 'HH24:00')", "to_char(s.local_start, 'HH24:00')", "key"},
+	ReportGroupShowtime: {"s.id::text", "m.title || ' - ' || st.name || ' - ' || to_char(s.local_start, 'DD Mon YYYY HH24:MI')", "first_start"},
+}
+
+// reportTimeZone is the zone days, weeks and slots are counted in: REPORT_TIMEZONE,
+// else TZ (the zone showtimes are printed in), else UTC
+func reportTimeZone() string {
+	for _, key := range []string{"REPORT_TIMEZONE", "TZ"} {
+		name := os.Getenv(key)
+		if name == "" {
+			continue
+		}
+		if _, err := time.LoadLocation(name); err != nil {
+			log.Printf("[Reports] Ignoring %s=%q: %v", key, name, err)
+			continue
+		}
+		return name
+	}
+	return "UTC"
+}
+
+// ReportService computes admin sales reports
+type ReportService struct {
+	db *gorm.DB
+}
+
+// SalesReportRow is the sales of one group of showtimes.
+// Revenue is the tickets' list price; NetRevenue is after promo code discounts.
+type SalesReportRow struct {
+	Key                string      `json:"key"`
+	Label              string      `json:"label"`
+	Showtimes          int64       `json:"showtimes"`
+	TicketsSold        int64       `json:"tickets_sold"`
+	Capacity           int64       `json:"capacity"`
+	OccupancyPercent   float64     `json:"occupancy_percent"`
+	Revenue            money.Money `json:"revenue"`
+	Discounts          money.Money `json:"discounts"`
+	NetRevenue         money.Money `json:"net_revenue"`
+	AverageTicketPrice money.Money `json:"average_ticket_price"` // Net revenue per ticket
+}
+
+// NewReportService creates a new report service
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+// GetSalesReport aggregates the paid tickets of showtimes starting in [from, to) by groupBy.
+// Capacity counts the studio's sellable seats for every showtime in the group, so showtimes
+// that sold nothing still lower the occupancy. Either bound may be nil.
+func (s *ReportService) GetSalesReport(groupBy string, from, to *time.Time) ([]SalesReportRow, error) {
+	grouping, ok := reportGroupings[groupBy]
+	if !ok {
+		return nil, errors.New("group_by must be day, week, movie, studio, slot or showtime")
+	}
+
+	where := "s.deleted_at IS NULL"
+	var whereArgs []interface{}
+	if from != nil {
+		where += " AND s.start_time >= ?"
+		whereArgs = append(whereArgs, *from)
+	}
+	if to != nil {
+		where += " AND s.start_time < ?"
+		whereArgs = append(whereArgs, *to)
+	}
+
+	args := append([]interface{}{reportTimeZone()}, whereArgs...)
+	args = append(args, BookingStatusPaid, models.SeatStateActive)
+
+	// Each ticket carries its share of the booking's discount, in proportion to its list price
+	// against the booking's ticket gross (total + discount - concession items): promo codes
+	// only discount tickets, so concession lines must not dilute the share.
+	query := fmt.Sprintf(`
+		WITH shows AS (
+			SELECT s.*, s.start_time AT TIME ZONE ? AS local_start
+			FROM showtimes s
+			WHERE %[3]s
+		), items AS (
+			SELECT booking_id, SUM(total_price) AS total
+			FROM booking_items
+			GROUP BY booking_id
+		), sold AS (
+			SELECT t.showtime_id,
+				COUNT(*) AS tickets,
+				SUM(t.price) AS revenue,
+				SUM(ROUND(b.discount_amount::numeric * t.price / NULLIF(b.total_amount + b.discount_amount - COALESCE(items.total, 0), 0))) AS discounts
+			FROM tickets t
+			JOIN bookings b ON b.id = t.booking_id
+			LEFT JOIN items ON items.booking_id = b.id
+			WHERE b.status = ? AND t.showtime_id IN (SELECT id FROM shows)
+			GROUP BY t.showtime_id
+		), capacity AS (
+			SELECT studio_id, COUNT(*) AS seats
+			FROM studio_seats
+			WHERE state = ?
+			GROUP BY studio_id
+		), grouped AS (
+			SELECT %[1]s AS key,
+				MIN(%[2]s) AS label,
+				MIN(s.start_time) AS first_start,
+				COUNT(*) AS showtimes,
+				COALESCE(SUM(sold.tickets), 0) AS tickets_sold,
+				COALESCE(SUM(capacity.seats), 0) AS capacity,
+				COALESCE(SUM(sold.revenue), 0) AS revenue,
+				COALESCE(SUM(sold.discounts), 0) AS discounts
+			FROM shows s
+			JOIN movies m ON m.id = s.movie_id
+			JOIN studios st ON st.id = s.studio_id
+			LEFT JOIN sold ON sold.showtime_id = s.id
+			LEFT JOIN capacity ON capacity.studio_id = s.studio_id
+			GROUP BY 1
+		)
+		SELECT key, label, showtimes, tickets_sold, capacity,
+			COALESCE(ROUND(100.0 * tickets_sold / NULLIF(capacity, 0), 2), 0)::float8 AS occupancy_percent,
+			revenue::bigint AS revenue,
+			discounts::bigint AS discounts,
+			(revenue - discounts)::bigint AS net_revenue,
+			COALESCE(ROUND((revenue - discounts) / NULLIF(tickets_sold, 0)), 0)::bigint AS average_ticket_price
+		FROM grouped
+		ORDER BY %[4]s, key
+	`, grouping.key, grouping.label, where, grouping.orderBy)
+
+	var rows []SalesReportRow
+	if err := s.db.Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute sales report: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+func TestGetSalesReport(t *testing.T) {
+	db := newTestDB(t, &models.User{}, &models.Movie{}, &models.Studio{}, &models.StudioSeat{},
+		&models.Showtime{}, &models.Booking{}, &models.Ticket{}, &models.SeatHold{}, &models.BookingItem{})
+	t.Setenv("REPORT_TIMEZONE", "Asia/Jakarta")
+
+	user := models.User{Username: "report", Email: "report@example.com", Password: "x"}
+	movie := models.Movie{Title: "Report", DurationMinutes: 100}
+	studio := models.Studio{Name: "Studio 1", TotalRows: 1, TotalCols: 3} // 3 sellable seats
+	for _, record := range []interface{}{&user, &movie, &studio} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	// 18:00 UTC on the 14th is 01:00 on the 15th in Jakarta
+	start := time.Date(2026, 3, 14, 18, 0, 0, 0, time.UTC)
+	showtime := models.Showtime{MovieID: movie.ID, StudioID: studio.ID, StartTime: start,
+		EndTime: start.Add(2 * time.Hour), Price: money.FromMajor(50000)}
+	if err := db.Create(&showtime).Error; err != nil {
+		t.Fatalf("seed showtime: %v", err)
+	}
+
+	// Two tickets (Rp 60,000 + Rp 40,000) and Rp 50,000 of popcorn with a Rp 10,000 promo code.
+	// The whole discount falls on the tickets.
+	booking := models.Booking{UserID: user.ID, InvoiceNumber: "INV/1", Status: BookingStatusPaid,
+		TotalAmount: money.FromMajor(140000), DiscountAmount: money.FromMajor(10000),
+		Tickets: []models.Ticket{
+			{ShowtimeID: showtime.ID, SeatNumber: "A1", Price: money.FromMajor(60000)},
+			{ShowtimeID: showtime.ID, SeatNumber: "A2", Price: money.FromMajor(40000)},
+		},
+		Items: []models.BookingItem{{ProductID: 1, ProductName: "Popcorn", Cinema: "DEFAULT", Quantity: 1,
+			UnitPrice: money.FromMajor(50000), TotalPrice: money.FromMajor(50000)}},
+	}
+	if err := db.Create(&booking).Error; err != nil {
+		t.Fatalf("seed booking: %v", err)
+	}
+
+	rows, err := NewReportService(db).GetSalesReport(ReportGroupDay, nil, nil)
+	if err != nil {
+		t.Fatalf("GetSalesReport: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	row := rows[0]
+	if row.Key != "2026-03-15" {
+		t.Errorf("key = %s, want the Jakarta date 2026-03-15", row.Key)
+	}
+	if row.TicketsSold != 2 || row.Capacity != 3 || row.OccupancyPercent != 66.67 {
+		t.Errorf("tickets %d, capacity %d, occupancy %v; want 2, 3, 66.67", row.TicketsSold, row.Capacity, row.OccupancyPercent)
+	}
+	if !row.Revenue.Equal(money.FromMajor(100000)) || !row.Discounts.Equal(money.FromMajor(10000)) ||
+		!row.NetRevenue.Equal(money.FromMajor(90000)) || !row.AverageTicketPrice.Equal(money.FromMajor(45000)) {
+		t.Errorf("revenue %s, discounts %s, net %s, average %s; want 100000, 10000, 90000, 45000",
+			row.Revenue, row.Discounts, row.NetRevenue, row.AverageTicketPrice)
+	}
+}
+
+func TestReportTimeZone(t *testing.T) {
+	t.Setenv("REPORT_TIMEZONE", "Asia/Jakarta")
+	t.Setenv("TZ", "Europe/Amsterdam")
+	if got := reportTimeZone(); got != "Asia/Jakarta" {
+		t.Errorf("reportTimeZone() = %q, want REPORT_TIMEZONE", got)
+	}
+
+	t.Setenv("REPORT_TIMEZONE", "Not/AZone")
+	if got := reportTimeZone(); got != "Europe/Amsterdam" {
+		t.Errorf("reportTimeZone() = %q, want TZ when REPORT_TIMEZONE is invalid", got)
+	}
+
+	t.Setenv("REPORT_TIMEZONE", "")
+	t.Setenv("TZ", "")
+	if got := reportTimeZone(); got != "UTC" {
+		t.Errorf("reportTimeZone() = %q, want UTC", got)
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+// SeatCategoryService handles seat categories and per-showtime category pricing
+type SeatCategoryService struct {
+	db *gorm.DB
+}
+
+// CategoryPrice is the price of a seat category for a showtime
+type CategoryPrice struct {
+	SeatCategoryID uint        `json:"seat_category_id" binding:"required"`
+	Price          money.Money `json:"price"`
+}
+
+// SetShowtimePricesRequest replaces all category prices of a showtime
+type SetShowtimePricesRequest struct {
+	Prices []CategoryPrice `json:"prices" binding:"dive"`
+}
+
+// SeatPrice is the resolved price of one seat for one showtime
+type SeatPrice struct {
+	Price    money.Money `json:"price"`
+	Category string      `json:"category,omitempty"` // Category name; empty for regular seats
+}
+
+// NewSeatCategoryService creates a new seat category service
+func NewSeatCategoryService(db *gorm.DB) *SeatCategoryService {
+	return &SeatCategoryService{db: db}
+}
+
+// CreateCategory creates a new seat category
+func (s *SeatCategoryService) CreateCategory(category *models.SeatCategory) error {
+	if err := s.validateCategory(category); err != nil {
+		return err
+	}
+	return s.db.Create(category).Error
+}
+
+// GetAllCategories retrieves all seat categories
+func (s *SeatCategoryService) GetAllCategories() ([]models.SeatCategory, error) {
+	var categories []models.SeatCategory
+	if err := s.db.Order("id ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// UpdateCategory updates an existing seat category
+func (s *SeatCategoryService) UpdateCategory(id uint, updates *models.SeatCategory) error {
+	if err := s.validateCategory(updates); err != nil {
+		return err
+	}
+
+	var category models.SeatCategory
+	if err := s.db.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("seat category not found")
+		}
+		return err
+	}
+
+	category.Code = updates.Code
+	category.Name = updates.Name
+	category.Description = updates.Description
+
+	return s.db.Save(&category).Error
+}
+
+// DeleteCategory soft deletes a seat category; its seats become regular seats
+func (s *SeatCategoryService) DeleteCategory(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.SeatCategory{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("seat category not found")
+		}
+
+		return tx.Model(&models.StudioSeat{}).
+			Where("category_id = ?", id).
+			Update("category_id", nil).Error
+	})
+}
+
+// GetShowtimePrices retrieves the category prices configured for a showtime
+func (s *SeatCategoryService) GetShowtimePrices(showtimeID uint) ([]models.ShowtimeSeatPrice, error) {
+	var prices []models.ShowtimeSeatPrice
+	if err := s.db.Preload("SeatCategory").
+		Where("showtime_id = ?", showtimeID).
+		Order("seat_category_id ASC").
+		Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// SetShowtimePrices replaces the category prices of a showtime
+func (s *SeatCategoryService) SetShowtimePrices(showtimeID uint, prices []CategoryPrice) error {
+	var showtime models.Showtime
+	if err := s.db.First(&showtime, showtimeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("showtime not found")
+		}
+		return err
+	}
+
+	seen := make(map[uint]bool)
+	for _, p := range prices {
+		if seen[p.SeatCategoryID] {
+			return fmt.Errorf("duplicate price for seat category %d", p.SeatCategoryID)
+		}
+		seen[p.SeatCategoryID] = true
+
+		if !p.Price.IsPositive() {
+			return errors.New("price must be greater than 0")
+		}
+
+		var count int64
+		if err := s.db.Model(&models.SeatCategory{}).Where("id = ?", p.SeatCategoryID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("seat category not found")
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("showtime_id = ?", showtimeID).Delete(&models.ShowtimeSeatPrice{}).Error; err != nil {
+			return fmt.Errorf("failed to clear showtime prices: %w", err)
+		}
+
+		for _, p := range prices {
+			price := models.ShowtimeSeatPrice{
+				ShowtimeID:     showtimeID,
+				SeatCategoryID: p.SeatCategoryID,
+				Price:          p.Price,
+			}
+			if err := tx.Omit("Showtime", "SeatCategory").Create(&price).Error; err != nil {
+				return fmt.Errorf("failed to save showtime price: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetSeatPriceMap resolves the price of every bookable seat in a showtime's studio
+func (s *SeatCategoryService) GetSeatPriceMap(showtime *models.Showtime) (map[string]SeatPrice, error) {
+	return resolveSeatPrices(s.db, showtime, nil)
+}
+
+// validateCategory validates seat category data
+func (s *SeatCategoryService) validateCategory(category *models.SeatCategory) error {
+	category.Code = strings.ToUpper(strings.TrimSpace(category.Code))
+	if category.Code == "" {
+		return errors.New("seat category code is required")
+	}
+	if category.Name == "" {
+		return errors.New("seat category name is required")
+	}
+	return nil
+}
+
+// resolveSeatPrices returns the price of each seat for a showtime.
+// When labels is nil every bookable seat in the studio is priced.
+// A seat costs its category's showtime price, falling back to Showtime.Price.
+func resolveSeatPrices(db *gorm.DB, showtime *models.Showtime, labels []string) (map[string]SeatPrice, error) {
+	query := db.Preload("Category").
+		Where("studio_id = ? AND state = ?", showtime.StudioID, models.SeatStateActive)
+	if labels != nil {
+		query = query.Where("label IN ?", labels)
+	}
+
+	var seats []models.StudioSeat
+	if err := query.Find(&seats).Error; err != nil {
+		return nil, fmt.Errorf("failed to load seat map: %w", err)
+	}
+
+	var categoryPrices []models.ShowtimeSeatPrice
+	if err := db.Where("showtime_id = ?", showtime.ID).Find(&categoryPrices).Error; err != nil {
+		return nil, fmt.Errorf("failed to load showtime prices: %w", err)
+	}
+
+	priceByCategory := make(map[uint]money.Money, len(categoryPrices))
+	for _, cp := range categoryPrices {
+		priceByCategory[cp.SeatCategoryID] = cp.Price
+	}
+
+	prices := make(map[string]SeatPrice, len(seats))
+	for _, seat := range seats {
+		sp := SeatPrice{Price: showtime.Price}
+		if seat.Category != nil {
+			sp.Category = seat.Category.Name
+			if price, ok := priceByCategory[seat.Category.ID]; ok {
+				sp.Price = price
+			}
+		}
+		prices[seat.Label] = sp
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+
+	"absolutcinema-backend/internal/database"
+	"absolutcinema-backend/internal/models"
+)
+
+func TestSeatCategoryCodeReusableAfterDelete(t *testing.T) {
+	db := newTestDB(t, database.Models()...)
+	s := NewSeatCategoryService(db)
+
+	first := models.SeatCategory{Code: "IMAX", Name: "IMAX"}
+	if err := s.CreateCategory(&first); err != nil {
+		t.Fatalf("CreateCategory: %v", err)
+	}
+	if err := s.CreateCategory(&models.SeatCategory{Code: "IMAX", Name: "Duplicate"}); err == nil {
+		t.Fatal("a second live category with the same code was created")
+	}
+
+	if err := s.DeleteCategory(first.ID); err != nil {
+		t.Fatalf("DeleteCategory: %v", err)
+	}
+	if err := s.CreateCategory(&models.SeatCategory{Code: "IMAX", Name: "IMAX"}); err != nil {
+		t.Errorf("code of a deleted category can't be reused: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+const (
+	// SeatEventsChannel is the Postgres NOTIFY channel carrying seat changes
+	SeatEventsChannel = "seat_events"
+
+	// Seat event types
+	SeatEventTaken    = "taken"
+	SeatEventReleased = "released"
+	SeatEventResync   = "resync" // Sent locally after the listener reconnects; clients should refetch
+
+	// Seat event reasons
+	SeatReasonBooked    = "booked"
+	SeatReasonHeld      = "held"
+	SeatReasonCancelled = "cancelled"
+	SeatReasonExpired   = "expired"
+	SeatReasonRefunded  = "refunded"
+
+	// seatSubscriberBuffer is how many events a slow client may lag behind before it is dropped
+	seatSubscriberBuffer = 32
+)
+
+// SeatEvent describes seats that became taken or available for a showtime
+type SeatEvent struct {
+	Type        string    `json:"type"`
+	Reason      string    `json:"reason,omitempty"`
+	ShowtimeID  uint      `json:"showtime_id"`
+	SeatNumbers []string  `json:"seat_numbers"`
+	At          time.Time `json:"at"`
+}
+
+// publishSeatEvent queues a seat event on the Postgres NOTIFY channel.
+// NOTIFY is transactional: when called with a transaction the event is only
+// delivered if that transaction commits, so listeners never see phantom changes.
+func publishSeatEvent(tx *gorm.DB, eventType, reason string, showtimeID uint, seatNumbers []string) error {
+	if len(seatNumbers) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(SeatEvent{
+		Type:        eventType,
+		Reason:      reason,
+		ShowtimeID:  showtimeID,
+		SeatNumbers: seatNumbers,
+		At:          time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode seat event: %w", err)
+	}
+
+	if err := tx.Exec("SELECT pg_notify(?, ?)", SeatEventsChannel, string(payload)).Error; err != nil {
+		return fmt.Errorf("failed to publish seat event: %w", err)
+	}
+	return nil
+}
+
+// SeatEventHub listens for seat events on Postgres and fans them out to
+// subscribers of each showtime. Every backend instance runs its own hub, so
+// events raised on any instance reach clients connected to all of them.
+type SeatEventHub struct {
+	db *gorm.DB
+
+	mu          sync.Mutex
+	subscribers map[uint]map[chan SeatEvent]struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSeatEventHub creates a new seat event hub
+func NewSeatEventHub(db *gorm.DB) *SeatEventHub {
+	return &SeatEventHub{
+		db:          db,
+		subscribers: make(map[uint]map[chan SeatEvent]struct{}),
+	}
+}
+
+// Start begins listening for notifications in the background
+func (h *SeatEventHub) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.done = make(chan struct{})
+
+	go h.listen(ctx)
+}
+
+// Stop stops listening and closes every subscriber channel so streaming handlers return
+func (h *SeatEventHub) Stop() {
+	if h.cancel == nil {
+		return
+	}
+	h.cancel()
+	<-h.done
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for showtimeID, subs := range h.subscribers {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(h.subscribers, showtimeID)
+	}
+}
+
+// Subscribe registers interest in a showtime's seat events.
+// The returned channel is closed when the hub stops or the subscriber falls too far
+// behind; call the returned function to unsubscribe.
+func (h *SeatEventHub) Subscribe(showtimeID uint) (<-chan SeatEvent, func()) {
+	ch := make(chan SeatEvent, seatSubscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[showtimeID] == nil {
+		h.subscribers[showtimeID] = make(map[chan SeatEvent]struct{})
+	}
+	h.subscribers[showtimeID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(showtimeID, ch)
+	}
+
+	return ch, unsubscribe
+}
+
+// remove drops a subscriber; caller must hold h.mu
+func (h *SeatEventHub) remove(showtimeID uint, ch chan SeatEvent) {
+	subs := h.subscribers[showtimeID]
+	if _, ok := subs[ch]; !ok {
+		return
+	}
+	delete(subs, ch)
+	close(ch)
+	if len(subs) == 0 {
+		delete(h.subscribers, showtimeID)
+	}
+}
+
+// broadcast delivers an event to every subscriber of its showtime
+func (h *SeatEventHub) broadcast(event SeatEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[event.ShowtimeID] {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is not keeping up; drop it so the client reconnects and refetches
+			h.remove(event.ShowtimeID, ch)
+		}
+	}
+}
+
+// broadcastResync tells every subscriber that events may have been missed
+func (h *SeatEventHub) broadcastResync() {
+	h.mu.Lock()
+	showtimeIDs := make([]uint, 0, len(h.subscribers))
+	for showtimeID := range h.subscribers {
+		showtimeIDs = append(showtimeIDs, showtimeID)
+	}
+	h.mu.Unlock()
+
+	for _, showtimeID := range showtimeIDs {
+		h.broadcast(SeatEvent{Type: SeatEventResync, ShowtimeID: showtimeID, At: time.Now().UTC()})
+	}
+}
+
+// listen keeps a dedicated connection LISTENing, reconnecting with backoff on failure
+func (h *SeatEventHub) listen(ctx context.Context) {
+	defer close(h.done)
+
+	backoff := time.Second
+	connected := false
+
+	for ctx.Err() == nil {
+		err := h.listenOnce(ctx, func() {
+			if connected {
+				// Notifications sent while we were disconnected are lost
+				h.broadcastResync()
+			}
+			connected = true
+			backoff = time.Second
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("[SeatEvents] Listener disconnected: %v (retrying in %s)", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// listenOnce holds one dedicated connection for LISTEN until it fails or ctx is cancelled
+func (h *SeatEventHub) listenOnce(ctx context.Context, onListening func()) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("seat events require the pgx driver")
+		}
+		pgxConn := stdConn.Conn()
+
+		// Close the underlying connection on exit so the pool discards it instead of
+		// handing out a connection that is still LISTENing
+		defer pgxConn.Close(context.Background())
+
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+SeatEventsChannel); err != nil {
+			return err
+		}
+		onListening()
+
+		for {
+			notification, err := pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+
+			var event SeatEvent
+			if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+				log.Printf("[SeatEvents] Ignoring malformed payload: %v", err)
+				continue
+			}
+			h.broadcast(event)
+		}
+	})
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"absolutcinema-backend/internal/models"
@@ -138,6 +140,13 @@ func (s *ShowtimeService) UpdateShowtime(id uint, updates *models.Showtime) erro
 		return err
 	}
 
+	// Remember what customers were told before the change
+	previous := showtime
+	var previousStudio models.Studio
+	if err := s.db.First(&previousStudio, showtime.StudioID).Error; err != nil {
+		return err
+	}
+
 	// Update fields
 	showtime.MovieID = updates.MovieID
 	showtime.StudioID = updates.StudioID
@@ -145,7 +154,55 @@ func (s *ShowtimeService) UpdateShowtime(id uint, updates *models.Showtime) erro
 	showtime.EndTime = newEndTime
 	showtime.Price = updates.Price
 
-	return s.db.Save(&showtime).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&showtime).Error; err != nil {
+			return err
+		}
+
+		if !previous.StartTime.Equal(showtime.StartTime) {
+			if err := rescheduleReminders(tx, showtime.ID); err != nil {
+				return err
+			}
+		}
+
+		if previous.MovieID == showtime.MovieID && previous.StudioID == showtime.StudioID &&
+			previous.StartTime.Equal(showtime.StartTime) {
+			return nil
+		}
+		return notifyShowtimeChanged(tx, &previous, previousStudio.Name)
+	})
+}
+
+// notifyShowtimeChanged emails the holders of paid bookings for a showtime that was moved
+func notifyShowtimeChanged(tx *gorm.DB, previous *models.Showtime, previousStudio string) error {
+	bookingIDs, err := paidBookingsForShowtime(tx, previous.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, bookingID := range bookingIDs {
+		err := enqueueBookingEmail(tx, models.EmailShowtimeChanged, bookingID, func(data *emailData) {
+			data.PreviousStart = previous.StartTime.Local().Format(documentTimeFormat)
+			data.PreviousStudio = previousStudio
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// paidBookingsForShowtime lists the paid bookings holding tickets for a showtime
+func paidBookingsForShowtime(tx *gorm.DB, showtimeID uint) ([]uuid.UUID, error) {
+	var bookingIDs []uuid.UUID
+	if err := tx.Model(&models.Booking{}).
+		Distinct("bookings.id").
+		Joins("JOIN tickets ON tickets.booking_id = bookings.id").
+		Where("tickets.showtime_id = ? AND bookings.status = ?", showtimeID, BookingStatusPaid).
+		Pluck("bookings.id", &bookingIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch bookings: %w", err)
+	}
+	return bookingIDs, nil
 }
 
 // DeleteShowtime soft deletes a showtime
@@ -179,7 +236,7 @@ func (s *ShowtimeService) validateShowtime(showtime *models.Showtime) error {
 		return errors.New("start_time must be in the future")
 	}
 
-	if showtime.Price <= 0 {
+	if !showtime.Price.IsPositive() {
 		return errors.New("price must be greater than 0")
 	}
 
@@ -2,12 +2,43 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
 	"absolutcinema-backend/internal/models"
 )
 
+const (
+	// MaxLayoutGridSize bounds the seat map grid in each direction
+	MaxLayoutGridSize = 50
+)
+
+// LayoutSeat represents one seat in a layout editor request
+type LayoutSeat struct {
+	Label    string `json:"label"` // Defaults to RowLabel + Number
+	RowLabel string `json:"row_label" binding:"required"`
+	Number   int    `json:"number" binding:"required,min=1"`
+	GridRow  int    `json:"grid_row" binding:"min=0"`
+	GridCol  int    `json:"grid_col" binding:"min=0"`
+	State    string `json:"state"` // ACTIVE (default), BLOCKED or REMOVED
+
+	CategoryID *uint `json:"category_id"` // Seat category for pricing; nil for regular seats
+}
+
+// UpdateLayoutRequest replaces a studio's whole seat map
+type UpdateLayoutRequest struct {
+	Seats []LayoutSeat `json:"seats" binding:"required,min=1,dive"`
+}
+
+// UpdateSeatStateRequest changes the state of a single seat
+type UpdateSeatStateRequest struct {
+	State string `json:"state" binding:"required"`
+}
+
 type StudioService struct {
 	db *gorm.DB
 }
@@ -47,8 +78,10 @@ func (s *StudioService) GetStudioByID(id uint) (*models.Studio, error) {
 	return &studio, nil
 }
 
-// UpdateStudio updates an existing studio
-func (s *StudioService) UpdateStudio(id uint, updates *models.Studio) error {
+// UpdateStudio updates an existing studio. Changing the size of a studio whose seat map
+// was edited in the layout editor would throw that layout away, so it is refused unless
+// resetLayout asks for a full rectangle of the new size.
+func (s *StudioService) UpdateStudio(id uint, updates *models.Studio, resetLayout bool) error {
 	// Validation
 	if err := s.validateStudio(updates); err != nil {
 		return err
@@ -63,12 +96,35 @@ func (s *StudioService) UpdateStudio(id uint, updates *models.Studio) error {
 		return err
 	}
 	
+	// Resizing the grid resets the seat map to a full rectangle of the new size.
+	// Custom shapes are edited through the layout editor instead.
+	resized := studio.TotalRows != updates.TotalRows || studio.TotalCols != updates.TotalCols
+	if resized && !resetLayout {
+		var seats []models.StudioSeat
+		if err := s.db.Where("studio_id = ?", studio.ID).Find(&seats).Error; err != nil {
+			return err
+		}
+		if !isDefaultGrid(seats, studio.TotalRows, studio.TotalCols) {
+			return errors.New("studio has a custom seat layout; resizing it requires reset_layout=true")
+		}
+	}
+
 	// Update fields
 	studio.Name = updates.Name
 	studio.TotalRows = updates.TotalRows
 	studio.TotalCols = updates.TotalCols
-	
-	return s.db.Save(&studio).Error
+	studio.BillingEntity = updates.BillingEntity
+
+	if !resized {
+		return s.db.Save(&studio).Error
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&studio).Error; err != nil {
+			return err
+		}
+		return replaceSeats(tx, &studio, models.GenerateGridSeats(studio.ID, studio.TotalRows, studio.TotalCols))
+	})
 }
 
 // DeleteStudio soft deletes a studio
@@ -89,13 +145,285 @@ func (s *StudioService) validateStudio(studio *models.Studio) error {
 		return errors.New("studio name is required")
 	}
 	
-	if studio.TotalRows <= 0 || studio.TotalRows > 20 {
-		return errors.New("total rows must be between 1 and 20")
+	// Same bound as the layout editor, which sets these from the seat map
+	if studio.TotalRows <= 0 || studio.TotalRows > MaxLayoutGridSize {
+		return fmt.Errorf("total rows must be between 1 and %d", MaxLayoutGridSize)
 	}
 	
-	if studio.TotalCols <= 0 || studio.TotalCols > 20 {
-		return errors.New("total columns must be between 1 and 20")
+	if studio.TotalCols <= 0 || studio.TotalCols > MaxLayoutGridSize {
+		return fmt.Errorf("total columns must be between 1 and %d", MaxLayoutGridSize)
+	}
+
+	studio.BillingEntity = strings.ToUpper(strings.TrimSpace(studio.BillingEntity))
+	if studio.BillingEntity != "" && !billingEntityPattern.MatchString(studio.BillingEntity) {
+		return errors.New("billing entity must be 1-50 letters, digits, '-' or '_'")
 	}
 	
 	return nil
 }
+
+// GetStudioWithSeats retrieves a studio with its seat map ordered by grid position
+func (s *StudioService) GetStudioWithSeats(id uint) (*models.Studio, error) {
+	var studio models.Studio
+	if err := s.db.
+		Preload("Seats", func(db *gorm.DB) *gorm.DB {
+			return db.Order("grid_row ASC, grid_col ASC")
+		}).
+		Preload("Seats.Category").
+		First(&studio, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("studio not found")
+		}
+		return nil, err
+	}
+	return &studio, nil
+}
+
+// ReplaceLayout replaces a studio's seat map with the given seats.
+// Grid dimensions are derived from the furthest seat positions.
+func (s *StudioService) ReplaceLayout(id uint, layout []LayoutSeat) error {
+	var studio models.Studio
+	if err := s.db.First(&studio, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("studio not found")
+		}
+		return err
+	}
+
+	seats, err := buildLayoutSeats(studio.ID, layout)
+	if err != nil {
+		return err
+	}
+
+	// Every referenced seat category must exist
+	categoryIDs := make(map[uint]bool)
+	for _, seat := range seats {
+		if seat.CategoryID != nil {
+			categoryIDs[*seat.CategoryID] = true
+		}
+	}
+	for categoryID := range categoryIDs {
+		var count int64
+		if err := s.db.Model(&models.SeatCategory{}).Where("id = ?", categoryID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("seat category %d not found", categoryID)
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return replaceSeats(tx, &studio, seats)
+	})
+}
+
+// UpdateSeatState blocks, unblocks or removes a single seat
+func (s *StudioService) UpdateSeatState(studioID uint, label string, state string) error {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	if !isValidSeatState(state) {
+		return fmt.Errorf("invalid seat state: %s", state)
+	}
+
+	label = strings.ToUpper(strings.TrimSpace(label))
+
+	var seat models.StudioSeat
+	if err := s.db.Where("studio_id = ? AND label = ?", studioID, label).First(&seat).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("seat not found")
+		}
+		return err
+	}
+
+	if state == models.SeatStateRemoved {
+		if err := checkSeatsWithoutUpcomingTickets(s.db, studioID, []string{label}); err != nil {
+			return err
+		}
+	}
+
+	return s.db.Model(&seat).Update("state", state).Error
+}
+
+// buildLayoutSeats validates a layout request and converts it to seat records
+func buildLayoutSeats(studioID uint, layout []LayoutSeat) ([]models.StudioSeat, error) {
+	seats := make([]models.StudioSeat, 0, len(layout))
+	labels := make(map[string]bool)
+	cells := make(map[[2]int]bool)
+	hasActive := false
+
+	for _, ls := range layout {
+		rowLabel := strings.ToUpper(strings.TrimSpace(ls.RowLabel))
+		label := strings.ToUpper(strings.TrimSpace(ls.Label))
+		if label == "" {
+			label = rowLabel + strconv.Itoa(ls.Number)
+		}
+		if len(label) > 10 || len(rowLabel) > 5 {
+			return nil, fmt.Errorf("invalid seat label: %s", label)
+		}
+
+		state := strings.ToUpper(strings.TrimSpace(ls.State))
+		if state == "" {
+			state = models.SeatStateActive
+		}
+		if !isValidSeatState(state) {
+			return nil, fmt.Errorf("invalid seat state for %s: %s", label, state)
+		}
+
+		if ls.GridRow < 0 || ls.GridRow >= MaxLayoutGridSize || ls.GridCol < 0 || ls.GridCol >= MaxLayoutGridSize {
+			return nil, fmt.Errorf("seat %s is outside the %dx%d grid", label, MaxLayoutGridSize, MaxLayoutGridSize)
+		}
+
+		if labels[label] {
+			return nil, fmt.Errorf("duplicate seat label: %s", label)
+		}
+		cell := [2]int{ls.GridRow, ls.GridCol}
+		if cells[cell] {
+			return nil, fmt.Errorf("seat %s overlaps another seat at row %d, col %d", label, ls.GridRow, ls.GridCol)
+		}
+		labels[label] = true
+		cells[cell] = true
+
+		if state == models.SeatStateActive {
+			hasActive = true
+		}
+
+		seats = append(seats, models.StudioSeat{
+			StudioID: studioID,
+			Label:    label,
+			RowLabel: rowLabel,
+			Number:   ls.Number,
+			GridRow:  ls.GridRow,
+			GridCol:  ls.GridCol,
+			State:    state,
+
+			CategoryID: ls.CategoryID,
+		})
+	}
+
+	if !hasActive {
+		return nil, errors.New("layout must contain at least one active seat")
+	}
+
+	return seats, nil
+}
+
+// replaceSeats swaps a studio's seat map and updates its grid dimensions.
+// Must be called inside a transaction.
+func replaceSeats(tx *gorm.DB, studio *models.Studio, seats []models.StudioSeat) error {
+	// Seats that disappear (or become REMOVED) must not have tickets for upcoming showtimes
+	kept := make([]string, 0, len(seats))
+	for _, seat := range seats {
+		if seat.State != models.SeatStateRemoved {
+			kept = append(kept, seat.Label)
+		}
+	}
+
+	var dropped []string
+	if err := tx.Model(&models.StudioSeat{}).
+		Where("studio_id = ? AND label NOT IN ?", studio.ID, kept).
+		Pluck("label", &dropped).Error; err != nil {
+		return fmt.Errorf("failed to compare layouts: %w", err)
+	}
+	if err := checkSeatsWithoutUpcomingTickets(tx, studio.ID, dropped); err != nil {
+		return err
+	}
+
+	if err := tx.Where("studio_id = ?", studio.ID).Delete(&models.StudioSeat{}).Error; err != nil {
+		return fmt.Errorf("failed to clear seat map: %w", err)
+	}
+	if err := tx.Create(&seats).Error; err != nil {
+		return fmt.Errorf("failed to save seat map: %w", err)
+	}
+
+	rows, cols := 0, 0
+	for _, seat := range seats {
+		rows = max(rows, seat.GridRow+1)
+		cols = max(cols, seat.GridCol+1)
+	}
+
+	return tx.Model(studio).Updates(map[string]interface{}{
+		"total_rows": rows,
+		"total_cols": cols,
+	}).Error
+}
+
+// checkSeatsWithoutUpcomingTickets fails if any of the labels are sold for a future showtime in the studio
+func checkSeatsWithoutUpcomingTickets(db *gorm.DB, studioID uint, labels []string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	var sold []string
+	err := db.Model(&models.Ticket{}).
+		Distinct("tickets.seat_number").
+		Joins("JOIN showtimes ON showtimes.id = tickets.showtime_id").
+		Where("showtimes.studio_id = ? AND showtimes.start_time > ? AND showtimes.deleted_at IS NULL", studioID, time.Now()).
+		Where("tickets.seat_number IN ?", labels).
+		Pluck("tickets.seat_number", &sold).Error
+	if err != nil {
+		return fmt.Errorf("failed to check sold seats: %w", err)
+	}
+
+	if len(sold) > 0 {
+		return fmt.Errorf("seat %s has tickets for upcoming showtimes", sold[0])
+	}
+	return nil
+}
+
+// validateSeatsAgainstLayout checks that every seat exists in the studio's seat map and is sellable
+func validateSeatsAgainstLayout(db *gorm.DB, studioID uint, seatNumbers []string) error {
+	labels := removeDuplicateSeats(seatNumbers)
+	for _, label := range labels {
+		if label == "" {
+			return errors.New("invalid seat number format: empty seat number")
+		}
+	}
+
+	var seats []models.StudioSeat
+	if err := db.Where("studio_id = ? AND label IN ?", studioID, labels).Find(&seats).Error; err != nil {
+		return fmt.Errorf("failed to load seat map: %w", err)
+	}
+
+	byLabel := make(map[string]models.StudioSeat, len(seats))
+	for _, seat := range seats {
+		byLabel[seat.Label] = seat
+	}
+
+	for _, label := range labels {
+		seat, ok := byLabel[label]
+		if !ok || seat.State == models.SeatStateRemoved {
+			return fmt.Errorf("seat %s does not exist in this studio", label)
+		}
+		if !seat.IsBookable() {
+			return fmt.Errorf("seat %s is not available for booking", label)
+		}
+	}
+
+	return nil
+}
+
+// isDefaultGrid reports whether seats are exactly the full rows x cols rectangle that
+// GenerateGridSeats builds: every seat active, in place and without a seat category
+func isDefaultGrid(seats []models.StudioSeat, rows, cols int) bool {
+	if len(seats) != rows*cols {
+		return false
+	}
+	for _, seat := range seats {
+		if seat.GridRow >= rows || seat.GridCol >= cols {
+			return false
+		}
+		label := models.GridRowLabel(seat.GridRow) + strconv.Itoa(seat.GridCol+1)
+		if seat.Label != label || seat.State != models.SeatStateActive || seat.CategoryID != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidSeatState checks a seat state against the known values
+func isValidSeatState(state string) bool {
+	switch state {
+	case models.SeatStateActive, models.SeatStateBlocked, models.SeatStateRemoved:
+		return true
+	}
+	return false
+}
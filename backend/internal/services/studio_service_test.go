@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+
+	"absolutcinema-backend/internal/models"
+)
+
+func TestGenerateGridSeatsLabelsRowsPastZ(t *testing.T) {
+	seats := models.GenerateGridSeats(1, MaxLayoutGridSize, 1)
+	want := map[int]string{0: "A1", 25: "Z1", 26: "AA1", 27: "AB1", MaxLayoutGridSize - 1: "AX1"}
+	for row, label := range want {
+		if seats[row].Label != label {
+			t.Errorf("row %d label = %q, want %q", row, seats[row].Label, label)
+		}
+	}
+	if got := models.GridRowLabel(26*27 + 1); got != "AAB" {
+		t.Errorf("GridRowLabel(703) = %q, want AAB", got)
+	}
+}
+
+func TestValidateStudioAcceptsLayoutEditorSizes(t *testing.T) {
+	s := &StudioService{}
+	// A seat map saved in the layout editor may span the whole grid; later updates must still pass
+	if err := s.validateStudio(&models.Studio{Name: "IMAX", TotalRows: MaxLayoutGridSize, TotalCols: MaxLayoutGridSize}); err != nil {
+		t.Errorf("full grid rejected: %v", err)
+	}
+	if err := s.validateStudio(&models.Studio{Name: "IMAX", TotalRows: MaxLayoutGridSize + 1, TotalCols: 10}); err == nil {
+		t.Error("oversized grid accepted")
+	}
+}
+
+func TestIsDefaultGrid(t *testing.T) {
+	category := uint(2)
+	blocked := models.GenerateGridSeats(1, 3, 4)
+	blocked[5].State = models.SeatStateBlocked
+	categorized := models.GenerateGridSeats(1, 3, 4)
+	categorized[0].CategoryID = &category
+
+	tests := []struct {
+		name  string
+		seats []models.StudioSeat
+		want  bool
+	}{
+		{"generated grid", models.GenerateGridSeats(1, 3, 4), true},
+		{"seat removed", models.GenerateGridSeats(1, 3, 4)[1:], false},
+		{"seat blocked", blocked, false},
+		{"seat category assigned", categorized, false},
+		{"different size", models.GenerateGridSeats(1, 4, 3), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDefaultGrid(tt.seats, 3, 4); got != tt.want {
+				t.Errorf("isDefaultGrid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/testcontainers/testcontainers-go"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+var (
+	testPostgresOnce sync.Once
+	testPostgresDSN  string
+	testPostgresErr  error
+	testSchemaSeq    atomic.Int64
+)
+
+// newTestDB connects to a fresh schema of a throwaway Postgres with tables migrated.
+// The container is shared by the package's tests; they are skipped without Docker.
+func newTestDB(t *testing.T, tables ...interface{}) *gorm.DB {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	testPostgresOnce.Do(func() {
+		ctx := context.Background()
+		container, err := tcpostgres.Run(ctx, "postgres:16-alpine",
+			tcpostgres.WithDatabase("absolutcinema_test"),
+			tcpostgres.WithUsername("test"),
+			tcpostgres.WithPassword("test"),
+			testcontainers.WithWaitStrategy(
+				wait.ForLog("database system is ready to accept connections").
+					WithOccurrence(2).
+					WithStartupTimeout(30*time.Second)),
+		)
+		if err != nil {
+			testPostgresErr = err
+			return
+		}
+		testPostgresDSN, testPostgresErr = container.ConnectionString(ctx, "sslmode=disable")
+	})
+	if testPostgresErr != nil {
+		t.Fatalf("could not start postgres container: %v", testPostgresErr)
+	}
+
+	config := &gorm.Config{
+		Logger:  logger.Discard,
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	}
+	schema := fmt.Sprintf("test_%d", testSchemaSeq.Add(1))
+	admin, err := gorm.Open(postgres.Open(testPostgresDSN), config)
+	if err != nil {
+		t.Fatalf("could not connect to postgres: %v", err)
+	}
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("could not create schema: %v", err)
+	}
+	if sqlDB, err := admin.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	db, err := gorm.Open(postgres.Open(testPostgresDSN+"&search_path="+schema), config)
+	if err != nil {
+		t.Fatalf("could not connect to postgres: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("could not migrate: %v", err)
+	}
+	return db
+}
+
+// seedBooking creates a customer, a showtime tomorrow and a booking of seat A1 for it
+func seedBooking(t *testing.T, db *gorm.DB, status, paymentID string) models.Booking {
+	t.Helper()
+	suffix := uuid.NewString()
+	user := models.User{Username: "customer", Email: suffix + "@example.com", Password: "x"}
+	movie := models.Movie{Title: "Seeded", DurationMinutes: 100}
+	studio := models.Studio{Name: "Studio", TotalRows: 1, TotalCols: 2}
+	for _, record := range []interface{}{&user, &movie, &studio} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Minute)
+	showtime := models.Showtime{MovieID: movie.ID, StudioID: studio.ID, StartTime: start,
+		EndTime: start.Add(2 * time.Hour), Price: money.FromMajor(50000)}
+	if err := db.Create(&showtime).Error; err != nil {
+		t.Fatalf("seed showtime: %v", err)
+	}
+
+	booking := models.Booking{UserID: user.ID, InvoiceNumber: "INV/" + suffix, Status: status,
+		TotalAmount: money.FromMajor(50000), PaymentID: paymentID,
+		Tickets: []models.Ticket{{ShowtimeID: showtime.ID, SeatNumber: "A1", Price: money.FromMajor(50000)}},
+	}
+	if err := db.Create(&booking).Error; err != nil {
+		t.Fatalf("seed booking: %v", err)
+	}
+	if paymentID != "" {
+		payment := models.Payment{BookingID: booking.ID, Provider: "fake", ProviderInvoiceID: paymentID,
+			Amount: booking.TotalAmount, Currency: "IDR", Status: models.PaymentStatusPending}
+		if err := db.Create(&payment).Error; err != nil {
+			t.Fatalf("seed payment: %v", err)
+		}
+	}
+	return booking
+}
+
+// stubProvider is a payment provider whose invoices are all still pending.
+// Invoices in failing can't be reached; every lookup is recorded in checked.
+type stubProvider struct {
+	PaymentProvider
+	failing map[string]bool
+	checked []string
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) ExpireInvoice(invoiceID string) error {
+	if p.failing[invoiceID] {
+		return errors.New("gateway unreachable")
+	}
+	return nil
+}
+
+func (p *stubProvider) GetInvoice(invoiceID string) (*InvoiceResult, error) {
+	p.checked = append(p.checked, invoiceID)
+	if p.failing[invoiceID] {
+		return nil, errors.New("gateway unreachable")
+	}
+	return &InvoiceResult{InvoiceID: invoiceID, Status: "PENDING"}, nil
+}
+
+func (p *stubProvider) GetInvoiceByExternalID(bookingID uuid.UUID) (*InvoiceResult, error) {
+	return p.GetInvoice("inv-" + bookingID.String())
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+)
+
+// TicketTokenVersion prefixes every signed ticket, so the format can change later
+const TicketTokenVersion = "AC1"
+
+var (
+	ticketKeyOnce sync.Once
+	ticketKey     ed25519.PrivateKey
+	ticketKeyErr  error
+)
+
+// TicketClaims is what a signed ticket token vouches for
+type TicketClaims struct {
+	TicketID   uint      `json:"ticket_id"`
+	ShowtimeID uint      `json:"showtime_id"`
+	BookingID  uuid.UUID `json:"booking_id"`
+}
+
+// ticketSigningKey returns the Ed25519 key tickets are signed with. TICKET_SIGNING_KEY is
+// a base64 32-byte seed; without it the key is derived from JWT_ACCESS_SECRET, which is
+// stable across restarts but means rotating the JWT secret invalidates issued tickets.
+func ticketSigningKey() (ed25519.PrivateKey, error) {
+	ticketKeyOnce.Do(func() {
+		if encoded := os.Getenv("TICKET_SIGNING_KEY"); encoded != "" {
+			seed, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil || len(seed) != ed25519.SeedSize {
+				ticketKeyErr = errors.New("TICKET_SIGNING_KEY must be a base64 encoded 32-byte seed")
+				return
+			}
+			ticketKey = ed25519.NewKeyFromSeed(seed)
+			return
+		}
+
+		secret := os.Getenv("JWT_ACCESS_SECRET")
+		if secret == "" {
+			ticketKeyErr = errors.New("ticket signing key is not configured")
+			return
+		}
+		log.Println("Warning: TICKET_SIGNING_KEY not set, deriving the ticket signing key from JWT_ACCESS_SECRET")
+		seed := sha256.Sum256([]byte("absolutcinema-ticket-signing:" + secret))
+		ticketKey = ed25519.NewKeyFromSeed(seed[:])
+	})
+	return ticketKey, ticketKeyErr
+}
+
+// TicketVerificationKey returns the public key that verifies ticket tokens, base64 encoded
+func TicketVerificationKey() (string, error) {
+	key, err := ticketSigningKey()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)), nil
+}
+
+// ticketTokenPayload is the signed part of a token: AC1.<ticket>.<showtime>.<booking hex>
+func ticketTokenPayload(claims TicketClaims) string {
+	return fmt.Sprintf("%s.%d.%d.%s", TicketTokenVersion, claims.TicketID, claims.ShowtimeID,
+		hex.EncodeToString(claims.BookingID[:]))
+}
+
+// signTicket returns the token printed in a ticket's QR code: the payload followed by
+// its Ed25519 signature, so scanners holding only the public key can verify it
+func signTicket(ticket *models.Ticket) (string, error) {
+	payload := ticketTokenPayload(TicketClaims{
+		TicketID:   ticket.ID,
+		ShowtimeID: ticket.ShowtimeID,
+		BookingID:  ticket.BookingID,
+	})
+	signature, err := signWithTicketKey([]byte(payload))
+	if err != nil {
+		return "", err
+	}
+	return payload + "." + signature, nil
+}
+
+// signWithTicketKey signs data with the ticket key, returning the base64url signature
+func signWithTicketKey(data []byte) (string, error) {
+	key, err := ticketSigningKey()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(ed25519.Sign(key, data)), nil
+}
+
+// verifyTicketToken checks a token's signature and returns what it vouches for
+func verifyTicketToken(token string) (*TicketClaims, error) {
+	key, err := ticketSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	invalid := errors.New("invalid ticket token")
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 5 || parts[0] != TicketTokenVersion {
+		return nil, invalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, invalid
+	}
+	payload := strings.Join(parts[:4], ".")
+	if !ed25519.Verify(key.Public().(ed25519.PublicKey), []byte(payload), signature) {
+		return nil, invalid
+	}
+
+	ticketID, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, invalid
+	}
+	showtimeID, err := strconv.ParseUint(parts[2], 10, 32)
+	if err != nil {
+		return nil, invalid
+	}
+	bookingBytes, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return nil, invalid
+	}
+	bookingID, err := uuid.FromBytes(bookingBytes)
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &TicketClaims{TicketID: uint(ticketID), ShowtimeID: uint(showtimeID), BookingID: bookingID}, nil
+}
+
+// issueTicketTokens signs the tickets of a booking that has just been paid.
+// Must be called in the transaction that marks the booking PAID.
+func issueTicketTokens(tx *gorm.DB, bookingID uuid.UUID) error {
+	var tickets []models.Ticket
+	if err := tx.Where("booking_id = ? AND (qr_token IS NULL OR qr_token = '')", bookingID).Find(&tickets).Error; err != nil {
+		return fmt.Errorf("failed to fetch tickets: %w", err)
+	}
+
+	for i := range tickets {
+		token, err := signTicket(&tickets[i])
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&tickets[i]).Update("qr_token", token).Error; err != nil {
+			return fmt.Errorf("failed to issue ticket %d: %w", tickets[i].ID, err)
+		}
+	}
+	return nil
+}
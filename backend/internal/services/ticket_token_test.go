@@ -0,0 +1,60 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"absolutcinema-backend/internal/models"
+)
+
+func TestTicketTokenRoundTrip(t *testing.T) {
+	t.Setenv("JWT_ACCESS_SECRET", "test-secret")
+
+	ticket := models.Ticket{ID: 42, ShowtimeID: 7, BookingID: uuid.New()}
+	token, err := signTicket(&ticket)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	claims, err := verifyTicketToken(token)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims.TicketID != 42 || claims.ShowtimeID != 7 || claims.BookingID != ticket.BookingID {
+		t.Errorf("got claims %+v", claims)
+	}
+
+	// Moving the ticket to another showtime breaks the signature
+	tampered := strings.Replace(token, ".42.7.", ".42.8.", 1)
+	if _, err := verifyTicketToken(tampered); err == nil {
+		t.Error("tampered token was accepted")
+	}
+}
+
+func TestBundleSignatureVerifiesWithPublicKey(t *testing.T) {
+	t.Setenv("JWT_ACCESS_SECRET", "test-secret")
+
+	payload := []byte(`{"showtime_id":7,"tickets":[]}`)
+	signature, err := signWithTicketKey(payload)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	encodedKey, err := TicketVerificationKey()
+	if err != nil {
+		t.Fatalf("public key: %v", err)
+	}
+
+	// What a scanner does with only the exported key
+	publicKey, _ := base64.StdEncoding.DecodeString(encodedKey)
+	sig, _ := base64.RawURLEncoding.DecodeString(signature)
+	if !ed25519.Verify(publicKey, payload, sig) {
+		t.Error("bundle signature does not verify")
+	}
+	if ed25519.Verify(publicKey, []byte(`{"showtime_id":8,"tickets":[]}`), sig) {
+		t.Error("signature verified a different bundle")
+	}
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+const (
+	// DefaultTicketTypeCode is used for seats booked without an explicit ticket type
+	DefaultTicketTypeCode = "ADULT"
+)
+
+// TicketTypeService handles ticket type management
+type TicketTypeService struct {
+	db *gorm.DB
+}
+
+// NewTicketTypeService creates a new ticket type service
+func NewTicketTypeService(db *gorm.DB) *TicketTypeService {
+	return &TicketTypeService{db: db}
+}
+
+// CreateTicketType creates a new ticket type with validation
+func (s *TicketTypeService) CreateTicketType(ticketType *models.TicketType) error {
+	if err := s.validateTicketType(ticketType); err != nil {
+		return err
+	}
+	return s.db.Create(ticketType).Error
+}
+
+// GetAllTicketTypes retrieves all ticket types
+func (s *TicketTypeService) GetAllTicketTypes() ([]models.TicketType, error) {
+	var ticketTypes []models.TicketType
+	if err := s.db.Order("id ASC").Find(&ticketTypes).Error; err != nil {
+		return nil, err
+	}
+	return ticketTypes, nil
+}
+
+// UpdateTicketType updates an existing ticket type
+func (s *TicketTypeService) UpdateTicketType(id uint, updates *models.TicketType) error {
+	if err := s.validateTicketType(updates); err != nil {
+		return err
+	}
+
+	var ticketType models.TicketType
+	if err := s.db.First(&ticketType, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("ticket type not found")
+		}
+		return err
+	}
+
+	ticketType.Code = updates.Code
+	ticketType.Name = updates.Name
+	ticketType.PriceModifier = updates.PriceModifier
+	ticketType.ExcludedRatings = updates.ExcludedRatings
+
+	return s.db.Save(&ticketType).Error
+}
+
+// DeleteTicketType soft deletes a ticket type
+func (s *TicketTypeService) DeleteTicketType(id uint) error {
+	result := s.db.Delete(&models.TicketType{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("ticket type not found")
+	}
+	return nil
+}
+
+// validateTicketType validates ticket type data
+func (s *TicketTypeService) validateTicketType(ticketType *models.TicketType) error {
+	ticketType.Code = strings.ToUpper(strings.TrimSpace(ticketType.Code))
+	if ticketType.Code == "" {
+		return errors.New("ticket type code is required")
+	}
+	if ticketType.Name == "" {
+		return errors.New("ticket type name is required")
+	}
+	// Stored as decimal(5,4): four decimals, below 10
+	basisPoints := math.Round(ticketType.PriceModifier * 10000)
+	if basisPoints <= 0 || basisPoints >= 100000 {
+		return errors.New("price modifier must be greater than 0 and less than 10")
+	}
+	return nil
+}
+
+// resolveTicketTypes picks the ticket type for each seat and checks eligibility for the movie.
+// Seats missing from requested fall back to DefaultTicketTypeCode.
+func resolveTicketTypes(db *gorm.DB, seats []string, requested map[string]string, movie *models.Movie) (map[string]*models.TicketType, error) {
+	// Normalize seat keys and type codes the same way seats are normalized
+	codes := make(map[string]string, len(seats))
+	for seat, code := range requested {
+		codes[strings.ToUpper(strings.TrimSpace(seat))] = strings.ToUpper(strings.TrimSpace(code))
+	}
+
+	wanted := map[string]bool{DefaultTicketTypeCode: true}
+	for _, seat := range seats {
+		if code, ok := codes[seat]; ok && code != "" {
+			wanted[code] = true
+		}
+	}
+
+	lookup := make([]string, 0, len(wanted))
+	for code := range wanted {
+		lookup = append(lookup, code)
+	}
+
+	var ticketTypes []models.TicketType
+	if err := db.Where("code IN ?", lookup).Find(&ticketTypes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load ticket types: %w", err)
+	}
+
+	byCode := make(map[string]*models.TicketType, len(ticketTypes))
+	for i := range ticketTypes {
+		byCode[ticketTypes[i].Code] = &ticketTypes[i]
+	}
+
+	result := make(map[string]*models.TicketType, len(seats))
+	for _, seat := range seats {
+		code := codes[seat]
+		if code == "" {
+			code = DefaultTicketTypeCode
+		}
+
+		ticketType, ok := byCode[code]
+		if !ok {
+			if code == DefaultTicketTypeCode {
+				// No default type configured; seat is sold at its plain seat price
+				result[seat] = nil
+				continue
+			}
+			return nil, fmt.Errorf("ticket type %s does not exist", code)
+		}
+
+		if movie != nil && !ticketType.AllowsRating(movie.Rating) {
+			return nil, fmt.Errorf("ticket type %s is not allowed for movies rated %s", ticketType.Name, movie.Rating)
+		}
+
+		result[seat] = ticketType
+	}
+
+	return result, nil
+}
+
+// applyPriceModifier applies a ticket type's modifier to a seat price, rounded to the minor unit.
+// The modifier has four decimals, so it is applied as an exact ratio of 10000.
+func applyPriceModifier(price money.Money, ticketType *models.TicketType) money.Money {
+	if ticketType == nil {
+		return price
+	}
+	return price.MulRatio(int64(math.Round(ticketType.PriceModifier*10000)), 10000)
+}
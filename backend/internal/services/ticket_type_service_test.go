@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"absolutcinema-backend/internal/database"
+	"absolutcinema-backend/internal/models"
+)
+
+func TestValidateTicketTypePriceModifierBounds(t *testing.T) {
+	s := &TicketTypeService{}
+	tests := []struct {
+		modifier float64
+		valid    bool
+	}{
+		{0, false},
+		{0.00004, false}, // Rounds to 0.0000
+		{0.0001, true},
+		{1, true},
+		{9.9999, true},
+		{9.99995, false}, // Rounds to 10.0000, which decimal(5,4) cannot hold
+		{10, false},
+		{-0.5, false},
+	}
+	for _, tt := range tests {
+		ticketType := models.TicketType{Code: "adult", Name: "Adult", PriceModifier: tt.modifier}
+		err := s.validateTicketType(&ticketType)
+		if (err == nil) != tt.valid {
+			t.Errorf("modifier %v: error = %v, want valid %v", tt.modifier, err, tt.valid)
+		}
+	}
+}
+
+func TestTicketTypeCodeReusableAfterDelete(t *testing.T) {
+	db := newTestDB(t, database.Models()...)
+	s := NewTicketTypeService(db)
+
+	first := models.TicketType{Code: "MEMBER", Name: "Member", PriceModifier: 0.9}
+	if err := s.CreateTicketType(&first); err != nil {
+		t.Fatalf("CreateTicketType: %v", err)
+	}
+	if err := s.CreateTicketType(&models.TicketType{Code: "MEMBER", Name: "Duplicate", PriceModifier: 1}); err == nil {
+		t.Fatal("a second live ticket type with the same code was created")
+	}
+
+	if err := s.DeleteTicketType(first.ID); err != nil {
+		t.Fatalf("DeleteTicketType: %v", err)
+	}
+	if err := s.CreateTicketType(&models.TicketType{Code: "MEMBER", Name: "Member", PriceModifier: 0.9}); err != nil {
+		t.Errorf("code of a deleted ticket type can't be reused: %v", err)
+	}
+}
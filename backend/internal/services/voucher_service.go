@@ -0,0 +1,270 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+// VoucherService handles promo code management
+type VoucherService struct {
+	db *gorm.DB
+}
+
+// VoucherUsage is a voucher with its current redemption counts
+type VoucherUsage struct {
+	models.Voucher
+	TimesUsed int64 `json:"times_used"`
+}
+
+// NewVoucherService creates a new voucher service
+func NewVoucherService(db *gorm.DB) *VoucherService {
+	return &VoucherService{db: db}
+}
+
+// CreateVoucher creates a new voucher with validation
+func (s *VoucherService) CreateVoucher(voucher *models.Voucher) error {
+	if err := s.validateVoucher(voucher); err != nil {
+		return err
+	}
+	return s.db.Create(voucher).Error
+}
+
+// GetAllVouchers retrieves all vouchers with their active redemption counts
+func (s *VoucherService) GetAllVouchers() ([]VoucherUsage, error) {
+	var vouchers []models.Voucher
+	if err := s.db.Order("id ASC").Find(&vouchers).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		VoucherID uint
+		Count     int64
+	}
+	if err := s.db.Model(&models.VoucherRedemption{}).
+		Select("voucher_id, COUNT(*) AS count").
+		Where("status = ?", models.RedemptionStatusActive).
+		Group("voucher_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	used := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		used[c.VoucherID] = c.Count
+	}
+
+	result := make([]VoucherUsage, len(vouchers))
+	for i, v := range vouchers {
+		result[i] = VoucherUsage{Voucher: v, TimesUsed: used[v.ID]}
+	}
+	return result, nil
+}
+
+// GetVoucherByID retrieves a voucher by ID
+func (s *VoucherService) GetVoucherByID(id uint) (*models.Voucher, error) {
+	var voucher models.Voucher
+	if err := s.db.First(&voucher, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("voucher not found")
+		}
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+// UpdateVoucher saves every field of updates over an existing voucher.
+// Callers wanting a partial update pass the stored voucher with their changes applied.
+func (s *VoucherService) UpdateVoucher(id uint, updates *models.Voucher) error {
+	if err := s.validateVoucher(updates); err != nil {
+		return err
+	}
+
+	voucher, err := s.GetVoucherByID(id)
+	if err != nil {
+		return err
+	}
+
+	updates.ID = voucher.ID
+	updates.CreatedAt = voucher.CreatedAt
+	updates.DeletedAt = voucher.DeletedAt
+
+	return s.db.Save(updates).Error
+}
+
+// DeleteVoucher soft deletes a voucher; existing redemptions are kept
+func (s *VoucherService) DeleteVoucher(id uint) error {
+	result := s.db.Delete(&models.Voucher{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("voucher not found")
+	}
+	return nil
+}
+
+// validateVoucher validates voucher data
+func (s *VoucherService) validateVoucher(voucher *models.Voucher) error {
+	voucher.Code = normalizePromoCode(voucher.Code)
+	if voucher.Code == "" {
+		return errors.New("voucher code is required")
+	}
+
+	voucher.DiscountType = strings.ToUpper(strings.TrimSpace(voucher.DiscountType))
+	switch voucher.DiscountType {
+	case models.VoucherTypePercentage:
+		if voucher.PercentBasisPoints <= 0 || voucher.PercentBasisPoints > 10000 {
+			return errors.New("percent_basis_points must be greater than 0 and at most 10000 (100%)")
+		}
+		if !voucher.Amount.IsZero() {
+			return errors.New("amount is only used by FIXED vouchers")
+		}
+	case models.VoucherTypeFixed:
+		if !voucher.Amount.IsPositive() {
+			return errors.New("fixed discount amount must be greater than 0")
+		}
+		if voucher.PercentBasisPoints != 0 {
+			return errors.New("percent_basis_points is only used by PERCENTAGE vouchers")
+		}
+	default:
+		return errors.New("discount type must be PERCENTAGE or FIXED")
+	}
+
+	if voucher.MaxDiscount.IsNegative() || voucher.MaxUses < 0 || voucher.MaxUsesPerUser < 0 || voucher.MinSeats < 0 {
+		return errors.New("voucher limits cannot be negative")
+	}
+
+	if voucher.ValidFrom != nil && voucher.ValidUntil != nil && !voucher.ValidUntil.After(*voucher.ValidFrom) {
+		return errors.New("valid_until must be after valid_from")
+	}
+
+	for _, day := range voucher.Weekdays {
+		if day < 0 || day > 6 {
+			return errors.New("weekdays must be between 0 (Sunday) and 6 (Saturday)")
+		}
+	}
+
+	return nil
+}
+
+// applyVoucher locks the voucher, checks it can be used for this booking and
+// returns the discount. Must be called inside the booking transaction: the row
+// lock serializes concurrent redemptions so the usage caps cannot be overshot.
+func applyVoucher(tx *gorm.DB, code string, userID uuid.UUID, showtime *models.Showtime, seatCount int, subtotal money.Money) (*models.Voucher, money.Money, error) {
+	var voucher models.Voucher
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code = ? AND is_active = ?", normalizePromoCode(code), true).
+		First(&voucher).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, money.Money{}, errors.New("promo code not found")
+		}
+		return nil, money.Money{}, fmt.Errorf("failed to fetch promo code: %w", err)
+	}
+
+	if err := checkVoucherEligibility(&voucher, showtime, seatCount, time.Now()); err != nil {
+		return nil, money.Money{}, err
+	}
+
+	if voucher.MaxUses > 0 {
+		var used int64
+		if err := tx.Model(&models.VoucherRedemption{}).
+			Where("voucher_id = ? AND status = ?", voucher.ID, models.RedemptionStatusActive).
+			Count(&used).Error; err != nil {
+			return nil, money.Money{}, fmt.Errorf("failed to count promo code uses: %w", err)
+		}
+		if used >= int64(voucher.MaxUses) {
+			return nil, money.Money{}, errors.New("promo code has reached its usage limit")
+		}
+	}
+
+	if voucher.MaxUsesPerUser > 0 {
+		var used int64
+		if err := tx.Model(&models.VoucherRedemption{}).
+			Where("voucher_id = ? AND user_id = ? AND status = ?", voucher.ID, userID, models.RedemptionStatusActive).
+			Count(&used).Error; err != nil {
+			return nil, money.Money{}, fmt.Errorf("failed to count promo code uses: %w", err)
+		}
+		if used >= int64(voucher.MaxUsesPerUser) {
+			return nil, money.Money{}, errors.New("promo code has already been used the maximum number of times on this account")
+		}
+	}
+
+	discount, err := calculateDiscount(&voucher, subtotal)
+	if err != nil {
+		return nil, money.Money{}, err
+	}
+	return &voucher, discount, nil
+}
+
+// checkVoucherEligibility checks the validity window, minimum seats and
+// movie/studio/weekday restrictions of a voucher
+func checkVoucherEligibility(voucher *models.Voucher, showtime *models.Showtime, seatCount int, now time.Time) error {
+	if voucher.ValidFrom != nil && now.Before(*voucher.ValidFrom) {
+		return errors.New("promo code is not valid yet")
+	}
+	if voucher.ValidUntil != nil && !now.Before(*voucher.ValidUntil) {
+		return errors.New("promo code has expired")
+	}
+	if voucher.MinSeats > 0 && seatCount < voucher.MinSeats {
+		return fmt.Errorf("promo code requires at least %d seats", voucher.MinSeats)
+	}
+	if len(voucher.MovieIDs) > 0 && !slices.Contains(voucher.MovieIDs, showtime.MovieID) {
+		return errors.New("promo code is not valid for this movie")
+	}
+	if len(voucher.StudioIDs) > 0 && !slices.Contains(voucher.StudioIDs, showtime.StudioID) {
+		return errors.New("promo code is not valid for this studio")
+	}
+	if len(voucher.Weekdays) > 0 && !slices.Contains(voucher.Weekdays, int(showtime.StartTime.Weekday())) {
+		return errors.New("promo code is not valid on this day")
+	}
+	return nil
+}
+
+// calculateDiscount returns the discount for a subtotal, rounded to the minor unit
+// and never more than the subtotal itself
+func calculateDiscount(voucher *models.Voucher, subtotal money.Money) (money.Money, error) {
+	var discount money.Money
+	switch voucher.DiscountType {
+	case models.VoucherTypePercentage:
+		discount = subtotal.MulRatio(int64(voucher.PercentBasisPoints), 10000)
+		if voucher.MaxDiscount.IsPositive() {
+			var err error
+			if discount, err = money.Min(discount, voucher.MaxDiscount); err != nil {
+				return money.Money{}, err
+			}
+		}
+	case models.VoucherTypeFixed:
+		discount = voucher.Amount
+	}
+	return money.Min(discount, subtotal)
+}
+
+// releaseVoucherRedemption gives a booking's promo code use back.
+// Must be called inside the transaction that cancels or expires the booking.
+func releaseVoucherRedemption(tx *gorm.DB, bookingID uuid.UUID) error {
+	now := time.Now()
+	if err := tx.Model(&models.VoucherRedemption{}).
+		Where("booking_id = ? AND status = ?", bookingID, models.RedemptionStatusActive).
+		Updates(map[string]interface{}{
+			"status":      models.RedemptionStatusReleased,
+			"released_at": &now,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to release promo code use: %w", err)
+	}
+	return nil
+}
+
+// normalizePromoCode uppercases and trims a promo code
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
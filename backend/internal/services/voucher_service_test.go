@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+func TestCalculateDiscount(t *testing.T) {
+	tests := []struct {
+		name     string
+		voucher  models.Voucher
+		subtotal money.Money
+		want     money.Money
+	}{
+		{"12.5 percent", models.Voucher{DiscountType: models.VoucherTypePercentage, PercentBasisPoints: 1250}, money.FromMajor(100000), money.FromMajor(12500)},
+		{"percent rounds to the rupiah", models.Voucher{DiscountType: models.VoucherTypePercentage, PercentBasisPoints: 3333}, money.FromMajor(10001), money.FromMajor(3333)},
+		{"percent capped", models.Voucher{DiscountType: models.VoucherTypePercentage, PercentBasisPoints: 5000, MaxDiscount: money.FromMajor(20000)}, money.FromMajor(100000), money.FromMajor(20000)},
+		{"fixed", models.Voucher{DiscountType: models.VoucherTypeFixed, Amount: money.FromMajor(15000)}, money.FromMajor(50000), money.FromMajor(15000)},
+		{"fixed never exceeds subtotal", models.Voucher{DiscountType: models.VoucherTypeFixed, Amount: money.FromMajor(75000)}, money.FromMajor(50000), money.FromMajor(50000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateDiscount(&tt.voucher, tt.subtotal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("calculateDiscount() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateVoucherDiscountFields(t *testing.T) {
+	s := &VoucherService{}
+	tests := []struct {
+		name    string
+		voucher models.Voucher
+		valid   bool
+	}{
+		{"percentage", models.Voucher{Code: "p", DiscountType: models.VoucherTypePercentage, PercentBasisPoints: 10000}, true},
+		{"percentage over 100%", models.Voucher{Code: "p", DiscountType: models.VoucherTypePercentage, PercentBasisPoints: 10001}, false},
+		{"percentage with amount", models.Voucher{Code: "p", DiscountType: models.VoucherTypePercentage, PercentBasisPoints: 500, Amount: money.FromMajor(1)}, false},
+		{"fixed", models.Voucher{Code: "f", DiscountType: models.VoucherTypeFixed, Amount: money.FromMajor(5000)}, true},
+		{"fixed without amount", models.Voucher{Code: "f", DiscountType: models.VoucherTypeFixed}, false},
+		{"fixed with percent", models.Voucher{Code: "f", DiscountType: models.VoucherTypeFixed, Amount: money.FromMajor(5000), PercentBasisPoints: 100}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.validateVoucher(&tt.voucher)
+			if (err == nil) != tt.valid {
+				t.Errorf("validateVoucher() error = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"absolutcinema-backend/internal/models"
+)
+
+const (
+	// Default and maximum page size when listing webhook events
+	defaultWebhookEventLimit = 50
+	maxWebhookEventLimit     = 200
+)
+
+// redactedWebhookHeaders are stored masked so the log never holds secrets
+var redactedWebhookHeaders = map[string]bool{
+	"X-Callback-Token": true,
+	"Authorization":    true,
+	"Cookie":           true,
+}
+
+// WebhookEventService stores incoming payment callbacks and processes them
+// through the booking service, keeping a replayable record of every attempt
+type WebhookEventService struct {
+	db             *gorm.DB
+	bookingService *BookingService
+}
+
+// WebhookReceipt is the outcome of receiving one callback
+type WebhookReceipt struct {
+	Event     *models.WebhookEvent
+	Duplicate bool // Event was already processed; nothing was done
+}
+
+// NewWebhookEventService creates a new webhook event service
+func NewWebhookEventService(db *gorm.DB, bookingService *BookingService) *WebhookEventService {
+	return &WebhookEventService{
+		db:             db,
+		bookingService: bookingService,
+	}
+}
+
+// ReceiveInvoiceCallback stores a raw invoice callback, verifies its token and processes it.
+// The returned error is the verification or processing error (a *WebhookError where known);
+// the event is stored either way.
+func (ws *WebhookEventService) ReceiveInvoiceCallback(rawBody []byte, headers http.Header) (*WebhookReceipt, error) {
+	event := &models.WebhookEvent{
+		Provider:         PaymentProviderXendit,
+		RawBody:          string(rawBody),
+		Headers:          flattenWebhookHeaders(headers),
+		ProcessingStatus: models.WebhookEventReceived,
+		DeliveryCount:    1,
+		ReceivedAt:       time.Now(),
+	}
+
+	// 1. Verify the callback token
+	verifyErr := ValidateCallbackToken(headers.Get("x-callback-token"))
+	event.Verified = verifyErr == nil
+	if verifyErr != nil {
+		event.VerificationError = verifyErr.Error()
+	}
+
+	// 2. Parse the payload
+	var payload models.XenditInvoiceCallback
+	parseErr := json.Unmarshal(rawBody, &payload)
+	if parseErr == nil {
+		event.InvoiceID = payload.ID
+		event.ExternalID = payload.ExternalID
+		event.Status = payload.Status
+	}
+
+	// Only verified, well-formed events take a dedupe key
+	if event.Verified && parseErr == nil && payload.ID != "" && payload.Status != "" {
+		key := payload.ID + ":" + payload.Status
+		event.DedupeKey = &key
+
+		receipt, err := ws.claimEvent(event)
+		if err != nil || receipt != nil {
+			return receipt, err
+		}
+	} else if err := ws.db.Create(event).Error; err != nil {
+		return nil, fmt.Errorf("failed to store webhook event: %w", err)
+	}
+
+	receipt := &WebhookReceipt{Event: event}
+
+	if verifyErr != nil {
+		return receipt, ws.finish(event, models.WebhookEventRejected, verifyErr)
+	}
+
+	if parseErr != nil {
+		invalid := NewWebhookError(ErrCodeInvalidPayload, "invalid payload format")
+		return receipt, ws.finish(event, models.WebhookEventFailed, invalid)
+	}
+
+	// 3. Process
+	return receipt, ws.process(event, &payload)
+}
+
+// claimEvent stores a verified event under its dedupe key.
+// A non-nil receipt means the event was already handled and must not be processed again.
+func (ws *WebhookEventService) claimEvent(event *models.WebhookEvent) (*WebhookReceipt, error) {
+	var existing models.WebhookEvent
+	err := ws.db.Where("dedupe_key = ?", *event.DedupeKey).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up webhook event: %w", err)
+	}
+
+	if err == nil {
+		// Seen before: record the redelivery, and retry only if it hasn't succeeded yet
+		updates := map[string]interface{}{"delivery_count": gorm.Expr("delivery_count + 1")}
+		if existing.ProcessingStatus != models.WebhookEventProcessed {
+			updates["raw_body"] = event.RawBody
+			updates["received_at"] = event.ReceivedAt
+		}
+		if err := ws.db.Model(&existing).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update webhook event: %w", err)
+		}
+
+		if existing.ProcessingStatus == models.WebhookEventProcessed {
+			return &WebhookReceipt{Event: &existing, Duplicate: true}, nil
+		}
+		*event = existing
+		return nil, nil
+	}
+
+	if err := ws.db.Create(event).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == PgUniqueViolationCode {
+			// The same delivery is being processed concurrently
+			return &WebhookReceipt{Event: event, Duplicate: true}, nil
+		}
+		return nil, fmt.Errorf("failed to store webhook event: %w", err)
+	}
+	return nil, nil
+}
+
+// ListEvents lists webhook events, newest first, optionally filtered by processing status
+func (ws *WebhookEventService) ListEvents(status string, limit, offset int) ([]models.WebhookEvent, int64, error) {
+	if limit <= 0 {
+		limit = defaultWebhookEventLimit
+	}
+	if limit > maxWebhookEventLimit {
+		limit = maxWebhookEventLimit
+	}
+
+	query := ws.db.Model(&models.WebhookEvent{})
+	if status != "" {
+		query = query.Where("processing_status = ?", strings.ToUpper(status))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook events: %w", err)
+	}
+
+	var events []models.WebhookEvent
+	if err := query.Order("received_at DESC").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch webhook events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// GetEvent retrieves a webhook event by ID
+func (ws *WebhookEventService) GetEvent(id uint) (*models.WebhookEvent, error) {
+	var event models.WebhookEvent
+	if err := ws.db.First(&event, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("webhook event not found")
+		}
+		return nil, fmt.Errorf("failed to fetch webhook event: %w", err)
+	}
+	return &event, nil
+}
+
+// ReplayEvent processes a stored event again. Only verified events that have not
+// been processed successfully can be replayed; the token is not checked again.
+func (ws *WebhookEventService) ReplayEvent(id uint) (*models.WebhookEvent, error) {
+	event, err := ws.GetEvent(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !event.Verified {
+		return nil, errors.New("cannot replay an unverified webhook event")
+	}
+	if event.ProcessingStatus == models.WebhookEventProcessed {
+		return nil, errors.New("webhook event was already processed")
+	}
+
+	var payload models.XenditInvoiceCallback
+	if err := json.Unmarshal([]byte(event.RawBody), &payload); err != nil {
+		return nil, errors.New("cannot replay a webhook event with an invalid payload")
+	}
+
+	// The processing error is recorded on the event; the replay itself succeeded
+	_ = ws.process(event, &payload)
+	return event, nil
+}
+
+// process runs the booking update for a verified event and records the outcome
+func (ws *WebhookEventService) process(event *models.WebhookEvent, payload *models.XenditInvoiceCallback) error {
+	event.Attempts++
+	err := ws.bookingService.processInvoiceCallback(payload)
+
+	status := models.WebhookEventProcessed
+	if err != nil {
+		status = models.WebhookEventFailed
+	}
+	if recordErr := ws.finish(event, status, err); recordErr != nil && err == nil {
+		return recordErr
+	}
+	return err
+}
+
+// finish saves the processing outcome of an event and passes cause through
+func (ws *WebhookEventService) finish(event *models.WebhookEvent, status string, cause error) error {
+	now := time.Now()
+	event.ProcessingStatus = status
+	event.ProcessedAt = &now
+	event.Error = ""
+	if cause != nil {
+		event.Error = cause.Error()
+	}
+
+	if err := ws.db.Model(event).Updates(map[string]interface{}{
+		"processing_status": event.ProcessingStatus,
+		"error":             event.Error,
+		"attempts":          event.Attempts,
+		"processed_at":      event.ProcessedAt,
+	}).Error; err != nil {
+		if cause != nil {
+			return cause
+		}
+		return fmt.Errorf("failed to record webhook outcome: %w", err)
+	}
+
+	return cause
+}
+
+// flattenWebhookHeaders keeps the first value of each header, masking secrets
+func flattenWebhookHeaders(headers http.Header) map[string]string {
+	flat := make(map[string]string, len(headers))
+	for name, values := range headers {
+		if len(values) == 0 {
+			continue
+		}
+		canonical := http.CanonicalHeaderKey(name)
+		if redactedWebhookHeaders[canonical] {
+			flat[canonical] = "[redacted]"
+			continue
+		}
+		flat[canonical] = values[0]
+	}
+	return flat
+}
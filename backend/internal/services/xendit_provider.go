@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	xendit "github.com/xendit/xendit-go/v6"
+	invoice "github.com/xendit/xendit-go/v6/invoice"
+	refund "github.com/xendit/xendit-go/v6/refund"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+// XenditProvider is the PaymentProvider backed by the Xendit API
+type XenditProvider struct {
+	client *xendit.APIClient
+}
+
+// NewXenditProvider creates a new Xendit payment provider
+func NewXenditProvider() (*XenditProvider, error) {
+	secretKey := os.Getenv("XENDIT_SECRET_KEY")
+	if secretKey == "" {
+		return nil, errors.New("XENDIT_SECRET_KEY environment variable is not set")
+	}
+
+	client := xendit.NewClient(secretKey)
+
+	return &XenditProvider{
+		client: client,
+	}, nil
+}
+
+// Name returns the provider name
+func (xp *XenditProvider) Name() string {
+	return PaymentProviderXendit
+}
+
+// CreateInvoice creates a Xendit invoice for a booking
+func (xp *XenditProvider) CreateInvoice(booking *models.Booking, userEmail string) (*InvoiceResult, error) {
+	if xp.client == nil {
+		return nil, errors.New("payment client is not initialized")
+	}
+
+	ctx := context.Background()
+
+	// External ID should be the booking ID (UUID)
+	externalID := booking.ID.String()
+
+	// Invoice description
+	description := fmt.Sprintf("AbsolutCinema Booking - Invoice %s", booking.InvoiceNumber)
+
+	// The invoice expires with the booking's payment deadline
+	expiryDate := invoiceExpiry(booking)
+	duration := int64(time.Until(expiryDate) / time.Second)
+
+	// Build invoice items from tickets
+	items, err := buildInvoiceItems(booking)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create invoice request
+	invoiceRequest := *invoice.NewCreateInvoiceRequest(externalID, booking.TotalAmount.Float64())
+	invoiceRequest.SetDescription(description)
+	invoiceRequest.SetPayerEmail(userEmail)
+	invoiceRequest.SetCurrency(string(booking.TotalAmount.Currency()))
+	invoiceRequest.SetInvoiceDuration(strconv.FormatInt(duration, 10))
+	invoiceRequest.SetSuccessRedirectUrl(getSuccessRedirectURL())
+	invoiceRequest.SetFailureRedirectUrl(getFailureRedirectURL())
+
+	if len(items) > 0 {
+		invoiceRequest.SetItems(items)
+	}
+
+	// Items carry the full seat prices; the promo discount is a negative fee so they add up to the amount
+	if fees := buildInvoiceFees(booking); len(fees) > 0 {
+		invoiceRequest.SetFees(fees)
+	}
+
+	// Create the invoice via Xendit API
+	resp, _, err := xp.client.InvoiceApi.CreateInvoice(ctx).
+		CreateInvoiceRequest(invoiceRequest).
+		Execute()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Xendit invoice: %w", err)
+	}
+
+	result := &InvoiceResult{
+		InvoiceID:  *resp.Id,
+		InvoiceURL: resp.InvoiceUrl,
+		ExternalID: resp.ExternalId,
+		Amount:     money.FromFloat(resp.Amount, money.DefaultCurrency),
+		Status:     string(resp.Status),
+		Currency:   invoiceCurrency(resp),
+		ExpiryDate: expiryDate.Format(time.RFC3339),
+	}
+
+	return result, nil
+}
+
+// GetInvoice retrieves an invoice by ID
+func (xp *XenditProvider) GetInvoice(invoiceID string) (*InvoiceResult, error) {
+	if xp.client == nil {
+		return nil, errors.New("payment client is not initialized")
+	}
+
+	ctx := context.Background()
+
+	resp, _, err := xp.client.InvoiceApi.GetInvoiceById(ctx, invoiceID).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Xendit invoice: %w", err)
+	}
+
+	result := &InvoiceResult{
+		InvoiceID:  *resp.Id,
+		InvoiceURL: resp.InvoiceUrl,
+		ExternalID: resp.ExternalId,
+		Amount:     money.FromFloat(resp.Amount, money.DefaultCurrency),
+		Status:     string(resp.Status),
+		Currency:   invoiceCurrency(resp),
+	}
+
+	return result, nil
+}
+
+// GetInvoiceByExternalID retrieves an invoice by external ID (booking ID)
+func (xp *XenditProvider) GetInvoiceByExternalID(bookingID uuid.UUID) (*InvoiceResult, error) {
+	if xp.client == nil {
+		return nil, errors.New("payment client is not initialized")
+	}
+
+	ctx := context.Background()
+
+	resp, _, err := xp.client.InvoiceApi.GetInvoices(ctx).
+		ExternalId(bookingID.String()).
+		Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Xendit invoice by external ID: %w", err)
+	}
+
+	if len(resp) == 0 {
+		return nil, errors.New("invoice not found")
+	}
+
+	// Get the most recent invoice
+	inv := resp[0]
+
+	result := &InvoiceResult{
+		InvoiceID:  *inv.Id,
+		InvoiceURL: inv.InvoiceUrl,
+		ExternalID: inv.ExternalId,
+		Amount:     money.FromFloat(inv.Amount, money.DefaultCurrency),
+		Status:     string(inv.Status),
+		Currency:   invoiceCurrency(&inv),
+	}
+
+	return result, nil
+}
+
+// ExpireInvoice expires/cancels an invoice
+func (xp *XenditProvider) ExpireInvoice(invoiceID string) error {
+	if xp.client == nil {
+		return errors.New("payment client is not initialized")
+	}
+
+	ctx := context.Background()
+
+	_, _, err := xp.client.InvoiceApi.ExpireInvoice(ctx, invoiceID).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to expire Xendit invoice: %w", err)
+	}
+
+	return nil
+}
+
+// Refund refunds part or all of a paid invoice.
+// Xendit processes refunds asynchronously, so the result is usually PENDING.
+func (xp *XenditProvider) Refund(invoiceID string, amount money.Money, reason string) (*RefundResult, error) {
+	if xp.client == nil {
+		return nil, errors.New("payment client is not initialized")
+	}
+
+	ctx := context.Background()
+
+	refundRequest := *refund.NewCreateRefund()
+	refundRequest.SetInvoiceId(invoiceID)
+	refundRequest.SetAmount(amount.Float64())
+	refundRequest.SetCurrency(string(amount.Currency()))
+	refundRequest.SetReason(reason)
+
+	resp, _, err := xp.client.RefundApi.CreateRefund(ctx).
+		CreateRefund(refundRequest).
+		Execute()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Xendit refund: %w", err)
+	}
+
+	return &RefundResult{
+		RefundID:  resp.GetId(),
+		InvoiceID: invoiceID,
+		Amount:    money.FromFloat(resp.GetAmount(), amount.Currency()),
+		Status:    RefundStatusPending,
+	}, nil
+}
+
+// invoiceCurrency returns the currency of a Xendit invoice
+func invoiceCurrency(inv *invoice.Invoice) string {
+	if inv.Currency == nil {
+		return ""
+	}
+	return string(*inv.Currency)
+}
+
+// buildInvoiceItems creates invoice items from booking tickets and concession items
+func buildInvoiceItems(booking *models.Booking) ([]invoice.InvoiceItem, error) {
+	if len(booking.Tickets) == 0 && len(booking.Items) == 0 {
+		return nil, nil
+	}
+
+	items := make([]invoice.InvoiceItem, 0, len(booking.Tickets)+len(booking.Items))
+
+	// One item per ticket, priced by the seat's category and ticket type.
+	// Tickets booked before per-seat pricing have no price snapshot; split the
+	// ticket total across them so the items still add up to it exactly.
+	ticketsTotal, err := ticketsGross(booking)
+	if err != nil {
+		return nil, err
+	}
+	fallbackPrices := ticketsTotal.Allocate(len(booking.Tickets))
+
+	for i, ticket := range booking.Tickets {
+		price := ticket.Price
+		if price.IsZero() {
+			price = fallbackPrices[i]
+		}
+
+		// NewInvoiceItem takes (name, price, quantity)
+		item := *invoice.NewInvoiceItem(
+			invoiceItemName(ticket),
+			price.Float32(),
+			1, // Quantity
+		)
+		if ticket.TicketTypeName != "" {
+			item.SetCategory(ticket.TicketTypeName)
+		}
+		items = append(items, item)
+	}
+
+	for _, bi := range booking.Items {
+		item := *invoice.NewInvoiceItem(bi.ProductName, bi.UnitPrice.Float32(), float32(bi.Quantity))
+		item.SetCategory(bi.Category)
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// buildInvoiceFees creates the discount line for a booking paid with a promo code
+func buildInvoiceFees(booking *models.Booking) []invoice.InvoiceFee {
+	if !booking.DiscountAmount.IsPositive() {
+		return nil
+	}
+
+	return []invoice.InvoiceFee{
+		*invoice.NewInvoiceFee(invoiceDiscountName(booking), booking.DiscountAmount.Neg().Float32()),
+	}
+}
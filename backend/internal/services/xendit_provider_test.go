@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"absolutcinema-backend/internal/models"
+	"absolutcinema-backend/internal/money"
+)
+
+func TestBuildInvoiceItemsPriceAndQuantity(t *testing.T) {
+	booking := &models.Booking{
+		TotalAmount: money.FromMajor(130000),
+		Tickets: []models.Ticket{
+			{SeatNumber: "A1", Price: money.FromMajor(50000), TicketTypeName: "Adult"},
+			{SeatNumber: "A2", Price: money.FromMajor(37500), TicketTypeName: "Child"},
+		},
+		Items: []models.BookingItem{
+			{ProductName: "Popcorn", Category: models.ConcessionCategorySnack, Quantity: 2,
+				UnitPrice: money.FromMajor(21250), TotalPrice: money.FromMajor(42500)},
+		},
+	}
+
+	items, err := buildInvoiceItems(booking)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("got %d items, want 3", len(items))
+	}
+
+	want := []struct {
+		price    float32
+		quantity float32
+	}{
+		{50000, 1},
+		{37500, 1},
+		{21250, 2},
+	}
+	for i, w := range want {
+		if items[i].Price != w.price || items[i].Quantity != w.quantity {
+			t.Errorf("item %d (%s): got price %v quantity %v, want price %v quantity %v",
+				i, items[i].Name, items[i].Price, items[i].Quantity, w.price, w.quantity)
+		}
+	}
+}